@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
+	httpHandlers "github.com/grcflEgor/go-anagram-api/internal/controller/http/v1"
+)
+
+const defaultAPIAddr = "http://localhost:8080"
+
+// runCommand выполняет административную подкоманду вместо запуска сервера
+func runCommand(args []string) error {
+	switch args[0] {
+	case "export":
+		return runExport(args[1:])
+	case "import":
+		return runImport(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, expected export or import", args[0])
+	}
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAPIAddr, "адрес API")
+	out := flags.String("out", "", "файл для сохранения архива")
+	token := flags.String("token", os.Getenv("ADMIN_TOKEN"), "токен администратора")
+	status := flags.String("status", "", "статусы задач через запятую (completed, failed)")
+	timeout := flags.Duration("timeout", 5*time.Minute, "таймаут запроса")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("export: -out is required")
+	}
+
+	endpoint := strings.TrimRight(*addr, "/") + "/api/v1/admin/tasks/export"
+	if *status != "" {
+		endpoint += "?status=" + url.QueryEscape(*status)
+	}
+
+	req, err := newAdminRequest(http.MethodGet, endpoint, *token, nil)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	resp, err := (&http.Client{Timeout: *timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("export: %w", decodeAPIError(resp))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(*out), filepath.Base(*out)+".part-*")
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("export: download archive: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		return fmt.Errorf("export: %w", err)
+	}
+
+	tasks, err := archive.Read(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export: verify archive: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Printf("exported %d tasks to %s\n", len(tasks), *out)
+	return nil
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAPIAddr, "адрес API")
+	in := flags.String("in", "", "файл архива")
+	token := flags.String("token", os.Getenv("ADMIN_TOKEN"), "токен администратора")
+	onConflict := flags.String("on-conflict", string(archive.ConflictSkip), "поведение при совпадении ID: skip, overwrite, fail")
+	timeout := flags.Duration("timeout", 5*time.Minute, "таймаут запроса")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("import: -in is required")
+	}
+	if _, err := archive.ParseConflictPolicy(*onConflict); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := archive.Read(file); err != nil {
+		return fmt.Errorf("import: verify archive: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	endpoint := strings.TrimRight(*addr, "/") + "/api/v1/admin/tasks/import?on_conflict=" + url.QueryEscape(*onConflict)
+	req, err := newAdminRequest(http.MethodPost, endpoint, *token, file)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := (&http.Client{Timeout: *timeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("import: %w", decodeAPIError(resp))
+	}
+
+	var report httpHandlers.ImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return fmt.Errorf("import: decode response: %w", err)
+	}
+
+	fmt.Printf("imported %d, overwritten %d, skipped %d tasks\n", report.Imported, report.Overwritten, report.Skipped)
+	return nil
+}
+
+// newAdminRequest создает запрос к административному эндпоинту с токеном администратора
+func newAdminRequest(method, endpoint, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Admin-Token", token)
+	return req, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	var apiErr httpHandlers.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Code == "" {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if apiErr.Details != "" {
+		return fmt.Errorf("%s: %s (%s)", apiErr.Code, apiErr.Error, apiErr.Details)
+	}
+	return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Error)
+}
@@ -1,50 +1,207 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/grcflEgor/go-anagram-api/internal/config"
 	httpHandlers "github.com/grcflEgor/go-anagram-api/internal/controller/http/v1"
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/ingest"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/service"
 	"github.com/grcflEgor/go-anagram-api/internal/storage"
 	"github.com/grcflEgor/go-anagram-api/internal/worker"
 	"github.com/grcflEgor/go-anagram-api/pkg/logger"
-	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
 )
 
 type Dependencies struct {
 	Config         *config.Config
-	Cache          *cache.Cache
+	Cache          *storage.LRUCache
+	Journal        *storage.Journal
 	Validator      *validator.Validate
 	TaskStorage    storage.TaskStorage
 	AnagramService service.AnagramServiceProvider
 	WorkerPool     *worker.Pool
-	TaskQueue      chan *domain.Task
+	TaskQueue      queue.TaskQueue
 	Handlers       *httpHandlers.Handlers
 	TaskStats      *service.TaskStats
+	Dispatcher     *service.TaskDispatcher
+	Watcher        *ingest.Watcher
+	Leases         *service.LeaseManager
+
+	recoveredTasks []*domain.Task
 }
 
-func NewDependencies(config *config.Config) *Dependencies {
-	appCache := cache.New(config.Cache.DefaultExpiration, config.Cache.CleanupInterval)
+func NewDependencies(config *config.Config) (*Dependencies, error) {
+	appCache := storage.NewLRUCache(config.Cache.MaxBytes, config.Cache.DefaultExpiration, config.Cache.CleanupInterval)
 
 	appValidator := validator.New()
 
 	inMemoryStorage := storage.NewInMemoryStorage()
-	cachedTaskStorage := storage.NewCachedTaskStorage(inMemoryStorage, appCache)
 
-	taskQueue := make(chan *domain.Task, config.Task.QueueSize)
+	var baseStorage storage.TaskStorage = inMemoryStorage
+	var journal *storage.Journal
+	var recoveredTasks []*domain.Task
+	if config.Journal.Enabled {
+		var err error
+		journal, recoveredTasks, err = storage.OpenJournal(context.Background(), config.Journal.Path, config.Journal.Sync, inMemoryStorage)
+		if err != nil {
+			appCache.Close()
+			return nil, err
+		}
+		journal.SetMaxBytes(config.Journal.MaxBytes)
+		baseStorage = storage.NewJournaledTaskStorage(inMemoryStorage, journal)
+	}
+
+	if config.Results.Enabled {
+		resultStore, err := storage.NewFileResultStore(config.Results.Dir, config.Results.ChunkSize)
+		if err != nil {
+			if journal != nil {
+				_ = journal.Close()
+			}
+			appCache.Close()
+			return nil, err
+		}
+		baseStorage = storage.NewOffloadingTaskStorage(baseStorage, resultStore, config.Results.OffloadThreshold)
+	}
+
+	cachedTaskStorage := storage.NewCachedTaskStorage(baseStorage, appCache)
+
+	if config.Graceful.CheckpointEnabled {
+		var err error
+		recoveredTasks, err = restoreCheckpoint(context.Background(), config.Graceful.CheckpointPath, cachedTaskStorage, recoveredTasks)
+		if err != nil {
+			if journal != nil {
+				_ = journal.Close()
+			}
+			appCache.Close()
+			return nil, err
+		}
+	}
+
+	var taskQueue queue.TaskQueue = queue.NewQueues(config.Task.QueueSize)
+	if config.Task.Persistent {
+		diskQueue, queued, err := queue.OpenDiskQueue(config.Task.Dir, config.Task.QueueSize)
+		if err == nil {
+			recoveredTasks, err = restoreQueue(context.Background(), cachedTaskStorage, diskQueue, queued, recoveredTasks)
+		}
+		if err != nil {
+			if journal != nil {
+				_ = journal.Close()
+			}
+			appCache.Close()
+			return nil, err
+		}
+		if len(queued) > 0 {
+			logger.AppLogger.Info("queued tasks restored from disk", zap.Int("count", diskQueue.Len()), zap.String("dir", config.Task.Dir))
+		}
+		taskQueue = diskQueue
+	}
+
+	// задачи уже восстановлены, поэтому файлы, на которые они не ссылаются, остались от потерянных задач
+	spool, err := service.NewSpool(config.Spool.Dir, config.Spool.MaxBytes)
+	if err != nil {
+		if journal != nil {
+			_ = journal.Close()
+		}
+		appCache.Close()
+		return nil, err
+	}
+	if removed, err := spool.Sweep(context.Background(), cachedTaskStorage, logger.AppLogger); err != nil {
+		logger.AppLogger.Warn("failed to sweep orphaned spool files", zap.String("dir", spool.Dir()), zap.Error(err))
+	} else if removed > 0 {
+		logger.AppLogger.Info("orphaned spool files removed", zap.Int("count", removed), zap.String("dir", spool.Dir()))
+	}
 
 	taskStats := service.NewTaskStats()
+	taskStats.SetCacheStats(cachedTaskStorage)
+	taskStats.SetQueue(taskQueue)
 
 	anagramService := service.NewAnagramService(cachedTaskStorage, taskQueue, taskStats, config.Upload.BatchSize)
+	anagramService.SetIdempotencyWindow(config.Idempotency.Window)
+	anagramService.SetEnqueueTimeout(config.Task.EnqueueTimeout)
+	anagramService.SetSpool(spool)
+	anagramService.SetPriorityThresholds(service.PriorityThresholds{
+		HighMaxWords: config.Priority.HighMaxWords,
+		LowMinWords:  config.Priority.LowMinWords,
+	})
 
 	workerPool := worker.NewPool(cachedTaskStorage, taskQueue, logger.AppLogger, config.Processing.Timeout, taskStats, config.Upload.BatchSize)
+	workerPool.SetPriorityWeights(map[domain.TaskPriority]int{
+		domain.PriorityHigh:   config.Priority.HighWeight,
+		domain.PriorityNormal: config.Priority.NormalWeight,
+		domain.PriorityLow:    config.Priority.LowWeight,
+	})
+	retryable := worker.IsTransientError
+	if config.Retry.Timeouts {
+		retryable = worker.RetryTimeouts
+	}
+	workerPool.SetRetryPolicy(worker.RetryPolicy{
+		MaxAttempts:    config.Retry.MaxAttempts,
+		InitialBackoff: config.Retry.InitialBackoff,
+		MaxBackoff:     config.Retry.MaxBackoff,
+		Multiplier:     config.Retry.Multiplier,
+		Retryable:      retryable,
+	})
+
+	workerPool.SetProgressInterval(config.Processing.ProgressInterval)
+	workerPool.SetFileParallelism(config.Processing.FileParallelism)
+	workerPool.SetWorkerLimits(config.Worker.Min, config.Worker.Max)
+	// разобранные по клиентам задачи занимают место в очереди, поэтому буфер больше нее не нужен
+	workerPool.SetClientLimits(config.Worker.ClientMaxConcurrency, min(config.Worker.FairBufferSize, config.Task.QueueSize))
+	anagramService.SetTaskCanceller(workerPool)
+
+	dispatcher := service.NewTaskDispatcher(cachedTaskStorage, taskQueue, logger.AppLogger)
+	restoredScheduled, err := dispatcher.Restore(context.Background())
+	if err != nil {
+		if journal != nil {
+			_ = journal.Close()
+		}
+		appCache.Close()
+		return nil, err
+	}
+	if restoredScheduled > 0 {
+		logger.AppLogger.Info("scheduled tasks restored", zap.Int("count", restoredScheduled))
+	}
+	anagramService.SetTaskScheduler(dispatcher)
+
+	var watcher *ingest.Watcher
+	if config.Ingest.Enabled {
+		watcher, err = ingest.NewWatcher(anagramService, ingest.Options{
+			Dir:           config.Ingest.Dir,
+			Pattern:       config.Ingest.Pattern,
+			PollInterval:  config.Ingest.PollInterval,
+			CaseSensitive: config.Ingest.CaseSensitive,
+			MaxFileSize:   config.Upload.MaxFileSize,
+		}, logger.AppLogger)
+		if err != nil {
+			if journal != nil {
+				_ = journal.Close()
+			}
+			appCache.Close()
+			return nil, err
+		}
+	}
 
 	handlers := httpHandlers.NewHandlers(anagramService, appValidator, config, taskStats)
+	handlers.SetWorkerPool(workerPool)
+	handlers.SetTaskQueue(taskQueue)
+
+	var leases *service.LeaseManager
+	if config.Remote.Enabled {
+		leases = service.NewLeaseManager(cachedTaskStorage, taskQueue, workerPool, taskStats, logger.AppLogger)
+		leases.SetTTL(config.Remote.LeaseTTL)
+		handlers.SetTaskLeaser(leases)
+	}
 
 	return &Dependencies{
 		Config:         config,
 		Cache:          appCache,
+		Journal:        journal,
 		Validator:      appValidator,
 		TaskStorage:    cachedTaskStorage,
 		AnagramService: anagramService,
@@ -52,15 +209,187 @@ func NewDependencies(config *config.Config) *Dependencies {
 		TaskQueue:      taskQueue,
 		Handlers:       handlers,
 		TaskStats:      taskStats,
-	}
+		Dispatcher:     dispatcher,
+		Watcher:        watcher,
+		Leases:         leases,
+		recoveredTasks: recoveredTasks,
+	}, nil
 }
 
 func (d *Dependencies) Start() {
 	d.WorkerPool.Run(d.Config.Worker.Count)
 	logger.AppLogger.Info("worker pool started")
+
+	if d.Config.Worker.Autoscale {
+		d.WorkerPool.EnableAutoscaling(worker.AutoscalePolicy{
+			Interval:       d.Config.Worker.AutoscaleInterval,
+			QueuePerWorker: d.Config.Worker.AutoscaleQueuePerWorker,
+			TargetLatency:  d.Config.Worker.AutoscaleTargetLatency,
+		})
+		logger.AppLogger.Info("worker pool autoscaling enabled",
+			zap.Int("min", d.Config.Worker.Min),
+			zap.Int("max", d.Config.Worker.Max),
+		)
+	}
+
+	for _, task := range d.recoveredTasks {
+		if err := d.TaskQueue.Enqueue(context.Background(), task); err != nil {
+			logger.AppLogger.Error("failed to re-enqueue recovered task", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}
+	if len(d.recoveredTasks) > 0 {
+		logger.AppLogger.Info("recovered tasks re-enqueued", zap.Int("count", len(d.recoveredTasks)))
+	}
+	d.recoveredTasks = nil
+
+	d.Dispatcher.Start()
+
+	if d.Leases != nil {
+		d.Leases.Start()
+		logger.AppLogger.Info("remote workers enabled", zap.Duration("lease_ttl", d.Config.Remote.LeaseTTL))
+	}
+
+	if d.Watcher != nil {
+		d.Watcher.Start()
+		logger.AppLogger.Info("ingest watcher started", zap.String("dir", d.Config.Ingest.Dir), zap.String("pattern", d.Config.Ingest.Pattern))
+	}
+
+	if d.Config.Graceful.CheckpointEnabled {
+		if err := storage.RemoveCheckpoint(d.Config.Graceful.CheckpointPath); err != nil {
+			logger.AppLogger.Error("failed to remove checkpoint", zap.Error(err))
+		}
+	}
+}
+
+// Shutdown останавливает пул воркеров в пределах ctx. Если снимок очереди
+// включен, необработанные задачи сохраняются на диск вместе с их файлами
+// и ставятся в очередь при следующем запуске, иначе очередь дорабатывается.
+func (d *Dependencies) Shutdown(ctx context.Context) {
+	if !d.Config.Graceful.CheckpointEnabled {
+		d.Stop()
+		return
+	}
+
+	d.stopWatcher()
+	scheduled := d.Dispatcher.Stop()
+	leased := d.stopLeases()
+	pending := d.WorkerPool.Shutdown(ctx)
+	logger.AppLogger.Info("worker pool stopped", zap.Int("unprocessed", len(pending)), zap.Int("scheduled", len(scheduled)), zap.Int("leased", len(leased)))
+	pending = append(pending, scheduled...)
+	pending = append(pending, leased...)
+
+	if err := storage.WriteCheckpoint(context.Background(), d.Config.Graceful.CheckpointPath, pending); err != nil {
+		logger.AppLogger.Error("failed to checkpoint unprocessed tasks", zap.Int("count", len(pending)), zap.Error(err))
+	} else if len(pending) > 0 {
+		logger.AppLogger.Info("unprocessed tasks checkpointed", zap.Int("count", len(pending)), zap.String("path", d.Config.Graceful.CheckpointPath))
+	}
+
+	d.close()
 }
 
 func (d *Dependencies) Stop() {
+	d.stopWatcher()
+	d.Dispatcher.Stop()
+	// задачи удаленных воркеров дорабатывает локальный пул
+	for _, task := range d.stopLeases() {
+		if err := d.TaskQueue.Enqueue(context.Background(), task); err != nil {
+			logger.AppLogger.Error("failed to re-enqueue leased task", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}
 	d.WorkerPool.Stop()
 	logger.AppLogger.Info("worker pool stopped")
+
+	d.close()
+}
+
+// stopLeases прекращает выдачу задач удаленным воркерам и возвращает задачи,
+// которые они не успели сдать
+func (d *Dependencies) stopLeases() []*domain.Task {
+	if d.Leases == nil {
+		return nil
+	}
+	return d.Leases.Stop()
+}
+
+func (d *Dependencies) stopWatcher() {
+	if d.Watcher != nil {
+		d.Watcher.Stop()
+	}
+}
+
+func (d *Dependencies) close() {
+	if d.Journal != nil {
+		if err := d.Journal.Close(); err != nil {
+			logger.AppLogger.Error("failed to close journal", zap.Error(err))
+		}
+	}
+
+	d.Cache.Close()
+}
+
+// restoreCheckpoint сохраняет задачи из снимка очереди в хранилище и добавляет их
+// к восстановленным задачам. Задачи, уже восстановленные из журнала, не дублируются.
+func restoreCheckpoint(ctx context.Context, path string, taskStorage storage.TaskStorage, recovered []*domain.Task) ([]*domain.Task, error) {
+	tasks, err := storage.LoadCheckpoint(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(recovered))
+	for _, task := range recovered {
+		seen[task.ID] = true
+	}
+
+	for _, task := range tasks {
+		if seen[task.ID] {
+			continue
+		}
+		if existing, err := taskStorage.GetByID(ctx, task.ID); err == nil {
+			// журнал уже восстановил задачу в итоговом статусе
+			if existing.Status != domain.StatusProcessing {
+				continue
+			}
+			task.Version = existing.Version
+		}
+		if err := taskStorage.Save(ctx, task); err != nil {
+			return nil, fmt.Errorf("restore checkpointed task %s: %w", task.ID, err)
+		}
+		if task.Status == domain.StatusProcessing {
+			recovered = append(recovered, task)
+			seen[task.ID] = true
+		}
+	}
+	return recovered, nil
+}
+
+// restoreQueue сверяет задачи, восстановленные из очереди на диске, с хранилищем.
+// Задачи, которых нет в хранилище, сохраняются, а задачи, уже завершенные
+// или отмененные, удаляются из очереди. Восстановленные из журнала или снимка
+// задачи, которые уже стоят в очереди, повторно не ставятся.
+func restoreQueue(ctx context.Context, taskStorage storage.TaskStorage, diskQueue *queue.DiskQueue, queued []*domain.Task, recovered []*domain.Task) ([]*domain.Task, error) {
+	for _, task := range queued {
+		existing, err := taskStorage.GetByID(ctx, task.ID)
+		switch {
+		case err == nil:
+			if existing.Status != domain.StatusProcessing {
+				diskQueue.Remove(task.ID)
+				continue
+			}
+			task.Version = existing.Version
+		case errors.Is(err, storage.ErrNotFound):
+			if err := taskStorage.Save(ctx, task); err != nil {
+				return nil, fmt.Errorf("restore queued task %s: %w", task.ID, err)
+			}
+		default:
+			return nil, fmt.Errorf("restore queued task %s: %w", task.ID, err)
+		}
+	}
+
+	pending := recovered[:0]
+	for _, task := range recovered {
+		if !diskQueue.Contains(task.ID) {
+			pending = append(pending, task)
+		}
+	}
+	return pending, nil
 }
@@ -32,16 +32,21 @@ func (gm *GracefulManager) Start() {
 	<-quit
 	logger.AppLogger.Info("received shutdown signal, starting graceful shutdown...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), gm.config.Graceful.ShutdownTimeout)
-	defer cancel()
+	// у остановки HTTP и пула воркеров свои таймауты: медленные запросы
+	// не должны съедать время на доработку задач и снимок очереди
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), gm.config.Graceful.HTTPTimeout)
+	defer cancelHTTP()
 
-	if err := gm.server.GetHTTPServer().Shutdown(ctx); err != nil {
+	if err := gm.server.GetHTTPServer().Shutdown(httpCtx); err != nil {
 		logger.AppLogger.Error("HTTP server forced to shutdown", zap.Error(err))
 	} else {
 		logger.AppLogger.Info("HTTP server gracefully stopped")
 	}
 
-	gm.deps.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), gm.config.Graceful.ShutdownTimeout)
+	defer cancel()
+
+	gm.deps.Shutdown(ctx)
 
 	logger.AppLogger.Info("app gracefully shutdown completed")
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/grcflEgor/go-anagram-api/internal/config"
 	"github.com/grcflEgor/go-anagram-api/pkg/logger"
@@ -10,6 +12,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.InitLogger()
 	defer func() { _ = logger.AppLogger.Sync() }()
 
@@ -28,7 +38,10 @@ func main() {
 		}
 	}()
 
-	dependencies := NewDependencies(config)
+	dependencies, err := NewDependencies(config)
+	if err != nil {
+		logger.AppLogger.Fatal("failed to initialize dependencies", zap.Error(err))
+	}
 	dependencies.Start()
 
 	server := NewServer(config, dependencies.Handlers)
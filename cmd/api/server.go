@@ -43,19 +43,44 @@ func setupRouter(config *config.Config, handlers *httpHandlers.Handlers) *chi.Mu
 	router.Use(middleware.Recoverer)
 	router.Use(httpHandlers.LoggerMiddleware)
 	router.Use(httpHandlers.OTelMiddleware)
-	router.Use(httprate.Limit(
-		config.RateLimit.Requests,
-		config.RateLimit.Window,
-		httprate.WithKeyFuncs(httprate.KeyByIP, httprate.KeyByEndpoint),
-	))
-
 	router.Route("/api/v1", func(r chi.Router) {
-		r.Get("/health", handlers.HealthCheck)
-		r.Post("/anagrams/group", handlers.GroupAnagrams)
-		r.Get("/anagrams/groups/{id}", handlers.GetResult)
-		r.Post("/anagrams/upload", handlers.UploadFile)
-		r.Get("/anagrams/stats", handlers.GetStats)
-		r.Delete("/anagrams/cache", handlers.ClearCache)
+		r.Group(func(r chi.Router) {
+			r.Use(httprate.Limit(
+				config.RateLimit.Requests,
+				config.RateLimit.Window,
+				httprate.WithKeyFuncs(httprate.KeyByIP, httprate.KeyByEndpoint),
+			))
+
+			r.Get("/health", handlers.HealthCheck)
+			r.Post("/anagrams/group", handlers.GroupAnagrams)
+			r.Get("/anagrams/groups/{id}", handlers.GetResult)
+			r.Post("/anagrams/groups/{id}/cancel", handlers.CancelTask)
+			r.Post("/anagrams/upload", handlers.UploadFile)
+			r.Get("/anagrams/stats", handlers.GetStats)
+			r.Get("/anagrams/cache", handlers.GetCacheSummary)
+			r.Delete("/anagrams/cache", handlers.ClearCache)
+			r.Delete("/anagrams/cache/{id}", handlers.InvalidateCacheEntry)
+			r.Post("/anagrams/cache/invalidate", handlers.InvalidateCache)
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(httpHandlers.AdminAuthMiddleware(config.Admin.Token))
+
+				r.Get("/tasks/export", handlers.ExportTasks)
+				r.Post("/tasks/import", handlers.ImportTasks)
+				r.Get("/tasks/dead-letter", handlers.ListDeadLetterTasks)
+				r.Post("/tasks/{id}/requeue", handlers.RequeueTask)
+				r.Get("/workers", handlers.GetWorkers)
+				r.Put("/workers", handlers.ResizeWorkers)
+			})
+		})
+
+		// удаленные воркеры опрашивают API постоянно, поэтому лимит запросов к ним не применяется
+		r.Route("/internal/leases", func(r chi.Router) {
+			r.Post("/", handlers.AcquireLease)
+			r.Get("/{id}/input", handlers.LeaseInput)
+			r.Post("/{id}/heartbeat", handlers.HeartbeatLease)
+			r.Post("/{id}/complete", handlers.CompleteLease)
+		})
 	})
 
 	return router
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/grcflEgor/go-anagram-api/internal/config"
+	"github.com/grcflEgor/go-anagram-api/internal/worker"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
+	"github.com/grcflEgor/go-anagram-api/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger.InitLogger()
+	defer func() { _ = logger.AppLogger.Sync() }()
+
+	config, err := config.LoadWorkerConfig()
+	if err != nil {
+		logger.AppLogger.Fatal("failed to load config", zap.Error(err))
+	}
+
+	tracerProvider, err := tracing.NewTracerProvider(logger.AppLogger, config.ServiceName)
+	if err != nil {
+		logger.AppLogger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := tracerProvider.Shutdown(context.Background()); err != nil {
+			logger.AppLogger.Error("failed to shutdown tracing", zap.Error(err))
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	client := worker.NewLeaseClient(config.APIAddr, config.Token, nil)
+	remote := worker.NewRemoteWorker(client, config.ID, config.Concurrency, config.BatchSize, config.ProcessingTimeout, logger.AppLogger)
+	remote.SetLeaseWait(config.LeaseWait)
+
+	logger.AppLogger.Info("remote worker started",
+		zap.String("api_addr", config.APIAddr),
+		zap.String("worker_id", config.ID),
+		zap.Int("concurrency", config.Concurrency),
+	)
+	remote.Run(ctx)
+	logger.AppLogger.Info("remote worker stopped")
+}
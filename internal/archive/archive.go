@@ -0,0 +1,334 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+const (
+	Format  = "anagram-api/tasks"
+	Version = 1
+
+	kindHeader  = "header"
+	kindTask    = "task"
+	kindTrailer = "trailer"
+)
+
+var (
+	ErrInvalidArchive   = errors.New("invalid archive")
+	ErrChecksumMismatch = errors.New("archive checksum mismatch")
+	ErrConflict         = errors.New("task already exists")
+)
+
+// ConflictPolicy определяет, что делать при импорте задачи, которая уже есть в хранилище
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictFail      ConflictPolicy = "fail"
+)
+
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", value)
+	}
+}
+
+// ImportReport содержит итоги импорта архива
+type ImportReport struct {
+	Imported    int
+	Overwritten int
+	Skipped     int
+}
+
+type Source interface {
+	List(ctx context.Context) ([]*domain.Task, error)
+}
+
+// resultLoader реализуется источниками, которые хранят результаты задач отдельно
+type resultLoader interface {
+	LoadResult(ctx context.Context, task *domain.Task) error
+}
+
+type Target interface {
+	Save(ctx context.Context, task *domain.Task) error
+	GetByID(ctx context.Context, id string) (*domain.Task, error)
+}
+
+// line - одна строка NDJSON-архива: заголовок, задача или завершающая запись
+type line struct {
+	Kind string `json:"kind"`
+
+	Format     string     `json:"format,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	ExportedAt *time.Time `json:"exported_at,omitempty"`
+
+	Task     *taskRecord `json:"task,omitempty"`
+	Checksum string      `json:"sha256,omitempty"`
+
+	Count int `json:"count,omitempty"`
+}
+
+type taskRecord struct {
+	ID               string            `json:"id"`
+	Status           domain.TaskStatus `json:"status"`
+	CaseSensitive    bool              `json:"case_sensitive,omitempty"`
+	Fingerprint      string            `json:"fingerprint,omitempty"`
+	Result           [][]string        `json:"result,omitempty"`
+	Error            string            `json:"error,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	ProcessingTimeMS int64             `json:"processing_time_ms,omitempty"`
+	GroupsCount      int               `json:"groups_count,omitempty"`
+}
+
+func newTaskRecord(task *domain.Task) *taskRecord {
+	return &taskRecord{
+		ID:               task.ID,
+		Status:           task.Status,
+		CaseSensitive:    task.CaseSensitive,
+		Fingerprint:      task.Fingerprint,
+		Result:           task.Result,
+		Error:            task.Error,
+		CreatedAt:        task.CreatedAt,
+		ProcessingTimeMS: task.ProcessingTimeMS,
+		GroupsCount:      task.GroupsCount,
+	}
+}
+
+func (tr *taskRecord) toDomain() *domain.Task {
+	return &domain.Task{
+		ID:               tr.ID,
+		Status:           tr.Status,
+		CaseSensitive:    tr.CaseSensitive,
+		Fingerprint:      tr.Fingerprint,
+		Result:           tr.Result,
+		Error:            tr.Error,
+		CreatedAt:        tr.CreatedAt,
+		ProcessingTimeMS: tr.ProcessingTimeMS,
+		GroupsCount:      tr.GroupsCount,
+		TraceContext:     make(map[string]string),
+	}
+}
+
+func recordChecksum(record *taskRecord) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func isExportable(status domain.TaskStatus) bool {
+	return status == domain.StatusCompleted || status == domain.StatusFailed
+}
+
+// Export пишет в w завершенные задачи из src в формате NDJSON.
+// Если statuses не пуст, выгружаются только задачи с этими статусами.
+func Export(ctx context.Context, w io.Writer, src Source, statuses []domain.TaskStatus) (int, error) {
+	tasks, err := src.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	loader, _ := src.(resultLoader)
+
+	allowed := make(map[domain.TaskStatus]bool, len(statuses))
+	for _, status := range statuses {
+		allowed[status] = true
+	}
+
+	bw := bufio.NewWriter(w)
+	digest := sha256.New()
+	encoder := json.NewEncoder(io.MultiWriter(bw, digest))
+
+	exportedAt := time.Now().UTC()
+	header := line{Kind: kindHeader, Format: Format, Version: Version, ExportedAt: &exportedAt}
+	if err := json.NewEncoder(bw).Encode(header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			return count, ctx.Err()
+		default:
+		}
+
+		if !isExportable(task.Status) || (len(allowed) > 0 && !allowed[task.Status]) {
+			continue
+		}
+
+		if loader != nil {
+			if err := loader.LoadResult(ctx, task); err != nil {
+				return count, fmt.Errorf("load result of task %s: %w", task.ID, err)
+			}
+		}
+
+		record := newTaskRecord(task)
+		if task.ResultRef != nil {
+			// подгруженный результат не должен оставаться в памяти до конца выгрузки
+			task.Result = nil
+		}
+		checksum, err := recordChecksum(record)
+		if err != nil {
+			return count, err
+		}
+		if err := encoder.Encode(line{Kind: kindTask, Task: record, Checksum: checksum}); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	trailer := line{Kind: kindTrailer, Count: count, Checksum: hex.EncodeToString(digest.Sum(nil))}
+	if err := json.NewEncoder(bw).Encode(trailer); err != nil {
+		return count, err
+	}
+
+	return count, bw.Flush()
+}
+
+// Read разбирает архив и проверяет контрольные суммы задач и всего архива.
+func Read(r io.Reader) ([]*domain.Task, error) {
+	reader := bufio.NewReader(r)
+
+	var (
+		tasks      []*domain.Task
+		digest     hash.Hash = sha256.New()
+		seenHeader bool
+		trailer    *line
+		lineNo     int
+	)
+
+	for {
+		raw, readErr := reader.ReadBytes('\n')
+		if len(raw) > 0 {
+			lineNo++
+
+			var l line
+			if err := json.Unmarshal(raw, &l); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidArchive, lineNo, err)
+			}
+			if trailer != nil {
+				return nil, fmt.Errorf("%w: line %d: data after trailer", ErrInvalidArchive, lineNo)
+			}
+
+			switch l.Kind {
+			case kindHeader:
+				if seenHeader || lineNo != 1 {
+					return nil, fmt.Errorf("%w: line %d: unexpected header", ErrInvalidArchive, lineNo)
+				}
+				if l.Format != Format || l.Version != Version {
+					return nil, fmt.Errorf("%w: unsupported format %s v%d", ErrInvalidArchive, l.Format, l.Version)
+				}
+				seenHeader = true
+			case kindTask:
+				if !seenHeader {
+					return nil, fmt.Errorf("%w: missing header", ErrInvalidArchive)
+				}
+				if l.Task == nil || l.Task.ID == "" {
+					return nil, fmt.Errorf("%w: line %d: empty task", ErrInvalidArchive, lineNo)
+				}
+				if !isExportable(l.Task.Status) {
+					return nil, fmt.Errorf("%w: line %d: task %s has status %s", ErrInvalidArchive, lineNo, l.Task.ID, l.Task.Status)
+				}
+				checksum, err := recordChecksum(l.Task)
+				if err != nil {
+					return nil, err
+				}
+				if checksum != l.Checksum {
+					return nil, fmt.Errorf("%w: task %s", ErrChecksumMismatch, l.Task.ID)
+				}
+				digest.Write(raw)
+				tasks = append(tasks, l.Task.toDomain())
+			case kindTrailer:
+				if !seenHeader {
+					return nil, fmt.Errorf("%w: missing header", ErrInvalidArchive)
+				}
+				trailer = &l
+			default:
+				return nil, fmt.Errorf("%w: line %d: unknown kind %q", ErrInvalidArchive, lineNo, l.Kind)
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	if trailer == nil {
+		return nil, fmt.Errorf("%w: missing trailer, archive may be truncated", ErrInvalidArchive)
+	}
+	if trailer.Count != len(tasks) {
+		return nil, fmt.Errorf("%w: trailer expects %d tasks, got %d", ErrChecksumMismatch, trailer.Count, len(tasks))
+	}
+	if trailer.Checksum != hex.EncodeToString(digest.Sum(nil)) {
+		return nil, fmt.Errorf("%w: archive digest", ErrChecksumMismatch)
+	}
+
+	return tasks, nil
+}
+
+// Import проверяет архив целиком и только затем сохраняет задачи в dst.
+// При политике ConflictFail ни одна задача не сохраняется, если хотя бы одна уже существует.
+func Import(ctx context.Context, r io.Reader, dst Target, policy ConflictPolicy) (ImportReport, error) {
+	var report ImportReport
+
+	tasks, err := Read(r)
+	if err != nil {
+		return report, err
+	}
+
+	existing := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if current, err := dst.GetByID(ctx, task.ID); err == nil {
+			existing[task.ID] = true
+			// перезапись должна пройти проверку версии в хранилище
+			task.Version = current.Version
+			if policy == ConflictFail {
+				return report, fmt.Errorf("%w: %s", ErrConflict, task.ID)
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		if existing[task.ID] {
+			if policy == ConflictSkip {
+				report.Skipped++
+				continue
+			}
+			report.Overwritten++
+		} else {
+			report.Imported++
+		}
+
+		// слов задачи в архиве нет, поэтому отпечаток нельзя проверить: с ним
+		// подложенный результат выдавался бы новым задачам вместо обработки
+		task.Fingerprint = ""
+		if err := dst.Save(ctx, task); err != nil {
+			return report, fmt.Errorf("save task %s: %w", task.ID, err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,141 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
+)
+
+func newSourceStorage() *mocks.MockTaskStorage {
+	return &mocks.MockTaskStorage{Tasks: map[string]*domain.Task{
+		"done":    {ID: "done", Status: domain.StatusCompleted, Fingerprint: "f1", Result: [][]string{{"кот", "ток"}}, GroupsCount: 1, CreatedAt: time.Now()},
+		"failed":  {ID: "failed", Status: domain.StatusFailed, Error: "task processing timeout", CreatedAt: time.Now()},
+		"running": {ID: "running", Status: domain.StatusProcessing, Words: []string{"a"}, CreatedAt: time.Now()},
+	}}
+}
+
+func exportToBuffer(t *testing.T, statuses ...domain.TaskStatus) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if _, err := archive.Export(context.Background(), &buf, newSourceStorage(), statuses); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &buf
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	buf := exportToBuffer(t)
+
+	dst := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	report, err := archive.Import(context.Background(), buf, dst, archive.ConflictSkip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Imported != 2 {
+		t.Errorf("expected 2 imported tasks, got %+v", report)
+	}
+	if _, ok := dst.Tasks["running"]; ok {
+		t.Error("unfinished task must not be exported")
+	}
+
+	done := dst.Tasks["done"]
+	if done == nil || done.GroupsCount != 1 || len(done.Result) != 1 || done.Result[0][1] != "ток" {
+		t.Errorf("unexpected imported task: %+v", done)
+	}
+	if done != nil && done.Fingerprint != "" {
+		t.Errorf("imported task must not be reused by fingerprint, got %q", done.Fingerprint)
+	}
+}
+
+func TestExport_StatusFilter(t *testing.T) {
+	tasks, err := archive.Read(exportToBuffer(t, domain.StatusFailed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "failed" {
+		t.Errorf("expected only failed task, got %+v", tasks)
+	}
+}
+
+func TestRead_DetectsTampering(t *testing.T) {
+	tampered := strings.Replace(exportToBuffer(t).String(), "ток", "кто", 1)
+
+	_, err := archive.Read(strings.NewReader(tampered))
+	if !errors.Is(err, archive.ErrChecksumMismatch) {
+		t.Errorf("expected archive.ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestRead_DetectsTruncation(t *testing.T) {
+	lines := strings.SplitAfter(exportToBuffer(t).String(), "\n")
+	truncated := strings.Join(lines[:len(lines)-2], "")
+
+	_, err := archive.Read(strings.NewReader(truncated))
+	if !errors.Is(err, archive.ErrInvalidArchive) {
+		t.Errorf("expected archive.ErrInvalidArchive, got %v", err)
+	}
+}
+
+func TestRead_DetectsRemovedTask(t *testing.T) {
+	lines := strings.SplitAfter(exportToBuffer(t).String(), "\n")
+	withoutTask := lines[0] + strings.Join(lines[2:], "")
+
+	_, err := archive.Read(strings.NewReader(withoutTask))
+	if !errors.Is(err, archive.ErrChecksumMismatch) {
+		t.Errorf("expected archive.ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestImport_ConflictPolicies(t *testing.T) {
+	archiveData := exportToBuffer(t).String()
+
+	cases := []struct {
+		name     string
+		policy   archive.ConflictPolicy
+		wantErr  error
+		want     archive.ImportReport
+		wantDone string
+	}{
+		{name: "Skip", policy: archive.ConflictSkip, want: archive.ImportReport{Imported: 1, Skipped: 1}, wantDone: "local"},
+		{name: "Overwrite", policy: archive.ConflictOverwrite, want: archive.ImportReport{Imported: 1, Overwritten: 1}, wantDone: ""},
+		{name: "Fail", policy: archive.ConflictFail, wantErr: archive.ErrConflict, wantDone: "local"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := &mocks.MockTaskStorage{Tasks: map[string]*domain.Task{
+				"done": {ID: "done", Status: domain.StatusFailed, Error: "local"},
+			}}
+
+			report, err := archive.Import(context.Background(), strings.NewReader(archiveData), dst, tc.policy)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if report != tc.want {
+				t.Errorf("expected report %+v, got %+v", tc.want, report)
+			}
+			if dst.Tasks["done"].Error != tc.wantDone {
+				t.Errorf("unexpected task after import: %+v", dst.Tasks["done"])
+			}
+			if tc.wantErr != nil && len(dst.Tasks) != 1 {
+				t.Error("failed import must not save any task")
+			}
+		})
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	if policy, err := archive.ParseConflictPolicy(""); err != nil || policy != archive.ConflictSkip {
+		t.Errorf("expected default skip policy, got %q, %v", policy, err)
+	}
+	if _, err := archive.ParseConflictPolicy("merge"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"os"
 	"time"
 	"github.com/caarlos0/env/v10"
 
@@ -12,16 +14,52 @@ type Config struct {
 	}
 
 	Task struct {
-		QueueSize int `env:"TASK_QUEUE_SIZE" envDefault:"1000"`
+		// QueueSize - сколько задач всех приоритетов вместе может ожидать в очереди
+		QueueSize      int           `env:"TASK_QUEUE_SIZE" envDefault:"1000"`
+		EnqueueTimeout time.Duration `env:"TASK_ENQUEUE_TIMEOUT" envDefault:"0s"`
+		RetryAfter     time.Duration `env:"TASK_QUEUE_RETRY_AFTER" envDefault:"5s"`
+		// Persistent хранит ожидающие задачи файлами в Dir, чтобы очередь переживала падение процесса
+		Persistent bool   `env:"TASK_QUEUE_PERSISTENT" envDefault:"false"`
+		Dir        string `env:"TASK_QUEUE_DIR" envDefault:"data/queue"`
 	}
 
 	Worker struct {
 		Count int `env:"NUM_WORKERS" envDefault:"50"`
+		Min   int `env:"WORKER_MIN" envDefault:"1"`
+		Max   int `env:"WORKER_MAX" envDefault:"200"`
+
+		Autoscale               bool          `env:"WORKER_AUTOSCALE" envDefault:"false"`
+		AutoscaleInterval       time.Duration `env:"WORKER_AUTOSCALE_INTERVAL" envDefault:"5s"`
+		AutoscaleQueuePerWorker int           `env:"WORKER_AUTOSCALE_QUEUE_PER_WORKER" envDefault:"10"`
+		AutoscaleTargetLatency  time.Duration `env:"WORKER_AUTOSCALE_TARGET_LATENCY" envDefault:"2s"`
+
+		ClientMaxConcurrency int `env:"WORKER_CLIENT_MAX_CONCURRENCY" envDefault:"0"`
+		FairBufferSize       int `env:"WORKER_FAIR_BUFFER_SIZE" envDefault:"1000"`
+		// ClientAPIKeys - ключи X-API-Key, по которым различаются клиенты;
+		// с другим ключом клиент определяется по IP-адресу
+		ClientAPIKeys []string `env:"WORKER_CLIENT_API_KEYS" envSeparator:","`
+	}
+
+	Priority struct {
+		HighWeight   int `env:"PRIORITY_HIGH_WEIGHT" envDefault:"6"`
+		NormalWeight int `env:"PRIORITY_NORMAL_WEIGHT" envDefault:"3"`
+		LowWeight    int `env:"PRIORITY_LOW_WEIGHT" envDefault:"1"`
+		HighMaxWords int `env:"PRIORITY_HIGH_MAX_WORDS" envDefault:"1000"`
+		LowMinWords  int `env:"PRIORITY_LOW_MIN_WORDS" envDefault:"100000"`
+	}
+
+	Retry struct {
+		MaxAttempts    int           `env:"RETRY_MAX_ATTEMPTS" envDefault:"3"`
+		InitialBackoff time.Duration `env:"RETRY_INITIAL_BACKOFF" envDefault:"1s"`
+		MaxBackoff     time.Duration `env:"RETRY_MAX_BACKOFF" envDefault:"30s"`
+		Multiplier     float64       `env:"RETRY_MULTIPLIER" envDefault:"2"`
+		Timeouts       bool          `env:"RETRY_TIMEOUTS" envDefault:"false"`
 	}
 
 	Cache struct {
 		DefaultExpiration time.Duration `env:"CACHE_DEFAULT_EXPIRATION" envDefault:"5m"`
 		CleanupInterval   time.Duration `env:"CACHE_CLEANUP_INTERVAL" envDefault:"10m"`
+		MaxBytes          int64         `env:"CACHE_MAX_BYTES" envDefault:"268435456"`
 	}
 
 	Service struct {
@@ -29,7 +67,10 @@ type Config struct {
 	}
 
 	Processing struct {
-		Timeout time.Duration `env:"PROCESSING_TIMEOUT" envDefault:"30s"`
+		Timeout          time.Duration `env:"PROCESSING_TIMEOUT" envDefault:"30s"`
+		ProgressInterval time.Duration `env:"PROCESSING_PROGRESS_INTERVAL" envDefault:"1s"`
+		// FileParallelism - сколько фрагментов одного файла группируется параллельно
+		FileParallelism int `env:"PROCESSING_FILE_PARALLELISM" envDefault:"4"`
 	}
 
 	RateLimit struct {
@@ -38,7 +79,64 @@ type Config struct {
 	}
 
 	Graceful struct {
-		ShutdownTimeout time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" envDefault:"30s"`
+		ShutdownTimeout   time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" envDefault:"30s"`
+		HTTPTimeout       time.Duration `env:"GRACEFUL_HTTP_TIMEOUT" envDefault:"10s"`
+		CheckpointEnabled bool          `env:"GRACEFUL_CHECKPOINT_ENABLED" envDefault:"true"`
+		CheckpointPath    string        `env:"GRACEFUL_CHECKPOINT_PATH" envDefault:"data/queue.checkpoint"`
+	}
+
+	Journal struct {
+		Enabled bool   `env:"JOURNAL_ENABLED" envDefault:"false"`
+		Path    string `env:"JOURNAL_PATH" envDefault:"data/tasks.journal"`
+		Sync    bool   `env:"JOURNAL_SYNC" envDefault:"true"`
+		// MaxBytes - размер журнала, при котором он сжимается во время работы
+		MaxBytes int64 `env:"JOURNAL_MAX_BYTES" envDefault:"268435456"`
+	}
+
+	Archive struct {
+		MaxImportSize int64 `env:"ARCHIVE_MAX_IMPORT_SIZE" envDefault:"104857600"`
+	}
+
+	Results struct {
+		Enabled          bool   `env:"RESULTS_ENABLED" envDefault:"true"`
+		Dir              string `env:"RESULTS_DIR" envDefault:"data/results"`
+		OffloadThreshold int64  `env:"RESULTS_OFFLOAD_THRESHOLD" envDefault:"65536"`
+		ChunkSize        int64  `env:"RESULTS_CHUNK_SIZE" envDefault:"1048576"`
+	}
+
+	Ingest struct {
+		Enabled       bool          `env:"INGEST_ENABLED" envDefault:"false"`
+		Dir           string        `env:"INGEST_DIR" envDefault:"data/inbox"`
+		Pattern       string        `env:"INGEST_PATTERN" envDefault:"*.txt"`
+		PollInterval  time.Duration `env:"INGEST_POLL_INTERVAL" envDefault:"10s"`
+		CaseSensitive bool          `env:"INGEST_CASE_SENSITIVE" envDefault:"false"`
+	}
+
+	// Admin - доступ к эндпоинтам /admin; без токена они закрыты
+	Admin struct {
+		Token string `env:"ADMIN_TOKEN"`
+	}
+
+	// Remote настраивает выдачу задач удаленным воркерам (cmd/worker)
+	Remote struct {
+		Enabled      bool          `env:"REMOTE_WORKERS_ENABLED" envDefault:"false"`
+		LeaseTTL     time.Duration `env:"REMOTE_LEASE_TTL" envDefault:"30s"`
+		MaxLeaseWait time.Duration `env:"REMOTE_LEASE_MAX_WAIT" envDefault:"10s"`
+		Token        string        `env:"REMOTE_WORKER_TOKEN"`
+	}
+
+	Schedule struct {
+		MaxDelay time.Duration `env:"SCHEDULE_MAX_DELAY" envDefault:"720h"`
+	}
+
+	Idempotency struct {
+		Window time.Duration `env:"IDEMPOTENCY_WINDOW" envDefault:"24h"`
+	}
+
+	// Spool - каталог для слов задач больше UPLOAD_BATCH_SIZE, ожидающих обработки
+	Spool struct {
+		Dir      string `env:"SPOOL_DIR" envDefault:"data/spool"`
+		MaxBytes int64  `env:"SPOOL_MAX_BYTES" envDefault:"1073741824"`
 	}
 
 	Upload struct {
@@ -48,6 +146,9 @@ type Config struct {
 	}
 }
 
+// ErrRemoteWorkerTokenRequired возвращается, если удаленные воркеры включены без токена
+var ErrRemoteWorkerTokenRequired = errors.New("REMOTE_WORKER_TOKEN is required when REMOTE_WORKERS_ENABLED=true")
+
 func LoadConfig() (*Config, error) {
 	config := &Config{}
 
@@ -55,5 +156,42 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if config.Remote.Enabled && config.Remote.Token == "" {
+		return nil, ErrRemoteWorkerTokenRequired
+	}
+
+	return config, nil
+}
+
+// WorkerConfig - конфигурация удаленного воркера (cmd/worker)
+type WorkerConfig struct {
+	// APIAddr - адрес API, у которого воркер арендует задачи
+	APIAddr string `env:"REMOTE_API_ADDR" envDefault:"http://localhost:8080"`
+	Token   string `env:"REMOTE_WORKER_TOKEN"`
+	// ID - идентификатор воркера; по умолчанию имя хоста
+	ID          string        `env:"REMOTE_WORKER_ID"`
+	Concurrency int           `env:"REMOTE_WORKER_CONCURRENCY" envDefault:"4"`
+	LeaseWait   time.Duration `env:"REMOTE_LEASE_WAIT" envDefault:"10s"`
+
+	ServiceName       string        `env:"SERVICE_NAME" envDefault:"anagram-worker"`
+	BatchSize         int           `env:"UPLOAD_BATCH_SIZE" envDefault:"10000"`
+	ProcessingTimeout time.Duration `env:"PROCESSING_TIMEOUT" envDefault:"30s"`
+}
+
+func LoadWorkerConfig() (*WorkerConfig, error) {
+	config := &WorkerConfig{}
+
+	if err := env.Parse(config); err != nil {
+		return nil, err
+	}
+
+	if config.ID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		config.ID = hostname
+	}
+
 	return config, nil
-}
\ No newline at end of file
+}
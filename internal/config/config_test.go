@@ -17,16 +17,56 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	require.Equal(t, ":8080", cfg.Server.Port)
 	require.Equal(t, 1000, cfg.Task.QueueSize) 
 	require.Equal(t, 50, cfg.Worker.Count)    
+	require.False(t, cfg.Task.Persistent)
+	require.Equal(t, "data/queue", cfg.Task.Dir)
 	require.Equal(t, 5*time.Minute, cfg.Cache.DefaultExpiration)
 	require.Equal(t, 10*time.Minute, cfg.Cache.CleanupInterval)
+	require.Equal(t, int64(268435456), cfg.Cache.MaxBytes)
 	require.Equal(t, "anagram-api", cfg.Service.Name)
 	require.Equal(t, 30*time.Second, cfg.Processing.Timeout)
 	require.Equal(t, 1000, cfg.RateLimit.Requests)
 	require.Equal(t, 1*time.Minute, cfg.RateLimit.Window)
 	require.Equal(t, 30*time.Second, cfg.Graceful.ShutdownTimeout)
+	require.True(t, cfg.Graceful.CheckpointEnabled)
+	require.Equal(t, "data/queue.checkpoint", cfg.Graceful.CheckpointPath)
 	require.Equal(t, int64(20971520), cfg.Upload.MaxFileSize)
 	require.Equal(t, "application/json,application/csv,text/plain", cfg.Upload.AllowedTypes)
 	require.Equal(t, 10000, cfg.Upload.BatchSize)
+	require.False(t, cfg.Journal.Enabled)
+	require.Equal(t, "data/tasks.journal", cfg.Journal.Path)
+	require.True(t, cfg.Journal.Sync)
+	require.Equal(t, int64(104857600), cfg.Archive.MaxImportSize)
+	require.Equal(t, 24*time.Hour, cfg.Idempotency.Window)
+	require.Equal(t, 720*time.Hour, cfg.Schedule.MaxDelay)
+	require.False(t, cfg.Ingest.Enabled)
+	require.Equal(t, "data/inbox", cfg.Ingest.Dir)
+	require.Equal(t, "*.txt", cfg.Ingest.Pattern)
+	require.Equal(t, 10*time.Second, cfg.Ingest.PollInterval)
+	require.Equal(t, 6, cfg.Priority.HighWeight)
+	require.Equal(t, 3, cfg.Priority.NormalWeight)
+	require.Equal(t, 1, cfg.Priority.LowWeight)
+	require.Equal(t, 1000, cfg.Priority.HighMaxWords)
+	require.Equal(t, 100000, cfg.Priority.LowMinWords)
+	require.Equal(t, 3, cfg.Retry.MaxAttempts)
+	require.Equal(t, time.Second, cfg.Retry.InitialBackoff)
+	require.Equal(t, 30*time.Second, cfg.Retry.MaxBackoff)
+	require.Equal(t, 2.0, cfg.Retry.Multiplier)
+	require.False(t, cfg.Retry.Timeouts)
+	require.Equal(t, time.Second, cfg.Processing.ProgressInterval)
+	require.Equal(t, time.Duration(0), cfg.Task.EnqueueTimeout)
+	require.Equal(t, 5*time.Second, cfg.Task.RetryAfter)
+	require.Equal(t, 1, cfg.Worker.Min)
+	require.Equal(t, 200, cfg.Worker.Max)
+	require.False(t, cfg.Worker.Autoscale)
+	require.Equal(t, 5*time.Second, cfg.Worker.AutoscaleInterval)
+	require.Equal(t, 10, cfg.Worker.AutoscaleQueuePerWorker)
+	require.Equal(t, 2*time.Second, cfg.Worker.AutoscaleTargetLatency)
+	require.Equal(t, 0, cfg.Worker.ClientMaxConcurrency)
+	require.Equal(t, 1000, cfg.Worker.FairBufferSize)
+	require.True(t, cfg.Results.Enabled)
+	require.Equal(t, "data/results", cfg.Results.Dir)
+	require.Equal(t, int64(65536), cfg.Results.OffloadThreshold)
+	require.Equal(t, int64(1048576), cfg.Results.ChunkSize)
 }
 
 func TestLoadConfig_WithEnvOverrides(t *testing.T) {
@@ -35,14 +75,54 @@ func TestLoadConfig_WithEnvOverrides(t *testing.T) {
 	os.Setenv("NUM_WORKERS", "8")
 	os.Setenv("CACHE_DEFAULT_EXPIRATION", "2m")
 	os.Setenv("CACHE_CLEANUP_INTERVAL", "3m")
+	os.Setenv("CACHE_MAX_BYTES", "4096")
 	os.Setenv("SERVICE_NAME", "custom-service")
 	os.Setenv("PROCESSING_TIMEOUT", "45s")
 	os.Setenv("RATE_LIMIT_REQUESTS", "200")
 	os.Setenv("RATE_LIMIT_WINDOW", "2m")
 	os.Setenv("GRACEFUL_SHUTDOWN_TIMEOUT", "10s")
+	os.Setenv("GRACEFUL_CHECKPOINT_ENABLED", "false")
+	os.Setenv("GRACEFUL_CHECKPOINT_PATH", "/var/lib/anagram/queue.checkpoint")
 	os.Setenv("UPLOAD_MAX_FILE_SIZE", "1024")
 	os.Setenv("UPLOAD_ALLOWED_TYPES", "text/plain")
 	os.Setenv("UPLOAD_BATCH_SIZE", "123")
+	os.Setenv("JOURNAL_ENABLED", "true")
+	os.Setenv("JOURNAL_PATH", "/var/lib/anagram/journal")
+	os.Setenv("JOURNAL_SYNC", "false")
+	os.Setenv("ARCHIVE_MAX_IMPORT_SIZE", "2048")
+	os.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	os.Setenv("SCHEDULE_MAX_DELAY", "48h")
+	os.Setenv("INGEST_ENABLED", "true")
+	os.Setenv("INGEST_DIR", "/srv/inbox")
+	os.Setenv("INGEST_PATTERN", "*.words")
+	os.Setenv("INGEST_POLL_INTERVAL", "1m")
+	os.Setenv("PRIORITY_HIGH_WEIGHT", "10")
+	os.Setenv("PRIORITY_NORMAL_WEIGHT", "5")
+	os.Setenv("PRIORITY_LOW_WEIGHT", "2")
+	os.Setenv("PRIORITY_HIGH_MAX_WORDS", "50")
+	os.Setenv("PRIORITY_LOW_MIN_WORDS", "5000")
+	os.Setenv("RETRY_MAX_ATTEMPTS", "5")
+	os.Setenv("RETRY_INITIAL_BACKOFF", "200ms")
+	os.Setenv("RETRY_MAX_BACKOFF", "1m")
+	os.Setenv("RETRY_MULTIPLIER", "1.5")
+	os.Setenv("RETRY_TIMEOUTS", "true")
+	os.Setenv("PROCESSING_PROGRESS_INTERVAL", "250ms")
+	os.Setenv("TASK_ENQUEUE_TIMEOUT", "100ms")
+	os.Setenv("TASK_QUEUE_RETRY_AFTER", "10s")
+	os.Setenv("TASK_QUEUE_PERSISTENT", "true")
+	os.Setenv("TASK_QUEUE_DIR", "/var/lib/anagram/queue")
+	os.Setenv("WORKER_MIN", "2")
+	os.Setenv("WORKER_MAX", "32")
+	os.Setenv("WORKER_AUTOSCALE", "true")
+	os.Setenv("WORKER_AUTOSCALE_INTERVAL", "1s")
+	os.Setenv("WORKER_AUTOSCALE_QUEUE_PER_WORKER", "4")
+	os.Setenv("WORKER_AUTOSCALE_TARGET_LATENCY", "500ms")
+	os.Setenv("WORKER_CLIENT_MAX_CONCURRENCY", "5")
+	os.Setenv("WORKER_FAIR_BUFFER_SIZE", "200")
+	os.Setenv("RESULTS_ENABLED", "false")
+	os.Setenv("RESULTS_DIR", "/var/lib/anagram/results")
+	os.Setenv("RESULTS_OFFLOAD_THRESHOLD", "512")
+	os.Setenv("RESULTS_CHUNK_SIZE", "4096")
 
 	defer os.Clearenv()
 
@@ -51,15 +131,68 @@ func TestLoadConfig_WithEnvOverrides(t *testing.T) {
 
 	require.Equal(t, ":9999", cfg.Server.Port)
 	require.Equal(t, 500, cfg.Task.QueueSize)
+	require.True(t, cfg.Task.Persistent)
+	require.Equal(t, "/var/lib/anagram/queue", cfg.Task.Dir)
 	require.Equal(t, 8, cfg.Worker.Count)
 	require.Equal(t, 2*time.Minute, cfg.Cache.DefaultExpiration)
 	require.Equal(t, 3*time.Minute, cfg.Cache.CleanupInterval)
+	require.Equal(t, int64(4096), cfg.Cache.MaxBytes)
 	require.Equal(t, "custom-service", cfg.Service.Name)
 	require.Equal(t, 45*time.Second, cfg.Processing.Timeout)
 	require.Equal(t, 200, cfg.RateLimit.Requests)
 	require.Equal(t, 2*time.Minute, cfg.RateLimit.Window)
 	require.Equal(t, 10*time.Second, cfg.Graceful.ShutdownTimeout)
+	require.False(t, cfg.Graceful.CheckpointEnabled)
+	require.Equal(t, "/var/lib/anagram/queue.checkpoint", cfg.Graceful.CheckpointPath)
 	require.Equal(t, int64(1024), cfg.Upload.MaxFileSize)
 	require.Equal(t, "text/plain", cfg.Upload.AllowedTypes)
 	require.Equal(t, 123, cfg.Upload.BatchSize)
+	require.True(t, cfg.Journal.Enabled)
+	require.Equal(t, "/var/lib/anagram/journal", cfg.Journal.Path)
+	require.False(t, cfg.Journal.Sync)
+	require.Equal(t, int64(2048), cfg.Archive.MaxImportSize)
+	require.Equal(t, time.Hour, cfg.Idempotency.Window)
+	require.Equal(t, 48*time.Hour, cfg.Schedule.MaxDelay)
+	require.True(t, cfg.Ingest.Enabled)
+	require.Equal(t, "/srv/inbox", cfg.Ingest.Dir)
+	require.Equal(t, "*.words", cfg.Ingest.Pattern)
+	require.Equal(t, time.Minute, cfg.Ingest.PollInterval)
+	require.Equal(t, 10, cfg.Priority.HighWeight)
+	require.Equal(t, 5, cfg.Priority.NormalWeight)
+	require.Equal(t, 2, cfg.Priority.LowWeight)
+	require.Equal(t, 50, cfg.Priority.HighMaxWords)
+	require.Equal(t, 5000, cfg.Priority.LowMinWords)
+	require.Equal(t, 5, cfg.Retry.MaxAttempts)
+	require.Equal(t, 200*time.Millisecond, cfg.Retry.InitialBackoff)
+	require.Equal(t, time.Minute, cfg.Retry.MaxBackoff)
+	require.Equal(t, 1.5, cfg.Retry.Multiplier)
+	require.True(t, cfg.Retry.Timeouts)
+	require.Equal(t, 250*time.Millisecond, cfg.Processing.ProgressInterval)
+	require.Equal(t, 100*time.Millisecond, cfg.Task.EnqueueTimeout)
+	require.Equal(t, 10*time.Second, cfg.Task.RetryAfter)
+	require.Equal(t, 2, cfg.Worker.Min)
+	require.Equal(t, 32, cfg.Worker.Max)
+	require.True(t, cfg.Worker.Autoscale)
+	require.Equal(t, time.Second, cfg.Worker.AutoscaleInterval)
+	require.Equal(t, 4, cfg.Worker.AutoscaleQueuePerWorker)
+	require.Equal(t, 500*time.Millisecond, cfg.Worker.AutoscaleTargetLatency)
+	require.Equal(t, 5, cfg.Worker.ClientMaxConcurrency)
+	require.Equal(t, 200, cfg.Worker.FairBufferSize)
+	require.False(t, cfg.Results.Enabled)
+	require.Equal(t, "/var/lib/anagram/results", cfg.Results.Dir)
+	require.Equal(t, int64(512), cfg.Results.OffloadThreshold)
+	require.Equal(t, int64(4096), cfg.Results.ChunkSize)
+}
+
+func TestLoadConfig_RemoteWorkersRequireToken(t *testing.T) {
+	os.Setenv("REMOTE_WORKERS_ENABLED", "true")
+	defer os.Clearenv()
+
+	_, err := LoadConfig()
+	require.ErrorIs(t, err, ErrRemoteWorkerTokenRequired)
+
+	os.Setenv("REMOTE_WORKER_TOKEN", "secret")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	require.Equal(t, "secret", cfg.Remote.Token)
 }
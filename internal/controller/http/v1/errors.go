@@ -2,7 +2,10 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
 )
 
 // ErrorResponse представляет ответ с ошибкой для клиента
@@ -58,6 +61,20 @@ var (
 		Status:  http.StatusNotFound,
 	}
 
+	// ErrTaskVersionConflict ошибка параллельного изменения задачи
+	ErrTaskVersionConflict = &APIError{
+		Code:    "TASK_VERSION_CONFLICT",
+		Message: "task was modified concurrently",
+		Status:  http.StatusConflict,
+	}
+
+	// ErrStorageUnavailable ошибка временной недоступности хранилища
+	ErrStorageUnavailable = &APIError{
+		Code:    "STORAGE_UNAVAILABLE",
+		Message: "storage is temporarily unavailable",
+		Status:  http.StatusServiceUnavailable,
+	}
+
 	// ErrInternalServer внутренняя ошибка сервера
 	ErrInternalServer = &APIError{
 		Code:    "INTERNAL_SERVER_ERROR",
@@ -71,4 +88,124 @@ var (
 		Message: "failed to create task",
 		Status:  http.StatusInternalServerError,
 	}
+
+	// ErrCacheEntryNotFound ошибка отсутствия задачи в кэше
+	ErrCacheEntryNotFound = &APIError{
+		Code:    "CACHE_ENTRY_NOT_FOUND",
+		Message: "task is not cached",
+		Status:  http.StatusNotFound,
+	}
+
+	// ErrInvalidArchive ошибка некорректного или поврежденного архива
+	ErrInvalidArchive = &APIError{
+		Code:    "INVALID_ARCHIVE",
+		Message: "invalid or corrupted archive",
+		Status:  http.StatusBadRequest,
+	}
+
+	// ErrImportConflict ошибка конфликта задач при импорте
+	ErrImportConflict = &APIError{
+		Code:    "IMPORT_CONFLICT",
+		Message: "task from archive already exists",
+		Status:  http.StatusConflict,
+	}
+
+	// ErrInvalidIdempotencyKey ошибка некорректного заголовка Idempotency-Key
+	ErrInvalidIdempotencyKey = &APIError{
+		Code:    "INVALID_IDEMPOTENCY_KEY",
+		Message: "invalid Idempotency-Key header",
+		Details: "key must be 1-255 printable ASCII characters",
+		Status:  http.StatusBadRequest,
+	}
+
+	// ErrIdempotencyKeyReused ошибка повторного использования ключа с другим запросом
+	ErrIdempotencyKeyReused = &APIError{
+		Code:    "IDEMPOTENCY_KEY_REUSED",
+		Message: "idempotency key was already used with a different request",
+		Status:  http.StatusUnprocessableEntity,
+	}
+
+	// ErrTaskNotDeadLetter ошибка повторного запуска задачи не в статусе dead_letter
+	ErrTaskNotDeadLetter = &APIError{
+		Code:    "TASK_NOT_DEAD_LETTER",
+		Message: "task is not in dead_letter status",
+		Status:  http.StatusConflict,
+	}
+
+	// ErrQueueFull ошибка переполнения очереди задач
+	ErrQueueFull = &APIError{
+		Code:    "QUEUE_FULL",
+		Message: "task queue is full, retry later",
+		Status:  http.StatusServiceUnavailable,
+	}
+
+	// ErrSpoolQuotaExceeded ошибка превышения квоты на диске для больших задач
+	ErrSpoolQuotaExceeded = &APIError{
+		Code:    "SPOOL_QUOTA_EXCEEDED",
+		Message: "not enough disk space for large task, retry later or send fewer words",
+		Status:  http.StatusInsufficientStorage,
+	}
+
+	// ErrWorkerPoolUnavailable ошибка управления остановленным или отсутствующим пулом воркеров
+	ErrWorkerPoolUnavailable = &APIError{
+		Code:    "WORKER_POOL_UNAVAILABLE",
+		Message: "worker pool is not available",
+		Status:  http.StatusServiceUnavailable,
+	}
+
+	// ErrTaskNotCancellable ошибка отмены уже завершенной задачи
+	ErrTaskNotCancellable = &APIError{
+		Code:    "TASK_NOT_CANCELLABLE",
+		Message: "task is already finished",
+		Status:  http.StatusConflict,
+	}
+
+	// ErrRemoteWorkersUnavailable ошибка обращения удаленного воркера, когда аренда задач отключена или остановлена
+	ErrRemoteWorkersUnavailable = &APIError{
+		Code:    "REMOTE_WORKERS_UNAVAILABLE",
+		Message: "remote workers are not available",
+		Status:  http.StatusServiceUnavailable,
+	}
+
+	// ErrAdminUnauthorized ошибка неверного токена администратора
+	ErrAdminUnauthorized = &APIError{
+		Code:    "ADMIN_UNAUTHORIZED",
+		Message: "invalid admin token",
+		Status:  http.StatusUnauthorized,
+	}
+
+	// ErrWorkerUnauthorized ошибка неверного токена удаленного воркера
+	ErrWorkerUnauthorized = &APIError{
+		Code:    "WORKER_UNAUTHORIZED",
+		Message: "invalid worker token",
+		Status:  http.StatusUnauthorized,
+	}
+
+	// ErrLeaseNotFound ошибка обращения к истекшей или отозванной аренде
+	ErrLeaseNotFound = &APIError{
+		Code:    "LEASE_NOT_FOUND",
+		Message: "lease not found or expired",
+		Status:  http.StatusGone,
+	}
+
+	// ErrSchedulingUnavailable ошибка создания отложенной задачи без диспетчера
+	ErrSchedulingUnavailable = &APIError{
+		Code:    "SCHEDULING_UNAVAILABLE",
+		Message: "delayed tasks are not available",
+		Status:  http.StatusServiceUnavailable,
+	}
 )
+
+// storageError сопоставляет ошибку хранилища с ошибкой API
+func storageError(err error) *APIError {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return ErrTaskNotFound
+	case errors.Is(err, storage.ErrVersionConflict):
+		return ErrTaskVersionConflict
+	case errors.Is(err, storage.ErrUnavailable):
+		return ErrStorageUnavailable
+	default:
+		return ErrInternalServer
+	}
+}
@@ -22,14 +22,29 @@ package v1
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
 	"github.com/grcflEgor/go-anagram-api/internal/config"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"github.com/grcflEgor/go-anagram-api/internal/worker"
 	"github.com/grcflEgor/go-anagram-api/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -39,6 +54,9 @@ type Handlers struct {
 	validator      *validator.Validate
 	config         *config.Config
 	stats          service.TaskStatsProvider
+	workerPool     service.WorkerPoolScaler
+	taskQueue      service.QueueDepthProvider
+	leases         service.TaskLeaser
 }
 
 func NewHandlers(anagramService service.AnagramServiceProvider, validator *validator.Validate, config *config.Config, stats service.TaskStatsProvider) *Handlers {
@@ -50,22 +68,50 @@ func NewHandlers(anagramService service.AnagramServiceProvider, validator *valid
 	}
 }
 
+// SetTaskQueue подключает очередь задач, глубина которой выводится в проверке состояния
+func (h *Handlers) SetTaskQueue(queue service.QueueDepthProvider) {
+	h.taskQueue = queue
+}
+
+// SetTaskLeaser подключает аренду задач для удаленных воркеров
+func (h *Handlers) SetTaskLeaser(leases service.TaskLeaser) {
+	h.leases = leases
+}
+
+// SetWorkerPool подключает пул воркеров для административных эндпоинтов
+func (h *Handlers) SetWorkerPool(pool service.WorkerPoolScaler) {
+	h.workerPool = pool
+}
+
 // GroupAnagrams godoc
 // @Summary      Создать задачу для группировки анаграмм
-// @Description  Принимает список слов и создает асинхронную задачу для группировки анаграмм
+// @Description  Принимает список слов и создает асинхронную задачу для группировки анаграмм. С run_at или delay задача получает статус scheduled и ставится в очередь в назначенное время
 // @Tags         anagrams
 // @Accept       json
 // @Produce      json
 // @Param        request body GroupRequest true "Список слов и настройки группировки"
+// @Param        Idempotency-Key header string false "Ключ идемпотентности: повтор запроса с тем же ключом вернет ту же задачу"
+// @Param        X-API-Key header string false "Ключ клиента: задачи разных клиентов обрабатываются по очереди"
 // @Success      202 {object} CreateTaskResponse "Задача создана успешно"
 // @Failure      400 {object} APIError "Ошибка валидации или некорректный запрос"
+// @Failure      422 {object} APIError "Ключ идемпотентности уже использован с другим запросом"
 // @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Failure      503 {object} APIError "Очередь задач заполнена или хранилище недоступно"
+// @Failure      507 {object} APIError "Превышена квота на диске для больших задач"
+// @Header       503 {integer} Retry-After "Через сколько секунд повторить запрос"
 // @Router       /api/v1/anagrams/group [post]
 func (h *Handlers) GroupAnagrams(w http.ResponseWriter, r *http.Request) {
 	l := logger.FromContext(r.Context())
 
 	var request GroupRequest
 
+	idempotencyKey, ok := readIdempotencyKey(r)
+	if !ok {
+		l.Info("invalid idempotency key")
+		WriteError(w, ErrInvalidIdempotencyKey)
+		return
+	}
+
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		l.Info("invalid request body")
 		WriteError(w, ErrInvalidRequest)
@@ -84,14 +130,33 @@ func (h *Handlers) GroupAnagrams(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID, err := h.anagramService.CreateTask(r.Context(), request.Words, request.CaseSensitive)
+	runAt, delay, err := h.scheduleTime(request.RunAt, request.Delay, time.Now())
 	if err != nil {
-		l.Error("failed to create task", zap.Error(err))
-		WriteError(w, ErrTaskCreationFailed)
+		l.Info("invalid schedule", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
 		return
 	}
 
-	response := CreateTaskResponse{TaskID: taskID}
+	taskID, err := h.anagramService.CreateTask(r.Context(), request.Words, domain.TaskOptions{
+		CaseSensitive:  request.CaseSensitive,
+		NoMemo:         request.NoMemo,
+		IdempotencyKey: idempotencyKey,
+		Priority:       domain.TaskPriority(request.Priority),
+		ClientID:       h.clientID(r),
+		RunAt:          runAt,
+		Delay:          delay,
+	})
+	if err != nil {
+		h.writeCreateTaskError(w, l, err)
+		return
+	}
+
+	response := newCreateTaskResponse(taskID, runAt)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -101,7 +166,7 @@ func (h *Handlers) GroupAnagrams(w http.ResponseWriter, r *http.Request) {
 
 // HealthCheck godoc
 // @Summary      Проверка доступности сервиса
-// @Description  Возвращает статус работоспособности API
+// @Description  Возвращает статус работоспособности API и количество задач в очереди
 // @Tags         health
 // @Produce      json
 // @Success      200 {object} HealthResponse "Сервис доступен"
@@ -110,6 +175,9 @@ func (h *Handlers) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	l := logger.FromContext(r.Context())
 
 	response := HealthResponse{Status: "ok"}
+	if h.taskQueue != nil {
+		response.QueueDepth = h.taskQueue.Len()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -119,13 +187,18 @@ func (h *Handlers) HealthCheck(w http.ResponseWriter, r *http.Request) {
 
 // GetResult godoc
 // @Summary      Получить результат задачи
-// @Description  Возвращает результат группировки анаграмм по ID задачи
+// @Description  Возвращает результат группировки анаграмм по ID задачи, а для задачи в обработке - прогресс и оценку оставшегося времени
 // @Tags         anagrams
 // @Produce      json
 // @Param        id path string true "ID задачи" example("task-123")
-// @Success      200 {object} domain.Task "Результат группировки"
+// @Param        If-None-Match header string false "ETag ранее полученной версии задачи"
+// @Success      200 {object} TaskResponse "Результат группировки"
+// @Header       200 {string} ETag "Версия задачи"
+// @Success      304 "Задача не изменилась"
 // @Failure      400 {object} APIError "Отсутствует ID задачи"
 // @Failure      404 {object} APIError "Задача не найдена"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Failure      503 {object} APIError "Хранилище временно недоступно"
 // @Router       /api/v1/anagrams/groups/{id} [get]
 func (h *Handlers) GetResult(w http.ResponseWriter, r *http.Request) {
 	l := logger.FromContext(r.Context())
@@ -144,17 +217,111 @@ func (h *Handlers) GetResult(w http.ResponseWriter, r *http.Request) {
 
 	task, err := h.anagramService.GetTaskByID(r.Context(), taskID)
 	if err != nil {
-		l.Error("failed to get task by id", zap.Error(err))
-		WriteError(w, ErrTaskNotFound)
+		apiErr := storageError(err)
+		if apiErr == ErrTaskNotFound {
+			l.Info("task not found", zap.String("task_id", taskID))
+		} else {
+			l.Error("failed to get task by id", zap.Error(err))
+		}
+		WriteError(w, apiErr)
+		return
+	}
+
+	etag := taskETag(task)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(task); err != nil {
+	if err := json.NewEncoder(w).Encode(newTaskResponse(task, time.Now())); err != nil {
 		l.Error("failed to write get result", zap.Error(err))
 	}
 }
 
+func newTaskResponse(task *domain.Task, now time.Time) TaskResponse {
+	response := TaskResponse{Task: task}
+	if task.Progress == nil {
+		return response
+	}
+
+	response.Progress = &ProgressResponse{
+		WordsProcessed: task.Progress.WordsProcessed,
+		TotalWords:     task.Progress.TotalWords,
+		BatchesMerged:  task.Progress.BatchesMerged,
+		Percent:        task.Progress.Percent(),
+	}
+	if task.Status == domain.StatusProcessing {
+		if eta, ok := task.Progress.ETA(now); ok {
+			seconds := eta.Seconds()
+			response.Progress.ETASeconds = &seconds
+		}
+	}
+	return response
+}
+
+func taskETag(task *domain.Task) string {
+	return `"` + strconv.FormatUint(task.Version, 10) + `"`
+}
+
+// etagMatches проверяет заголовок If-None-Match, который может содержать
+// несколько ETag через запятую, слабые ETag или "*"
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelTask godoc
+// @Summary      Отменить задачу
+// @Description  Отменяет отложенную задачу, удаляет задачу из очереди или прерывает ее обработку. Выполняющаяся задача получает статус cancelled асинхронно
+// @Tags         anagrams
+// @Produce      json
+// @Param        id path string true "ID задачи" example("task-123")
+// @Success      202 {object} CreateTaskResponse "Отмена принята"
+// @Failure      400 {object} APIError "Отсутствует ID задачи"
+// @Failure      404 {object} APIError "Задача не найдена"
+// @Failure      409 {object} APIError "Задача уже завершена"
+// @Failure      503 {object} APIError "Хранилище временно недоступно"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/anagrams/groups/{id}/cancel [post]
+func (h *Handlers) CancelTask(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	taskID := chi.URLParam(r, "id")
+	if taskID == "" {
+		l.Info("task ID is required")
+		WriteError(w, &APIError{
+			Code:    "MISSING_TASK_ID",
+			Message: "task ID is required",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.anagramService.CancelTask(r.Context(), taskID); err != nil {
+		if errors.Is(err, service.ErrTaskNotCancellable) {
+			l.Info("task is already finished", zap.String("task_id", taskID))
+			WriteError(w, ErrTaskNotCancellable)
+			return
+		}
+		l.Error("failed to cancel task", zap.String("task_id", taskID), zap.Error(err))
+		WriteError(w, storageError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(CreateTaskResponse{TaskID: taskID}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
 // UploadFile godoc
 // @Summary      Загрузить файл со словами
 // @Description  Загружает текстовый файл, содержащий слова, разделённые пробелами/переносами строк
@@ -163,14 +330,31 @@ func (h *Handlers) GetResult(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        file formData file true "Файл со словами (текстовый файл)"
 // @Param        case_sensitive formData string false "Учитывать регистр (true/false)" example("false")
+// @Param        no_memo formData string false "Не переиспользовать готовый результат (true/false)" example("false")
+// @Param        priority formData string false "Приоритет задачи (high/normal/low)" example("low")
+// @Param        run_at formData string false "Время запуска задачи в формате RFC 3339" example("2024-01-01T03:00:00Z")
+// @Param        delay formData string false "Задержка запуска задачи, например 30m или 2h" example("30m")
+// @Param        Idempotency-Key header string false "Ключ идемпотентности: повтор запроса с тем же ключом вернет ту же задачу"
+// @Param        X-API-Key header string false "Ключ клиента: задачи разных клиентов обрабатываются по очереди"
 // @Success      202 {object} CreateTaskResponse "Файл загружен, задача создана"
 // @Failure      400 {object} APIError "Некорректный файл или пустой файл"
+// @Failure      422 {object} APIError "Ключ идемпотентности уже использован с другим запросом"
 // @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Failure      503 {object} APIError "Очередь задач заполнена или хранилище недоступно"
+// @Failure      507 {object} APIError "Превышена квота на диске для больших задач"
+// @Header       503 {integer} Retry-After "Через сколько секунд повторить запрос"
 // @Router       /api/v1/anagrams/upload [post]
 func (h *Handlers) UploadFile(w http.ResponseWriter, r *http.Request) {
 	l := logger.FromContext(r.Context())
 	ctx := r.Context()
 
+	idempotencyKey, ok := readIdempotencyKey(r)
+	if !ok {
+		l.Info("invalid idempotency key")
+		WriteError(w, ErrInvalidIdempotencyKey)
+		return
+	}
+
 	r.Body = http.MaxBytesReader(w, r.Body, h.config.Upload.MaxFileSize)
 	if err := r.ParseMultipartForm(h.config.Upload.MaxFileSize); err != nil {
 		l.Error("failed to parse multipart form", zap.Error(err))
@@ -206,21 +390,47 @@ func (h *Handlers) UploadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	caseSensitive := false
-	if values := r.MultipartForm.Value["case_sensitive"]; len(values) > 0 {
-		if strings.ToLower(values[0]) == "true" {
-			caseSensitive = true
-		}
+	priority, err := domain.ParsePriority(r.FormValue("priority"))
+	if err != nil {
+		l.Info("invalid priority", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	runAt, delay, err := h.formScheduleTime(r, time.Now())
+	if err != nil {
+		l.Info("invalid schedule", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
 	}
 
-	taskID, err := h.anagramService.CreateTask(ctx, words, caseSensitive)
+	opts := domain.TaskOptions{
+		CaseSensitive:  formBool(r, "case_sensitive"),
+		NoMemo:         formBool(r, "no_memo"),
+		IdempotencyKey: idempotencyKey,
+		Priority:       priority,
+		ClientID:       h.clientID(r),
+		RunAt:          runAt,
+		Delay:          delay,
+	}
+
+	taskID, err := h.anagramService.CreateTask(ctx, words, opts)
 	if err != nil {
-		l.Error("failed to create task", zap.Error(err))
-		WriteError(w, ErrTaskCreationFailed)
+		h.writeCreateTaskError(w, l, err)
 		return
 	}
 
-	resp := CreateTaskResponse{TaskID: taskID}
+	resp := newCreateTaskResponse(taskID, runAt)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -230,9 +440,149 @@ func (h *Handlers) UploadFile(w http.ResponseWriter, r *http.Request) {
 
 }
 
+const maxIdempotencyKeyLength = 255
+
+// readIdempotencyKey возвращает значение заголовка Idempotency-Key и false, если оно некорректно
+func readIdempotencyKey(r *http.Request) (string, bool) {
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLength {
+		return "", false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return "", false
+		}
+	}
+	return key, true
+}
+
+// clientID определяет клиента, создающего задачу: по заголовку X-API-Key,
+// если это один из настроенных ключей, иначе по IP-адресу. Произвольные ключи
+// не принимаются, иначе клиент мог бы менять их и обходить свою долю воркеров.
+// Сам ключ в задаче не хранится.
+func (h *Handlers) clientID(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" && h.knownAPIKey(key) {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func (h *Handlers) knownAPIKey(key string) bool {
+	known := false
+	for _, configured := range h.config.Worker.ClientAPIKeys {
+		if configured != "" && subtle.ConstantTimeCompare([]byte(key), []byte(configured)) == 1 {
+			known = true
+		}
+	}
+	return known
+}
+
+func (h *Handlers) writeCreateTaskError(w http.ResponseWriter, l *zap.Logger, err error) {
+	if errors.Is(err, service.ErrIdempotencyConflict) {
+		l.Info("idempotency key reused with different request")
+		WriteError(w, ErrIdempotencyKeyReused)
+		return
+	}
+	if errors.Is(err, queue.ErrQueueFull) {
+		l.Warn("task queue is full")
+		h.writeRetryAfter(w, ErrQueueFull)
+		return
+	}
+	if errors.Is(err, service.ErrSpoolQuotaExceeded) {
+		l.Warn("spool quota exceeded", zap.Error(err))
+		h.writeRetryAfter(w, ErrSpoolQuotaExceeded)
+		return
+	}
+	if errors.Is(err, service.ErrSchedulingNotSupported) {
+		l.Warn("delayed task requested without dispatcher")
+		WriteError(w, ErrSchedulingUnavailable)
+		return
+	}
+
+	l.Error("failed to create task", zap.Error(err))
+	if errors.Is(err, storage.ErrUnavailable) {
+		WriteError(w, ErrStorageUnavailable)
+		return
+	}
+	WriteError(w, ErrTaskCreationFailed)
+}
+
+// writeRetryAfter отвечает ошибкой с Retry-After, чтобы клиенты повторили запрос
+// после того как воркеры разберут очередь и освободят место
+func (h *Handlers) writeRetryAfter(w http.ResponseWriter, apiErr *APIError) {
+	retryAfter := int(math.Ceil(h.config.Task.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	WriteError(w, apiErr)
+}
+
+// scheduleTime возвращает время запуска задачи по run_at или delay и саму задержку.
+// Нулевое время означает запуск сразу.
+func (h *Handlers) scheduleTime(runAt *time.Time, delay string, now time.Time) (time.Time, time.Duration, error) {
+	if runAt != nil && delay != "" {
+		return time.Time{}, 0, errors.New("run_at and delay cannot be used together")
+	}
+
+	var at time.Time
+	var d time.Duration
+	switch {
+	case runAt != nil:
+		at = *runAt
+	case delay != "":
+		var err error
+		d, err = time.ParseDuration(delay)
+		if err != nil {
+			return time.Time{}, 0, fmt.Errorf("invalid delay: %w", err)
+		}
+		if d < 0 {
+			return time.Time{}, 0, errors.New("delay must not be negative")
+		}
+		at = now.Add(d)
+	}
+
+	if maxDelay := h.config.Schedule.MaxDelay; maxDelay > 0 && at.Sub(now) > maxDelay {
+		return time.Time{}, 0, fmt.Errorf("task cannot be scheduled more than %s ahead", maxDelay)
+	}
+	return at, d, nil
+}
+
+func (h *Handlers) formScheduleTime(r *http.Request, now time.Time) (time.Time, time.Duration, error) {
+	var runAt *time.Time
+	if value := r.FormValue("run_at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, 0, fmt.Errorf("invalid run_at: %w", err)
+		}
+		runAt = &parsed
+	}
+	return h.scheduleTime(runAt, r.FormValue("delay"), now)
+}
+
+func newCreateTaskResponse(taskID string, runAt time.Time) CreateTaskResponse {
+	response := CreateTaskResponse{TaskID: taskID}
+	if runAt.After(time.Now()) {
+		response.RunAt = &runAt
+	}
+	return response
+}
+
+func formBool(r *http.Request, key string) bool {
+	if values := r.MultipartForm.Value[key]; len(values) > 0 {
+		return strings.ToLower(values[0]) == "true"
+	}
+	return false
+}
+
 // GetStats godoc
 // @Summary      Получить статистику задач
-// @Description  Возвращает статистику по всем задачам: общее количество, завершенные, неудачные
+// @Description  Возвращает статистику по всем задачам: общее количество, завершенные, неудачные, счетчики кэша результатов и глубину очереди
 // @Tags         stats
 // @Produce      json
 // @Success      200 {object} StatsResponse "Статистика задач"
@@ -277,3 +627,689 @@ func (h *Handlers) ClearCache(w http.ResponseWriter, r *http.Request) {
 
 	WriteError(w, ErrInternalServer)
 }
+
+// GetCacheSummary godoc
+// @Summary      Получить сводку о кэше
+// @Description  Возвращает количество записей, объем кэша и самую старую запись
+// @Tags         cache
+// @Produce      json
+// @Success      200 {object} CacheSummaryResponse "Сводка о кэше"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/anagrams/cache [get]
+func (h *Handlers) GetCacheSummary(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	summary, err := h.anagramService.CacheSummary(r.Context())
+	if err != nil {
+		l.Error("failed to get cache summary", zap.Error(err))
+		WriteError(w, ErrInternalServer)
+		return
+	}
+
+	response := CacheSummaryResponse{
+		Entries:  summary.Entries,
+		Bytes:    summary.Bytes,
+		MaxBytes: summary.MaxBytes,
+	}
+	if summary.OldestTaskID != "" {
+		response.OldestEntry = &CacheEntryResponse{
+			TaskID:   summary.OldestTaskID,
+			CachedAt: summary.OldestCachedAt,
+			AgeMS:    time.Since(summary.OldestCachedAt).Milliseconds(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		l.Error("failed to write cache summary", zap.Error(err))
+	}
+}
+
+// InvalidateCacheEntry godoc
+// @Summary      Удалить задачу из кэша
+// @Description  Удаляет из кэша одну задачу по ID, не затрагивая хранилище
+// @Tags         cache
+// @Param        id path string true "ID задачи" example("task-123")
+// @Success      204 "Задача удалена из кэша"
+// @Failure      400 {object} APIError "Отсутствует ID задачи"
+// @Failure      404 {object} APIError "Задача отсутствует в кэше"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/anagrams/cache/{id} [delete]
+func (h *Handlers) InvalidateCacheEntry(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	taskID := chi.URLParam(r, "id")
+	if taskID == "" {
+		l.Info("task ID is required")
+		missingIDError := &APIError{
+			Code:    "MISSING_TASK_ID",
+			Message: "task ID is required",
+			Status:  http.StatusBadRequest,
+		}
+		WriteError(w, missingIDError)
+		return
+	}
+
+	if err := h.anagramService.InvalidateCache(r.Context(), taskID); err != nil {
+		if errors.Is(err, storage.ErrNotCached) {
+			l.Info("task is not cached", zap.String("task_id", taskID))
+			WriteError(w, ErrCacheEntryNotFound)
+			return
+		}
+		l.Error("failed to invalidate cache entry", zap.Error(err))
+		WriteError(w, ErrInternalServer)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InvalidateCache godoc
+// @Summary      Удалить из кэша задачи по фильтру
+// @Description  Удаляет из кэша задачи, подходящие под все заданные условия: список ID, статус и возраст записи
+// @Tags         cache
+// @Accept       json
+// @Produce      json
+// @Param        request body InvalidateCacheRequest true "Фильтр инвалидации"
+// @Success      200 {object} InvalidateCacheResponse "Количество удаленных записей"
+// @Failure      400 {object} APIError "Ошибка валидации или пустой фильтр"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/anagrams/cache/invalidate [post]
+func (h *Handlers) InvalidateCache(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	var request InvalidateCacheRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.Info("invalid request body")
+		WriteError(w, ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		l.Info("validation failed", zap.Error(err))
+		validationError := &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		}
+		WriteError(w, validationError)
+		return
+	}
+
+	filter := domain.CacheFilter{
+		TaskIDs: request.TaskIDs,
+		Status:  domain.TaskStatus(request.Status),
+	}
+	if request.OlderThan != "" {
+		olderThan, err := time.ParseDuration(request.OlderThan)
+		if err != nil || olderThan <= 0 {
+			l.Info("invalid older_than", zap.String("older_than", request.OlderThan))
+			WriteError(w, &APIError{
+				Code:    "VALIDATION_FAILED",
+				Message: "validation failed",
+				Details: "older_than must be a positive duration",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+		filter.OlderThan = olderThan
+	}
+
+	if filter.IsEmpty() {
+		l.Info("empty cache filter")
+		WriteError(w, &APIError{
+			Code:    "EMPTY_FILTER",
+			Message: "at least one filter is required",
+			Details: "use DELETE /anagrams/cache to flush the whole cache",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	evicted, err := h.anagramService.InvalidateCacheMatching(r.Context(), filter)
+	if err != nil {
+		l.Error("failed to invalidate cache", zap.Error(err))
+		WriteError(w, ErrInternalServer)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(InvalidateCacheResponse{Evicted: evicted}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// ExportTasks godoc
+// @Summary      Экспортировать задачи
+// @Description  Выгружает завершенные задачи с результатами в формате NDJSON с контрольными суммами
+// @Tags         admin
+// @Produce      application/x-ndjson
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Param        status query string false "Статусы через запятую (completed, failed)" example("completed")
+// @Success      200 {file} file "Архив задач"
+// @Failure      400 {object} APIError "Некорректный статус"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/admin/tasks/export [get]
+func (h *Handlers) ExportTasks(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	var statuses []domain.TaskStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if status != string(domain.StatusCompleted) && status != string(domain.StatusFailed) {
+				l.Info("invalid export status", zap.String("status", status))
+				WriteError(w, &APIError{
+					Code:    "VALIDATION_FAILED",
+					Message: "validation failed",
+					Details: "status must be completed or failed",
+					Status:  http.StatusBadRequest,
+				})
+				return
+			}
+			statuses = append(statuses, domain.TaskStatus(status))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="tasks.ndjson"`)
+
+	count, err := h.anagramService.ExportTasks(r.Context(), w, statuses)
+	if err != nil {
+		// заголовки могли уже уйти клиенту, поэтому ошибка только логируется;
+		// оборванный архив не пройдет проверку завершающей записи при импорте
+		l.Error("failed to export tasks", zap.Int("exported", count), zap.Error(err))
+		return
+	}
+
+	l.Info("tasks exported", zap.Int("count", count))
+}
+
+// ImportTasks godoc
+// @Summary      Импортировать задачи
+// @Description  Загружает архив задач в формате NDJSON, проверяя контрольные суммы перед сохранением
+// @Tags         admin
+// @Accept       application/x-ndjson
+// @Produce      json
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Param        on_conflict query string false "Поведение при совпадении ID: skip, overwrite, fail" example("skip")
+// @Success      200 {object} ImportResponse "Итоги импорта"
+// @Failure      400 {object} APIError "Некорректный или поврежденный архив"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      409 {object} APIError "Задача из архива уже существует"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/admin/tasks/import [post]
+func (h *Handlers) ImportTasks(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	policy, err := archive.ParseConflictPolicy(r.URL.Query().Get("on_conflict"))
+	if err != nil {
+		l.Info("invalid conflict policy", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.config.Archive.MaxImportSize)
+
+	report, err := h.anagramService.ImportTasks(r.Context(), body, policy)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, archive.ErrConflict):
+			l.Info("import conflict", zap.Error(err))
+			WriteError(w, &APIError{
+				Code:    ErrImportConflict.Code,
+				Message: ErrImportConflict.Message,
+				Details: err.Error(),
+				Status:  ErrImportConflict.Status,
+			})
+		case errors.Is(err, archive.ErrInvalidArchive), errors.Is(err, archive.ErrChecksumMismatch), errors.As(err, &maxBytesErr):
+			l.Info("invalid archive", zap.Error(err))
+			WriteError(w, &APIError{
+				Code:    ErrInvalidArchive.Code,
+				Message: ErrInvalidArchive.Message,
+				Details: err.Error(),
+				Status:  ErrInvalidArchive.Status,
+			})
+		default:
+			l.Error("failed to import tasks", zap.Error(err))
+			WriteError(w, ErrInternalServer)
+		}
+		return
+	}
+
+	response := ImportResponse{
+		Imported:    report.Imported,
+		Overwritten: report.Overwritten,
+		Skipped:     report.Skipped,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// ListDeadLetterTasks godoc
+// @Summary      Получить задачи, исчерпавшие попытки
+// @Description  Возвращает задачи в статусе dead_letter с количеством попыток и последней ошибкой
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Success      200 {object} DeadLetterListResponse "Список задач"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      503 {object} APIError "Хранилище временно недоступно"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/admin/tasks/dead-letter [get]
+func (h *Handlers) ListDeadLetterTasks(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	tasks, err := h.anagramService.ListDeadLetterTasks(r.Context())
+	if err != nil {
+		l.Error("failed to list dead-letter tasks", zap.Error(err))
+		WriteError(w, storageError(err))
+		return
+	}
+
+	response := DeadLetterListResponse{Tasks: make([]DeadLetterTaskResponse, 0, len(tasks))}
+	for _, task := range tasks {
+		response.Tasks = append(response.Tasks, DeadLetterTaskResponse{
+			TaskID:    task.ID,
+			Attempts:  task.Attempts,
+			LastError: task.LastError,
+			CreatedAt: task.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// RequeueTask godoc
+// @Summary      Повторно запустить задачу
+// @Description  Сбрасывает счетчик попыток задачи в статусе dead_letter и возвращает ее в очередь
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Param        id path string true "ID задачи" example("task-123")
+// @Success      202 {object} CreateTaskResponse "Задача возвращена в очередь"
+// @Failure      400 {object} APIError "Отсутствует ID задачи"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      404 {object} APIError "Задача не найдена"
+// @Failure      409 {object} APIError "Задача не в статусе dead_letter"
+// @Failure      503 {object} APIError "Очередь задач заполнена или хранилище недоступно"
+// @Failure      500 {object} APIError "Внутренняя ошибка сервера"
+// @Router       /api/v1/admin/tasks/{id}/requeue [post]
+func (h *Handlers) RequeueTask(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	taskID := chi.URLParam(r, "id")
+	if taskID == "" {
+		l.Info("task ID is required")
+		WriteError(w, &APIError{
+			Code:    "MISSING_TASK_ID",
+			Message: "task ID is required",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.anagramService.RequeueTask(r.Context(), taskID); err != nil {
+		if errors.Is(err, service.ErrTaskNotDeadLetter) {
+			l.Info("task is not dead-lettered", zap.String("task_id", taskID))
+			WriteError(w, ErrTaskNotDeadLetter)
+			return
+		}
+		if errors.Is(err, queue.ErrQueueFull) {
+			l.Warn("task queue is full", zap.String("task_id", taskID))
+			h.writeRetryAfter(w, ErrQueueFull)
+			return
+		}
+		l.Error("failed to requeue task", zap.String("task_id", taskID), zap.Error(err))
+		WriteError(w, storageError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(CreateTaskResponse{TaskID: taskID}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// GetWorkers godoc
+// @Summary      Получить размер пула воркеров
+// @Description  Возвращает текущее количество воркеров
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Success      200 {object} WorkersResponse "Размер пула"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      503 {object} APIError "Пул воркеров недоступен"
+// @Router       /api/v1/admin/workers [get]
+func (h *Handlers) GetWorkers(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if h.workerPool == nil {
+		WriteError(w, ErrWorkerPoolUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(WorkersResponse{Workers: h.workerPool.Size()}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// ResizeWorkers godoc
+// @Summary      Изменить размер пула воркеров
+// @Description  Запускает недостающих или останавливает лишних воркеров без перезапуска сервиса. Останавливаемые воркеры дорабатывают текущую задачу
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "Токен администратора"
+// @Param        request body ResizeWorkersRequest true "Новый размер пула"
+// @Success      200 {object} WorkersResponse "Размер пула изменен"
+// @Failure      400 {object} APIError "Размер вне допустимых границ"
+// @Failure      401 {object} APIError "Неверный токен администратора"
+// @Failure      503 {object} APIError "Пул воркеров недоступен"
+// @Router       /api/v1/admin/workers [put]
+func (h *Handlers) ResizeWorkers(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if h.workerPool == nil {
+		WriteError(w, ErrWorkerPoolUnavailable)
+		return
+	}
+
+	var request ResizeWorkersRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.Info("invalid request body")
+		WriteError(w, ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		l.Info("validation failed", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.workerPool.Resize(request.Workers); err != nil {
+		if errors.Is(err, worker.ErrInvalidPoolSize) {
+			l.Info("invalid worker pool size", zap.Int("workers", request.Workers), zap.Error(err))
+			WriteError(w, &APIError{
+				Code:    "VALIDATION_FAILED",
+				Message: "validation failed",
+				Details: err.Error(),
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+		l.Error("failed to resize worker pool", zap.Error(err))
+		WriteError(w, ErrWorkerPoolUnavailable)
+		return
+	}
+
+	l.Info("worker pool resized by admin", zap.Int("workers", request.Workers))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(WorkersResponse{Workers: h.workerPool.Size()}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// authorizeWorker проверяет токен удаленного воркера, если он задан в конфигурации
+func (h *Handlers) authorizeWorker(w http.ResponseWriter, r *http.Request) bool {
+	if h.leases == nil {
+		WriteError(w, ErrRemoteWorkersUnavailable)
+		return false
+	}
+
+	// без настроенного токена внутренние эндпоинты закрыты: они доступны на публичном адресе
+	token := h.config.Remote.Token
+	if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Worker-Token")), []byte(token)) != 1 {
+		logger.FromContext(r.Context()).Warn("remote worker token mismatch")
+		WriteError(w, ErrWorkerUnauthorized)
+		return false
+	}
+	return true
+}
+
+func leaseError(err error) *APIError {
+	if errors.Is(err, service.ErrLeaseNotFound) {
+		return ErrLeaseNotFound
+	}
+	if errors.Is(err, service.ErrLeasingStopped) || errors.Is(err, worker.ErrPoolStopped) {
+		return ErrRemoteWorkersUnavailable
+	}
+	return storageError(err)
+}
+
+// AcquireLease godoc
+// @Summary      Арендовать задачу
+// @Description  Выдает удаленному воркеру следующую задачу из очереди на время REMOTE_LEASE_TTL. Если очередь пуста, ждет задачу не дольше wait
+// @Tags         internal
+// @Accept       json
+// @Produce      json
+// @Param        X-Worker-Token header string true "Токен удаленного воркера"
+// @Param        request body AcquireLeaseRequest true "Воркер и время ожидания"
+// @Success      200 {object} LeaseResponse "Задача выдана"
+// @Success      204 "Задач нет"
+// @Failure      400 {object} APIError "Некорректный запрос"
+// @Failure      401 {object} APIError "Неверный токен воркера"
+// @Failure      503 {object} APIError "Удаленные воркеры отключены"
+// @Router       /api/v1/internal/leases [post]
+func (h *Handlers) AcquireLease(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if !h.authorizeWorker(w, r) {
+		return
+	}
+
+	var request AcquireLeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.Info("invalid request body")
+		WriteError(w, ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		l.Info("validation failed", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	wait := h.config.Remote.MaxLeaseWait
+	if request.Wait != "" {
+		d, err := time.ParseDuration(request.Wait)
+		if err != nil || d <= 0 {
+			WriteError(w, &APIError{
+				Code:    "VALIDATION_FAILED",
+				Message: "validation failed",
+				Details: "wait must be a positive duration, e.g. 5s",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+		wait = min(d, wait)
+	}
+
+	lease, err := h.leases.Acquire(r.Context(), request.WorkerID, wait)
+	if err != nil {
+		l.Error("failed to lease task", zap.String("worker_id", request.WorkerID), zap.Error(err))
+		WriteError(w, leaseError(err))
+		return
+	}
+	if lease == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(LeaseResponse{
+		LeaseID:       lease.ID,
+		TaskID:        lease.Task.ID,
+		CaseSensitive: lease.Task.CaseSensitive,
+		Attempt:       lease.Task.Attempts,
+		ExpiresAt:     lease.ExpiresAt,
+	}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// LeaseInput godoc
+// @Summary      Получить слова арендованной задачи
+// @Description  Возвращает слова задачи потоком: содержимое загруженного файла или список слов по одному в строке
+// @Tags         internal
+// @Produce      plain
+// @Param        id path string true "ID аренды"
+// @Param        X-Worker-Token header string true "Токен удаленного воркера"
+// @Success      200 {string} string "Слова задачи"
+// @Failure      401 {object} APIError "Неверный токен воркера"
+// @Failure      410 {object} APIError "Аренда истекла"
+// @Failure      503 {object} APIError "Удаленные воркеры отключены"
+// @Router       /api/v1/internal/leases/{id}/input [get]
+func (h *Handlers) LeaseInput(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if !h.authorizeWorker(w, r) {
+		return
+	}
+
+	leaseID := chi.URLParam(r, "id")
+	input, err := h.leases.OpenInput(r.Context(), leaseID)
+	if err != nil {
+		l.Warn("failed to open lease input", zap.String("lease_id", leaseID), zap.Error(err))
+		WriteError(w, leaseError(err))
+		return
+	}
+	defer input.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.Copy(w, input); err != nil {
+		l.Error("failed to stream lease input", zap.String("lease_id", leaseID), zap.Error(err))
+	}
+}
+
+// HeartbeatLease godoc
+// @Summary      Продлить аренду задачи
+// @Description  Продлевает аренду и сохраняет прогресс обработки. 410 означает, что аренда истекла или задачу отменили, и обработку нужно прекратить
+// @Tags         internal
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "ID аренды"
+// @Param        X-Worker-Token header string true "Токен удаленного воркера"
+// @Param        request body LeaseHeartbeatRequest true "Прогресс обработки"
+// @Success      200 {object} LeaseHeartbeatResponse "Аренда продлена"
+// @Failure      400 {object} APIError "Некорректный запрос"
+// @Failure      401 {object} APIError "Неверный токен воркера"
+// @Failure      410 {object} APIError "Аренда истекла"
+// @Failure      503 {object} APIError "Удаленные воркеры отключены"
+// @Router       /api/v1/internal/leases/{id}/heartbeat [post]
+func (h *Handlers) HeartbeatLease(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if !h.authorizeWorker(w, r) {
+		return
+	}
+
+	var request LeaseHeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.Info("invalid request body")
+		WriteError(w, ErrInvalidRequest)
+		return
+	}
+
+	leaseID := chi.URLParam(r, "id")
+	lease, err := h.leases.Heartbeat(r.Context(), leaseID, request.WordsProcessed, request.BatchesMerged)
+	if err != nil {
+		l.Info("failed to renew lease", zap.String("lease_id", leaseID), zap.Error(err))
+		WriteError(w, leaseError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(LeaseHeartbeatResponse{ExpiresAt: lease.ExpiresAt}); err != nil {
+		l.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// CompleteLease godoc
+// @Summary      Сдать результат арендованной задачи
+// @Description  Сохраняет результат или ошибку обработки и завершает аренду
+// @Tags         internal
+// @Accept       json
+// @Param        id path string true "ID аренды"
+// @Param        X-Worker-Token header string true "Токен удаленного воркера"
+// @Param        request body CompleteLeaseRequest true "Результат обработки"
+// @Success      204 "Результат сохранен"
+// @Failure      400 {object} APIError "Некорректный запрос"
+// @Failure      401 {object} APIError "Неверный токен воркера"
+// @Failure      410 {object} APIError "Аренда истекла"
+// @Failure      503 {object} APIError "Удаленные воркеры отключены"
+// @Router       /api/v1/internal/leases/{id}/complete [post]
+func (h *Handlers) CompleteLease(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if !h.authorizeWorker(w, r) {
+		return
+	}
+
+	var request CompleteLeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.Info("invalid request body")
+		WriteError(w, ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		l.Info("validation failed", zap.Error(err))
+		WriteError(w, &APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "validation failed",
+			Details: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	leaseID := chi.URLParam(r, "id")
+	err := h.leases.Complete(r.Context(), leaseID, domain.LeaseResult{
+		Groups:           request.Result,
+		ProcessingTimeMS: request.ProcessingTimeMS,
+		WordsProcessed:   request.WordsProcessed,
+		BatchesMerged:    request.BatchesMerged,
+		Error:            request.Error,
+		Panicked:         request.Panicked,
+		Timeout:          request.Timeout,
+		Transient:        request.Transient,
+	})
+	if err != nil {
+		l.Warn("failed to complete lease", zap.String("lease_id", leaseID), zap.Error(err))
+		WriteError(w, leaseError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,7 +53,7 @@ func TestHandlersEdgeCases(t *testing.T) {
 		for _, tc := range cases {
 			t.Run(tc.name, func(t *testing.T) {
 				mockService, _, handlers := setupTestHandlers()
-				mockService.On("CreateTask", mock.Anything, tc.words, tc.caseSensitive).Return("task123", nil)
+				mockService.On("CreateTask", mock.Anything, tc.words, domain.TaskOptions{CaseSensitive: tc.caseSensitive, ClientID: testClientID}).Return("task123", nil)
 
 				request := GroupRequest{Words: tc.words, CaseSensitive: tc.caseSensitive}
 				req := createJSONRequest("POST", "/api/v1/anagrams/group", request)
@@ -101,7 +102,7 @@ func TestHandlersEdgeCases(t *testing.T) {
 		for _, tc := range cases {
 			t.Run(tc.name, func(t *testing.T) {
 				mockService, _, handlers := setupTestHandlers()
-				mockService.On("CreateTask", mock.Anything, tc.expectedWords, tc.caseSensitive).Return("task123", nil)
+				mockService.On("CreateTask", mock.Anything, tc.expectedWords, domain.TaskOptions{CaseSensitive: tc.caseSensitive, ClientID: testClientID}).Return("task123", nil)
 
 				req := createMultipartRequest("test.txt", tc.fileContent, tc.caseSensitive)
 				rec := httptest.NewRecorder()
@@ -227,7 +228,7 @@ func TestHandlersEdgeCases(t *testing.T) {
 		for _, tc := range cases {
 			t.Run(tc.name, func(t *testing.T) {
 				mockService, _, handlers := setupTestHandlers()
-				mockService.On("CreateTask", mock.Anything, tc.words, tc.caseSensitive).Return("task123", nil)
+				mockService.On("CreateTask", mock.Anything, tc.words, domain.TaskOptions{CaseSensitive: tc.caseSensitive, ClientID: testClientID}).Return("task123", nil)
 				var req *http.Request
 				if tc.useFile {
 					req = createMultipartRequest("large.txt", tc.fileContent, tc.caseSensitive)
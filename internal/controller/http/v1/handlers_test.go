@@ -7,8 +7,15 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"github.com/grcflEgor/go-anagram-api/internal/worker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -80,7 +87,7 @@ func TestHandlers(t *testing.T) {
 	t.Run("GroupAnagrams", func(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 			mockService, _, handlers := setupTestHandlers()
-			mockService.On("CreateTask", mock.Anything, []string{"hello", "world"}, false).Return("task123", nil)
+			mockService.On("CreateTask", mock.Anything, []string{"hello", "world"}, domain.TaskOptions{ClientID: testClientID}).Return("task123", nil)
 
 			request := GroupRequest{Words: []string{"hello", "world"}, CaseSensitive: false}
 			req := createJSONRequest("POST", "/api/v1/anagrams/group", request)
@@ -98,6 +105,122 @@ func TestHandlers(t *testing.T) {
 			mockService.AssertExpectations(t)
 		})
 
+		t.Run("NoMemo", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{NoMemo: true, ClientID: testClientID}).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}, NoMemo: true})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("Priority", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{Priority: domain.PriorityLow, ClientID: testClientID}).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}, Priority: "low"})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("ClientIdentity", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			handlers.config.Worker.ClientAPIKeys = []string{"partner-secret"}
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, mock.MatchedBy(func(opts domain.TaskOptions) bool {
+				return strings.HasPrefix(opts.ClientID, "key:") && !strings.Contains(opts.ClientID, "partner-secret")
+			})).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			req.Header.Set("X-API-Key", "partner-secret")
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("UnknownAPIKeyUsesIP", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			handlers.config.Worker.ClientAPIKeys = []string{"partner-secret"}
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, mock.MatchedBy(func(opts domain.TaskOptions) bool {
+				return opts.ClientID == testClientID
+			})).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			req.Header.Set("X-API-Key", "rotated-key-42")
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("Delay", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, mock.MatchedBy(func(opts domain.TaskOptions) bool {
+				wait := time.Until(opts.RunAt)
+				return wait > 29*time.Minute && wait <= 30*time.Minute
+			})).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}, Delay: "30m"})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			var response CreateTaskResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			require.NotNil(t, response.RunAt)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("InvalidSchedule", func(t *testing.T) {
+			runAt := time.Now().Add(time.Hour)
+			cases := []struct {
+				name    string
+				request GroupRequest
+			}{
+				{"BothRunAtAndDelay", GroupRequest{Words: []string{"hello"}, RunAt: &runAt, Delay: "1h"}},
+				{"MalformedDelay", GroupRequest{Words: []string{"hello"}, Delay: "soon"}},
+				{"NegativeDelay", GroupRequest{Words: []string{"hello"}, Delay: "-5m"}},
+				{"TooFarAhead", GroupRequest{Words: []string{"hello"}, Delay: "48h"}},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					_, _, handlers := setupTestHandlers()
+					handlers.config.Schedule.MaxDelay = 24 * time.Hour
+
+					rec := httptest.NewRecorder()
+					handlers.GroupAnagrams(rec, createJSONRequest("POST", "/api/v1/anagrams/group", tc.request))
+
+					assert.Equal(t, http.StatusBadRequest, rec.Code)
+					assertErrorResponse(t, rec, "VALIDATION_FAILED")
+				})
+			}
+		})
+
+		t.Run("InvalidPriority", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}, Priority: "urgent"})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "VALIDATION_FAILED")
+		})
+
 		t.Run("InvalidJSON", func(t *testing.T) {
 			_, _, handlers := setupTestHandlers()
 
@@ -126,7 +249,7 @@ func TestHandlers(t *testing.T) {
 
 		t.Run("ServiceError", func(t *testing.T) {
 			mockService, _, handlers := setupTestHandlers()
-			mockService.On("CreateTask", mock.Anything, []string{"test"}, false).Return("", fmt.Errorf("service error"))
+			mockService.On("CreateTask", mock.Anything, []string{"test"}, domain.TaskOptions{ClientID: testClientID}).Return("", fmt.Errorf("service error"))
 
 			request := GroupRequest{Words: []string{"test"}, CaseSensitive: false}
 			req := createJSONRequest("POST", "/api/v1/anagrams/group", request)
@@ -139,6 +262,77 @@ func TestHandlers(t *testing.T) {
 
 			mockService.AssertExpectations(t)
 		})
+
+		t.Run("IdempotencyKey", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{IdempotencyKey: "retry-1", ClientID: testClientID}).Return("task123", nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			req.Header.Set("Idempotency-Key", "retry-1")
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("IdempotencyKeyReused", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{IdempotencyKey: "retry-1", ClientID: testClientID}).Return("", service.ErrIdempotencyConflict)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			req.Header.Set("Idempotency-Key", "retry-1")
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assertErrorResponse(t, rec, "IDEMPOTENCY_KEY_REUSED")
+		})
+
+		t.Run("QueueFull", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			handlers.config.Task.RetryAfter = 1500 * time.Millisecond
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{ClientID: testClientID}).Return("", queue.ErrQueueFull)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+			assertErrorResponse(t, rec, "QUEUE_FULL")
+		})
+
+		t.Run("SpoolQuotaExceeded", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			handlers.config.Task.RetryAfter = 5 * time.Second
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{ClientID: testClientID}).Return("", fmt.Errorf("%w: task needs 10 bytes", service.ErrSpoolQuotaExceeded))
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusInsufficientStorage, rec.Code)
+			assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+			assertErrorResponse(t, rec, "SPOOL_QUOTA_EXCEEDED")
+		})
+
+		t.Run("InvalidIdempotencyKey", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/group", GroupRequest{Words: []string{"hello"}})
+			req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+			rec := httptest.NewRecorder()
+
+			handlers.GroupAnagrams(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "INVALID_IDEMPOTENCY_KEY")
+		})
 	})
 
 	t.Run("UploadFile", func(t *testing.T) {
@@ -156,7 +350,7 @@ func TestHandlers(t *testing.T) {
 
 		t.Run("SingleWord", func(t *testing.T) {
 			mockService, _, handlers := setupTestHandlers()
-			mockService.On("CreateTask", mock.Anything, []string{"hello"}, false).Return("task123", nil)
+			mockService.On("CreateTask", mock.Anything, []string{"hello"}, domain.TaskOptions{ClientID: testClientID}).Return("task123", nil)
 
 			req := createMultipartRequest("test.txt", "hello", false)
 			rec := httptest.NewRecorder()
@@ -180,6 +374,99 @@ func TestHandlers(t *testing.T) {
 			assert.Equal(t, http.StatusBadRequest, rec.Code)
 			assertErrorResponse(t, rec, "MISSING_TASK_ID")
 		})
+
+		t.Run("ETag", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			task := &domain.Task{ID: "task123", Status: domain.StatusCompleted, Version: 3}
+			mockService.On("GetTaskByID", mock.Anything, "task123").Return(task, nil)
+
+			req := withURLParam(httptest.NewRequest("GET", "/api/v1/anagrams/groups/task123", nil), "id", "task123")
+			rec := httptest.NewRecorder()
+			handlers.GetResult(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+			req = withURLParam(httptest.NewRequest("GET", "/api/v1/anagrams/groups/task123", nil), "id", "task123")
+			req.Header.Set("If-None-Match", `"2", W/"3"`)
+			rec = httptest.NewRecorder()
+			handlers.GetResult(rec, req)
+
+			assert.Equal(t, http.StatusNotModified, rec.Code)
+			assert.Empty(t, rec.Body.String())
+		})
+
+		t.Run("Progress", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			started := time.Now().Add(-10 * time.Second)
+			task := &domain.Task{ID: "task123", Status: domain.StatusProcessing, Progress: &domain.TaskProgress{
+				WordsProcessed: 50000,
+				TotalWords:     200000,
+				BatchesMerged:  5,
+				StartedAt:      started,
+				UpdatedAt:      started.Add(10 * time.Second),
+			}}
+			mockService.On("GetTaskByID", mock.Anything, "task123").Return(task, nil)
+
+			req := withURLParam(httptest.NewRequest("GET", "/api/v1/anagrams/groups/task123", nil), "id", "task123")
+			rec := httptest.NewRecorder()
+			handlers.GetResult(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response struct {
+				TaskID   string           `json:"task_id"`
+				Status   string           `json:"status"`
+				Progress ProgressResponse `json:"progress"`
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, "task123", response.TaskID)
+			assert.Equal(t, 50000, response.Progress.WordsProcessed)
+			assert.Equal(t, 5, response.Progress.BatchesMerged)
+			assert.Equal(t, 25.0, response.Progress.Percent)
+			require.NotNil(t, response.Progress.ETASeconds)
+			assert.InDelta(t, 30, *response.Progress.ETASeconds, 1)
+		})
+
+		t.Run("ProgressWithoutETAWhenFinished", func(t *testing.T) {
+			task := &domain.Task{ID: "task123", Status: domain.StatusCompleted, Progress: &domain.TaskProgress{
+				WordsProcessed: 10,
+				TotalWords:     10,
+				StartedAt:      time.Now().Add(-time.Second),
+				UpdatedAt:      time.Now(),
+			}}
+
+			response := newTaskResponse(task, time.Now())
+			require.NotNil(t, response.Progress)
+			assert.Equal(t, 100.0, response.Progress.Percent)
+			assert.Nil(t, response.Progress.ETASeconds)
+		})
+
+		t.Run("StorageErrors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				err        error
+				wantStatus int
+				wantCode   string
+			}{
+				{"NotFound", fmt.Errorf("%w: task123", storage.ErrNotFound), http.StatusNotFound, "TASK_NOT_FOUND"},
+				{"Unavailable", fmt.Errorf("%w: disk full", storage.ErrUnavailable), http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE"},
+				{"Unknown", fmt.Errorf("boom"), http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					mockService, _, handlers := setupTestHandlers()
+					mockService.On("GetTaskByID", mock.Anything, "task123").Return(nil, tc.err)
+
+					req := withURLParam(httptest.NewRequest("GET", "/api/v1/anagrams/groups/task123", nil), "id", "task123")
+					rec := httptest.NewRecorder()
+					handlers.GetResult(rec, req)
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
 	})
 
 	t.Run("HealthCheck", func(t *testing.T) {
@@ -199,6 +486,22 @@ func TestHandlers(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, "ok", response.Status)
 		})
+
+		t.Run("QueueDepth", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			taskQueue := queue.NewQueues(10)
+			taskQueue.Push(&domain.Task{ID: "queued"})
+			handlers.SetTaskQueue(taskQueue)
+
+			req := httptest.NewRequest("GET", "/health", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.HealthCheck(rec, req)
+
+			var response HealthResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, 1, response.QueueDepth)
+		})
 	})
 
 	t.Run("GetStats", func(t *testing.T) {
@@ -260,6 +563,520 @@ func TestHandlers(t *testing.T) {
 		})
 	})
 
+	t.Run("GetCacheSummary", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			cachedAt := time.Now().Add(-time.Minute)
+			mockService.On("CacheSummary", mock.Anything).Return(domain.CacheSummary{
+				Entries:        2,
+				Bytes:          512,
+				MaxBytes:       1024,
+				OldestTaskID:   "task-1",
+				OldestCachedAt: cachedAt,
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/api/v1/anagrams/cache", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.GetCacheSummary(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response CacheSummaryResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, uint64(2), response.Entries)
+			assert.Equal(t, uint64(512), response.Bytes)
+			assert.Equal(t, int64(1024), response.MaxBytes)
+			require.NotNil(t, response.OldestEntry)
+			assert.Equal(t, "task-1", response.OldestEntry.TaskID)
+			assert.GreaterOrEqual(t, response.OldestEntry.AgeMS, int64(time.Minute/time.Millisecond))
+
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("EmptyCache", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CacheSummary", mock.Anything).Return(domain.CacheSummary{}, nil)
+
+			req := httptest.NewRequest("GET", "/api/v1/anagrams/cache", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.GetCacheSummary(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response CacheSummaryResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Nil(t, response.OldestEntry)
+		})
+	})
+
+	t.Run("InvalidateCacheEntry", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("InvalidateCache", mock.Anything, "task-1").Return(nil)
+
+			req := withURLParam(httptest.NewRequest("DELETE", "/api/v1/anagrams/cache/task-1", nil), "id", "task-1")
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCacheEntry(rec, req)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("NotCached", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("InvalidateCache", mock.Anything, "task-1").Return(storage.ErrNotCached)
+
+			req := withURLParam(httptest.NewRequest("DELETE", "/api/v1/anagrams/cache/task-1", nil), "id", "task-1")
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCacheEntry(rec, req)
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assertErrorResponse(t, rec, "CACHE_ENTRY_NOT_FOUND")
+		})
+
+		t.Run("MissingTaskID", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := httptest.NewRequest("DELETE", "/api/v1/anagrams/cache/", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCacheEntry(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "MISSING_TASK_ID")
+		})
+	})
+
+	t.Run("InvalidateCache", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			filter := domain.CacheFilter{Status: domain.StatusFailed, OlderThan: 10 * time.Minute}
+			mockService.On("InvalidateCacheMatching", mock.Anything, filter).Return(3, nil)
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/cache/invalidate", InvalidateCacheRequest{
+				Status:    "failed",
+				OlderThan: "10m",
+			})
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCache(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response InvalidateCacheResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, 3, response.Evicted)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("EmptyFilter", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/cache/invalidate", InvalidateCacheRequest{})
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCache(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "EMPTY_FILTER")
+		})
+
+		t.Run("InvalidStatus", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/cache/invalidate", InvalidateCacheRequest{Status: "unknown"})
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCache(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "VALIDATION_FAILED")
+		})
+
+		t.Run("InvalidOlderThan", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := createJSONRequest("POST", "/api/v1/anagrams/cache/invalidate", InvalidateCacheRequest{OlderThan: "yesterday"})
+			rec := httptest.NewRecorder()
+
+			handlers.InvalidateCache(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "VALIDATION_FAILED")
+		})
+	})
+
+	t.Run("ExportTasks", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("ExportTasks", mock.Anything, mock.Anything, []domain.TaskStatus{domain.StatusCompleted}).Return(1, nil)
+
+			req := httptest.NewRequest("GET", "/api/v1/admin/tasks/export?status=completed", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.ExportTasks(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("InvalidStatus", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := httptest.NewRequest("GET", "/api/v1/admin/tasks/export?status=processing", nil)
+			rec := httptest.NewRecorder()
+
+			handlers.ExportTasks(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "VALIDATION_FAILED")
+		})
+	})
+
+	t.Run("ImportTasks", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("ImportTasks", mock.Anything, mock.Anything, archive.ConflictOverwrite).
+				Return(archive.ImportReport{Imported: 2, Overwritten: 1}, nil)
+
+			req := httptest.NewRequest("POST", "/api/v1/admin/tasks/import?on_conflict=overwrite", strings.NewReader("{}"))
+			rec := httptest.NewRecorder()
+
+			handlers.ImportTasks(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response ImportResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, 2, response.Imported)
+			assert.Equal(t, 1, response.Overwritten)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("InvalidPolicy", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+
+			req := httptest.NewRequest("POST", "/api/v1/admin/tasks/import?on_conflict=merge", strings.NewReader("{}"))
+			rec := httptest.NewRecorder()
+
+			handlers.ImportTasks(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assertErrorResponse(t, rec, "VALIDATION_FAILED")
+		})
+
+		t.Run("ServiceErrors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				err        error
+				wantStatus int
+				wantCode   string
+			}{
+				{"Checksum", fmt.Errorf("%w: task 1", archive.ErrChecksumMismatch), http.StatusBadRequest, "INVALID_ARCHIVE"},
+				{"Conflict", fmt.Errorf("%w: 1", archive.ErrConflict), http.StatusConflict, "IMPORT_CONFLICT"},
+				{"Internal", fmt.Errorf("disk error"), http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					mockService, _, handlers := setupTestHandlers()
+					mockService.On("ImportTasks", mock.Anything, mock.Anything, archive.ConflictSkip).Return(archive.ImportReport{}, tc.err)
+
+					req := httptest.NewRequest("POST", "/api/v1/admin/tasks/import", strings.NewReader("{}"))
+					rec := httptest.NewRecorder()
+
+					handlers.ImportTasks(rec, req)
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
+	})
+
+	t.Run("CancelTask", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("CancelTask", mock.Anything, "task-1").Return(nil)
+
+			req := withURLParam(httptest.NewRequest("POST", "/api/v1/anagrams/groups/task-1/cancel", nil), "id", "task-1")
+			rec := httptest.NewRecorder()
+
+			handlers.CancelTask(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("ServiceErrors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				err        error
+				wantStatus int
+				wantCode   string
+			}{
+				{"Finished", service.ErrTaskNotCancellable, http.StatusConflict, "TASK_NOT_CANCELLABLE"},
+				{"NotFound", fmt.Errorf("%w: task-1", storage.ErrNotFound), http.StatusNotFound, "TASK_NOT_FOUND"},
+				{"Conflict", &storage.VersionConflictError{TaskID: "task-1", Expected: 1, Actual: 2}, http.StatusConflict, "TASK_VERSION_CONFLICT"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					mockService, _, handlers := setupTestHandlers()
+					mockService.On("CancelTask", mock.Anything, "task-1").Return(tc.err)
+
+					req := withURLParam(httptest.NewRequest("POST", "/api/v1/anagrams/groups/task-1/cancel", nil), "id", "task-1")
+					rec := httptest.NewRecorder()
+
+					handlers.CancelTask(rec, req)
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
+	})
+
+	t.Run("ListDeadLetterTasks", func(t *testing.T) {
+		mockService, _, handlers := setupTestHandlers()
+		mockService.On("ListDeadLetterTasks", mock.Anything).Return([]*domain.Task{
+			{ID: "task-1", Status: domain.StatusDeadLetter, Attempts: 3, LastError: "read failed"},
+		}, nil)
+
+		req := httptest.NewRequest("GET", "/api/v1/admin/tasks/dead-letter", nil)
+		rec := httptest.NewRecorder()
+
+		handlers.ListDeadLetterTasks(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response DeadLetterListResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		require.Len(t, response.Tasks, 1)
+		assert.Equal(t, "task-1", response.Tasks[0].TaskID)
+		assert.Equal(t, 3, response.Tasks[0].Attempts)
+		assert.Equal(t, "read failed", response.Tasks[0].LastError)
+	})
+
+	t.Run("RequeueTask", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			mockService, _, handlers := setupTestHandlers()
+			mockService.On("RequeueTask", mock.Anything, "task-1").Return(nil)
+
+			req := withURLParam(httptest.NewRequest("POST", "/api/v1/admin/tasks/task-1/requeue", nil), "id", "task-1")
+			rec := httptest.NewRecorder()
+
+			handlers.RequeueTask(rec, req)
+
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+
+		t.Run("ServiceErrors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				err        error
+				wantStatus int
+				wantCode   string
+			}{
+				{"NotDeadLetter", service.ErrTaskNotDeadLetter, http.StatusConflict, "TASK_NOT_DEAD_LETTER"},
+				{"NotFound", fmt.Errorf("%w: task-1", storage.ErrNotFound), http.StatusNotFound, "TASK_NOT_FOUND"},
+				{"Unavailable", fmt.Errorf("%w: journal closed", storage.ErrUnavailable), http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					mockService, _, handlers := setupTestHandlers()
+					mockService.On("RequeueTask", mock.Anything, "task-1").Return(tc.err)
+
+					req := withURLParam(httptest.NewRequest("POST", "/api/v1/admin/tasks/task-1/requeue", nil), "id", "task-1")
+					rec := httptest.NewRecorder()
+
+					handlers.RequeueTask(rec, req)
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
+	})
+
+	t.Run("Workers", func(t *testing.T) {
+		t.Run("Get", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			handlers.SetWorkerPool(&fakeWorkerPool{size: 8})
+
+			rec := httptest.NewRecorder()
+			handlers.GetWorkers(rec, httptest.NewRequest("GET", "/api/v1/admin/workers", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var response WorkersResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, 8, response.Workers)
+		})
+
+		t.Run("Resize", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			pool := &fakeWorkerPool{size: 8}
+			handlers.SetWorkerPool(pool)
+
+			rec := httptest.NewRecorder()
+			handlers.ResizeWorkers(rec, createJSONRequest("PUT", "/api/v1/admin/workers", ResizeWorkersRequest{Workers: 16}))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, 16, pool.size)
+			var response WorkersResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, 16, response.Workers)
+		})
+
+		t.Run("ResizeErrors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				pool       *fakeWorkerPool
+				body       string
+				wantStatus int
+				wantCode   string
+			}{
+				{"ZeroWorkers", &fakeWorkerPool{size: 8}, `{"workers": 0}`, http.StatusBadRequest, "VALIDATION_FAILED"},
+				{"OutOfLimits", &fakeWorkerPool{size: 8, err: fmt.Errorf("%w: 500 not in [1, 200]", worker.ErrInvalidPoolSize)}, `{"workers": 500}`, http.StatusBadRequest, "VALIDATION_FAILED"},
+				{"PoolStopped", &fakeWorkerPool{size: 8, err: worker.ErrPoolStopped}, `{"workers": 4}`, http.StatusServiceUnavailable, "WORKER_POOL_UNAVAILABLE"},
+				{"InvalidJSON", &fakeWorkerPool{size: 8}, `{"workers":`, http.StatusBadRequest, "INVALID_REQUEST"},
+				{"NoPool", nil, `{"workers": 4}`, http.StatusServiceUnavailable, "WORKER_POOL_UNAVAILABLE"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					_, _, handlers := setupTestHandlers()
+					if tc.pool != nil {
+						handlers.SetWorkerPool(tc.pool)
+					}
+
+					rec := httptest.NewRecorder()
+					handlers.ResizeWorkers(rec, httptest.NewRequest("PUT", "/api/v1/admin/workers", strings.NewReader(tc.body)))
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
+	})
+
+	t.Run("Leases", func(t *testing.T) {
+		lease := &domain.Lease{
+			ID:        "lease-1",
+			WorkerID:  "worker-1",
+			Task:      &domain.Task{ID: "task-1", Words: []string{"кот", "ток"}, Attempts: 1},
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+
+		t.Run("Acquire", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			handlers.config.Remote.MaxLeaseWait = 5 * time.Second
+			leaser := &fakeTaskLeaser{lease: lease}
+			handlers.SetTaskLeaser(leaser)
+
+			rec := httptest.NewRecorder()
+			handlers.AcquireLease(rec, workerRequest(createJSONRequest("POST", "/api/v1/internal/leases", AcquireLeaseRequest{WorkerID: "worker-1", Wait: "1m"})))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, 5*time.Second, leaser.wait)
+			var response LeaseResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, "lease-1", response.LeaseID)
+			assert.Equal(t, "task-1", response.TaskID)
+			assert.Equal(t, 1, response.Attempt)
+		})
+
+		t.Run("AcquireNoTask", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			handlers.SetTaskLeaser(&fakeTaskLeaser{})
+
+			rec := httptest.NewRecorder()
+			handlers.AcquireLease(rec, workerRequest(createJSONRequest("POST", "/api/v1/internal/leases", AcquireLeaseRequest{WorkerID: "worker-1"})))
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+
+		t.Run("Input", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			handlers.SetTaskLeaser(&fakeTaskLeaser{lease: lease})
+
+			rec := httptest.NewRecorder()
+			handlers.LeaseInput(rec, withURLParam(workerRequest(httptest.NewRequest("GET", "/api/v1/internal/leases/lease-1/input", nil)), "id", "lease-1"))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "кот\nток", rec.Body.String())
+		})
+
+		t.Run("Complete", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			leaser := &fakeTaskLeaser{lease: lease}
+			handlers.SetTaskLeaser(leaser)
+
+			req := createJSONRequest("POST", "/api/v1/internal/leases/lease-1/complete", CompleteLeaseRequest{Result: [][]string{{"кот", "ток"}}, WordsProcessed: 2})
+			rec := httptest.NewRecorder()
+			handlers.CompleteLease(rec, withURLParam(workerRequest(req), "id", "lease-1"))
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, [][]string{{"кот", "ток"}}, leaser.completed.Groups)
+			assert.Equal(t, 2, leaser.completed.WordsProcessed)
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			cases := []struct {
+				name       string
+				leaser     *fakeTaskLeaser
+				token      string
+				header     string
+				body       string
+				wantStatus int
+				wantCode   string
+			}{
+				{"Disabled", nil, "secret", "secret", `{"worker_id": "worker-1"}`, http.StatusServiceUnavailable, "REMOTE_WORKERS_UNAVAILABLE"},
+				{"Stopped", &fakeTaskLeaser{err: service.ErrLeasingStopped}, "secret", "secret", `{"worker_id": "worker-1"}`, http.StatusServiceUnavailable, "REMOTE_WORKERS_UNAVAILABLE"},
+				{"MissingToken", &fakeTaskLeaser{lease: lease}, "secret", "", `{"worker_id": "worker-1"}`, http.StatusUnauthorized, "WORKER_UNAUTHORIZED"},
+				{"WrongToken", &fakeTaskLeaser{lease: lease}, "secret", "guess", `{"worker_id": "worker-1"}`, http.StatusUnauthorized, "WORKER_UNAUTHORIZED"},
+				{"TokenNotConfigured", &fakeTaskLeaser{lease: lease}, "", "", `{"worker_id": "worker-1"}`, http.StatusUnauthorized, "WORKER_UNAUTHORIZED"},
+				{"MissingWorkerID", &fakeTaskLeaser{lease: lease}, "secret", "secret", `{}`, http.StatusBadRequest, "VALIDATION_FAILED"},
+				{"InvalidWait", &fakeTaskLeaser{lease: lease}, "secret", "secret", `{"worker_id": "worker-1", "wait": "soon"}`, http.StatusBadRequest, "VALIDATION_FAILED"},
+				{"InvalidJSON", &fakeTaskLeaser{lease: lease}, "secret", "secret", `{"worker_id":`, http.StatusBadRequest, "INVALID_REQUEST"},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					_, _, handlers := setupTestHandlers()
+					handlers.config.Remote.Token = tc.token
+					if tc.leaser != nil {
+						handlers.SetTaskLeaser(tc.leaser)
+					}
+
+					req := httptest.NewRequest("POST", "/api/v1/internal/leases", strings.NewReader(tc.body))
+					if tc.header != "" {
+						req.Header.Set("X-Worker-Token", tc.header)
+					}
+					rec := httptest.NewRecorder()
+					handlers.AcquireLease(rec, req)
+
+					assert.Equal(t, tc.wantStatus, rec.Code)
+					assertErrorResponse(t, rec, tc.wantCode)
+				})
+			}
+		})
+
+		t.Run("HeartbeatExpired", func(t *testing.T) {
+			_, _, handlers := setupTestHandlers()
+			handlers.SetTaskLeaser(&fakeTaskLeaser{err: service.ErrLeaseNotFound})
+
+			req := createJSONRequest("POST", "/api/v1/internal/leases/lease-1/heartbeat", LeaseHeartbeatRequest{WordsProcessed: 1})
+			rec := httptest.NewRecorder()
+			handlers.HeartbeatLease(rec, withURLParam(workerRequest(req), "id", "lease-1"))
+
+			assert.Equal(t, http.StatusGone, rec.Code)
+			assertErrorResponse(t, rec, "LEASE_NOT_FOUND")
+		})
+	})
+
 	t.Run("Validation", func(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 			validator := validator.New()
@@ -329,3 +1146,36 @@ func TestHandlers(t *testing.T) {
 		})
 	})
 }
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name       string
+		token      string
+		header     string
+		wantStatus int
+	}{
+		{"ValidToken", "admin-secret", "admin-secret", http.StatusNoContent},
+		{"MissingToken", "admin-secret", "", http.StatusUnauthorized},
+		{"WrongToken", "admin-secret", "guess", http.StatusUnauthorized},
+		{"TokenNotConfigured", "", "", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/admin/workers", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Admin-Token", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			AdminAuthMiddleware(tc.token)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			if tc.wantStatus == http.StatusUnauthorized {
+				assertErrorResponse(t, rec, "ADMIN_UNAUTHORIZED")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"time"
 
@@ -65,3 +66,18 @@ func OTelMiddleware(next http.Handler) http.Handler {
 		}
 	})
 }
+
+// AdminAuthMiddleware пропускает только запросы с токеном администратора
+// в заголовке X-Admin-Token. Без настроенного токена запросы отклоняются.
+func AdminAuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(token)) != 1 {
+				logger.FromContext(r.Context()).Warn("admin token mismatch")
+				WriteError(w, ErrAdminUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package v1
 
+import "time"
+
 // GroupRequest представляет запрос на группировку анаграмм
 type GroupRequest struct {
 	// Список слов для группировки
 	Words []string `json:"words" validate:"min=1,dive,required" example:"[\"cat\",\"act\",\"tac\"]"`
 	// Учитывать ли регистр при группировке
 	CaseSensitive bool `json:"case_sensitive" example:"false"`
+	// Не переиспользовать готовый результат для тех же слов
+	NoMemo bool `json:"no_memo" example:"false"`
+	// Приоритет задачи; по умолчанию определяется по количеству слов
+	Priority string `json:"priority,omitempty" validate:"omitempty,oneof=high normal low" example:"high"`
+	// Время запуска задачи в формате RFC 3339; не используется вместе с delay
+	RunAt *time.Time `json:"run_at,omitempty" example:"2024-01-01T03:00:00Z"`
+	// Задержка запуска задачи, например "30m" или "2h"; не используется вместе с run_at
+	Delay string `json:"delay,omitempty" example:"30m"`
 }
 
 // UploadRequest представляет запрос на загрузку файла
@@ -17,3 +27,55 @@ type UploadRequest struct {
 	// Учитывать ли регистр при группировке
 	CaseSensitive bool `json:"case_sensitive" example:"false"`
 }
+
+// InvalidateCacheRequest представляет фильтр массовой инвалидации кэша
+type InvalidateCacheRequest struct {
+	// Идентификаторы задач для удаления из кэша
+	TaskIDs []string `json:"task_ids" validate:"omitempty,dive,required" example:"[\"task-123\"]"`
+	// Удалить только задачи с указанным статусом
+	Status string `json:"status" validate:"omitempty,oneof=scheduled processing completed failed dead_letter cancelled" example:"failed"`
+	// Удалить только записи старше указанной длительности
+	OlderThan string `json:"older_than" example:"10m"`
+}
+
+// AcquireLeaseRequest представляет запрос удаленного воркера на аренду задачи
+type AcquireLeaseRequest struct {
+	// Идентификатор воркера
+	WorkerID string `json:"worker_id" validate:"required,max=255" example:"worker-1"`
+	// Сколько ждать задачу, если очередь пуста; не больше REMOTE_LEASE_MAX_WAIT
+	Wait string `json:"wait,omitempty" example:"10s"`
+}
+
+// LeaseHeartbeatRequest представляет продление аренды с прогрессом обработки
+type LeaseHeartbeatRequest struct {
+	// Количество обработанных слов
+	WordsProcessed int `json:"words_processed" example:"50000"`
+	// Количество объединенных батчей
+	BatchesMerged int `json:"batches_merged" example:"5"`
+}
+
+// CompleteLeaseRequest представляет результат обработки арендованной задачи
+type CompleteLeaseRequest struct {
+	// Группы анаграмм из двух и более слов
+	Result [][]string `json:"result"`
+	// Время обработки на воркере
+	ProcessingTimeMS int64 `json:"processing_time_ms" validate:"min=0" example:"150"`
+	// Количество обработанных слов
+	WordsProcessed int `json:"words_processed" validate:"min=0" example:"200000"`
+	// Количество объединенных батчей
+	BatchesMerged int `json:"batches_merged" validate:"min=0" example:"20"`
+	// Ошибка обработки; пустая строка - задача выполнена
+	Error string `json:"error,omitempty" validate:"max=4096" example:""`
+	// Обработка завершилась паникой
+	Panicked bool `json:"panicked,omitempty" example:"false"`
+	// Обработка не уложилась в таймаут воркера
+	Timeout bool `json:"timeout,omitempty" example:"false"`
+	// Воркер счел ошибку временной; такая задача повторяется по политике повторов сервиса
+	Transient bool `json:"transient,omitempty" example:"false"`
+}
+
+// ResizeWorkersRequest представляет запрос на изменение размера пула воркеров
+type ResizeWorkersRequest struct {
+	// Новое количество воркеров
+	Workers int `json:"workers" validate:"required,min=1" example:"16"`
+}
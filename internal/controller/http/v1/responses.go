@@ -1,5 +1,11 @@
 package v1
 
+import (
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
 // GroupResponse представляет ответ с результатом группировки анаграмм
 type GroupResponse struct {
 	// Уникальный идентификатор задачи
@@ -14,16 +20,41 @@ type GroupResponse struct {
 	GroupsCount int `json:"groups_count" example:"2"`
 }
 
+// TaskResponse представляет задачу с ходом ее обработки
+type TaskResponse struct {
+	*domain.Task
+	// Ход обработки задачи
+	Progress *ProgressResponse `json:"progress,omitempty"`
+}
+
+// ProgressResponse описывает ход обработки задачи
+type ProgressResponse struct {
+	// Количество обработанных слов
+	WordsProcessed int `json:"words_processed" example:"50000"`
+	// Общее количество слов, 0 если неизвестно
+	TotalWords int `json:"total_words" example:"200000"`
+	// Количество объединенных батчей
+	BatchesMerged int `json:"batches_merged" example:"5"`
+	// Доля обработанных слов в процентах
+	Percent float64 `json:"percent" example:"25"`
+	// Оценка оставшегося времени обработки в секундах
+	ETASeconds *float64 `json:"eta_seconds,omitempty" example:"12.5"`
+}
+
 // CreateTaskResponse представляет ответ при создании задачи
 type CreateTaskResponse struct {
 	// Уникальный идентификатор созданной задачи
 	TaskID string `json:"task_id" example:"task-123"`
+	// Время запуска отложенной задачи
+	RunAt *time.Time `json:"run_at,omitempty" example:"2024-01-01T03:00:00Z"`
 }
 
 // HealthResponse представляет ответ проверки здоровья сервиса
 type HealthResponse struct {
 	// Статус сервиса
 	Status string `json:"status" example:"ok"`
+	// Количество задач, ожидающих в очереди
+	QueueDepth int `json:"queue_depth" example:"3"`
 }
 
 // StatsResponse представляет статистику по задачам
@@ -34,4 +65,108 @@ type StatsResponse struct {
 	CompletedTasks int64 `json:"completed_tasks" example:"85"`
 	// Количество неудачных задач
 	FailedTasks int64 `json:"failed_tasks" example:"5"`
+	// Количество повторных попыток обработки
+	RetriedTasks int64 `json:"retried_tasks" example:"7"`
+	// Количество задач, исчерпавших попытки
+	DeadLetterTasks int64 `json:"dead_letter_tasks" example:"1"`
+	// Количество отмененных задач
+	CancelledTasks int64 `json:"cancelled_tasks" example:"2"`
+	// Количество задач, обработка которых завершилась паникой
+	PanickedTasks int64 `json:"panicked_tasks" example:"0"`
+	// Количество запросов, обслуженных готовым результатом
+	MemoizedTasks int64 `json:"memoized_tasks" example:"12"`
+	// Количество попаданий в кэш
+	CacheHits int64 `json:"cache_hits" example:"120"`
+	// Количество промахов кэша
+	CacheMisses int64 `json:"cache_misses" example:"30"`
+	// Количество вытесненных из кэша записей
+	CacheEvictions int64 `json:"cache_evictions" example:"4"`
+	// Приблизительный объем кэша в байтах
+	CacheBytes int64 `json:"cache_bytes" example:"1048576"`
+	// Количество записей в кэше
+	CacheEntries int64 `json:"cache_entries" example:"42"`
+	// Количество задач, ожидающих в очереди
+	QueueDepth int64 `json:"queue_depth" example:"3"`
+}
+
+// CacheEntryResponse описывает запись в кэше
+type CacheEntryResponse struct {
+	// Идентификатор задачи
+	TaskID string `json:"task_id" example:"task-123"`
+	// Время помещения в кэш
+	CachedAt time.Time `json:"cached_at" example:"2025-01-01T12:00:00Z"`
+	// Возраст записи в миллисекундах
+	AgeMS int64 `json:"age_ms" example:"60000"`
+}
+
+// CacheSummaryResponse представляет сводку о содержимом кэша
+type CacheSummaryResponse struct {
+	// Количество записей в кэше
+	Entries uint64 `json:"entries" example:"42"`
+	// Приблизительный объем кэша в байтах
+	Bytes uint64 `json:"bytes" example:"1048576"`
+	// Лимит объема кэша в байтах
+	MaxBytes int64 `json:"max_bytes" example:"268435456"`
+	// Самая старая запись в кэше
+	OldestEntry *CacheEntryResponse `json:"oldest_entry,omitempty"`
+}
+
+// InvalidateCacheResponse представляет результат массовой инвалидации кэша
+type InvalidateCacheResponse struct {
+	// Количество удаленных записей
+	Evicted int `json:"evicted" example:"3"`
+}
+
+// ImportResponse представляет итоги импорта архива задач
+type ImportResponse struct {
+	// Количество новых задач
+	Imported int `json:"imported" example:"10"`
+	// Количество перезаписанных задач
+	Overwritten int `json:"overwritten" example:"2"`
+	// Количество пропущенных задач
+	Skipped int `json:"skipped" example:"1"`
+}
+
+// DeadLetterTaskResponse описывает задачу, исчерпавшую попытки обработки
+type DeadLetterTaskResponse struct {
+	// Идентификатор задачи
+	TaskID string `json:"task_id" example:"task-123"`
+	// Количество выполненных попыток
+	Attempts int `json:"attempts" example:"3"`
+	// Ошибка последней попытки
+	LastError string `json:"last_error" example:"open /tmp/upload-1.txt: too many open files"`
+	// Время создания задачи
+	CreatedAt time.Time `json:"created_at" example:"2025-01-01T12:00:00Z"`
+}
+
+// DeadLetterListResponse представляет список задач, исчерпавших попытки
+type DeadLetterListResponse struct {
+	// Задачи в статусе dead_letter
+	Tasks []DeadLetterTaskResponse `json:"tasks"`
+}
+
+// LeaseResponse представляет задачу, выданную удаленному воркеру
+type LeaseResponse struct {
+	// Идентификатор аренды
+	LeaseID string `json:"lease_id" example:"3f1c9a52-7d3e-4b8a-9c61-2f0e5d4b7a10"`
+	// Идентификатор задачи
+	TaskID string `json:"task_id" example:"task-123"`
+	// Учитывать ли регистр при группировке
+	CaseSensitive bool `json:"case_sensitive" example:"false"`
+	// Номер попытки обработки
+	Attempt int `json:"attempt" example:"1"`
+	// Время, до которого нужно продлить аренду или сдать результат
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T12:00:30Z"`
+}
+
+// LeaseHeartbeatResponse представляет продленную аренду
+type LeaseHeartbeatResponse struct {
+	// Новое время окончания аренды
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T12:01:00Z"`
+}
+
+// WorkersResponse представляет текущий размер пула воркеров
+type WorkersResponse struct {
+	// Количество запущенных воркеров
+	Workers int `json:"workers" example:"16"`
 }
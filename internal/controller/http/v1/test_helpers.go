@@ -2,30 +2,50 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/grcflEgor/go-anagram-api/internal/config"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testClientID - идентификатор клиента для запросов httptest.NewRequest без X-API-Key
+const testClientID = "ip:192.0.2.1"
+
 func setupTestHandlers() (*mocks.MockAnagramService, *mocks.MockTaskStats, *Handlers) {
 	mockService := &mocks.MockAnagramService{}
 	validator := validator.New()
 	config := &config.Config{}
 	config.Upload.MaxFileSize = 100 * 1024 * 1024
+	config.Archive.MaxImportSize = 100 * 1024 * 1024
+	config.Remote.Token = testWorkerToken
 	stats := &mocks.MockTaskStats{}
 	handlers := NewHandlers(mockService, validator, config, stats)
 	return mockService, stats, handlers
 }
 
+// testWorkerToken - токен удаленных воркеров в обработчиках из setupTestHandlers
+const testWorkerToken = "worker-secret"
+
+// workerRequest добавляет к запросу токен удаленного воркера
+func workerRequest(req *http.Request) *http.Request {
+	req.Header.Set("X-Worker-Token", testWorkerToken)
+	return req
+}
+
 func createJSONRequest(method, url string, body interface{}) *http.Request {
 	var bodyReader *bytes.Reader
 	if body != nil {
@@ -78,3 +98,57 @@ func assertErrorResponse(t *testing.T, rec *httptest.ResponseRecorder, expectedC
 	require.NoError(t, err)
 	assert.Equal(t, expectedCode, errorResp.Code)
 }
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+type fakeWorkerPool struct {
+	size int
+	err  error
+}
+
+func (f *fakeWorkerPool) Size() int {
+	return f.size
+}
+
+func (f *fakeWorkerPool) Resize(size int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.size = size
+	return nil
+}
+
+type fakeTaskLeaser struct {
+	lease     *domain.Lease
+	err       error
+	wait      time.Duration
+	completed domain.LeaseResult
+}
+
+func (f *fakeTaskLeaser) Acquire(ctx context.Context, workerID string, wait time.Duration) (*domain.Lease, error) {
+	f.wait = wait
+	return f.lease, f.err
+}
+
+func (f *fakeTaskLeaser) Heartbeat(ctx context.Context, leaseID string, wordsProcessed, batchesMerged int) (*domain.Lease, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.lease, nil
+}
+
+func (f *fakeTaskLeaser) OpenInput(ctx context.Context, leaseID string) (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return io.NopCloser(strings.NewReader(strings.Join(f.lease.Task.Words, "\n"))), nil
+}
+
+func (f *fakeTaskLeaser) Complete(ctx context.Context, leaseID string, result domain.LeaseResult) error {
+	f.completed = result
+	return f.err
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// CacheFilter задает условия массовой инвалидации кэша.
+// Пустые поля не участвуют в отборе, заполненные объединяются по И.
+type CacheFilter struct {
+	// Идентификаторы задач
+	TaskIDs []string
+	// Статус задачи
+	Status TaskStatus
+	// Минимальный возраст записи в кэше
+	OlderThan time.Duration
+}
+
+func (f CacheFilter) IsEmpty() bool {
+	return len(f.TaskIDs) == 0 && f.Status == "" && f.OlderThan <= 0
+}
+
+// CacheSummary содержит сводку о содержимом кэша
+type CacheSummary struct {
+	// Количество записей
+	Entries uint64
+	// Приблизительный объем в байтах
+	Bytes uint64
+	// Лимит объема в байтах (0 - без лимита)
+	MaxBytes int64
+	// Идентификатор самой старой записи
+	OldestTaskID string
+	// Время помещения самой старой записи в кэш
+	OldestCachedAt time.Time
+}
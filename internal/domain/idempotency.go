@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord связывает ключ идемпотентности с созданной задачей
+type IdempotencyRecord struct {
+	// Ключ из заголовка Idempotency-Key
+	Key string `json:"key"`
+	// Идентификатор задачи, созданной по первому запросу
+	TaskID string `json:"task_id,omitempty"`
+	// Хэш тела первого запроса
+	RequestHash string `json:"request_hash,omitempty"`
+	// Время, после которого ключ можно использовать повторно
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+func (r IdempotencyRecord) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
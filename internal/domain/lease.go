@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Lease - аренда задачи удаленным воркером. Пока аренда не истекла, задача
+// принадлежит воркеру; истекшая аренда возвращает задачу в очередь.
+type Lease struct {
+	// Идентификатор аренды
+	ID string
+	// Идентификатор удаленного воркера
+	WorkerID string
+	// Арендованная задача
+	Task *Task
+	// Время, до которого воркер должен продлить аренду или сдать результат
+	ExpiresAt time.Time
+}
+
+// LeaseResult - итог обработки арендованной задачи
+type LeaseResult struct {
+	// Группы анаграмм из двух и более слов
+	Groups [][]string
+	// Время обработки на воркере
+	ProcessingTimeMS int64
+	// Количество обработанных слов
+	WordsProcessed int
+	// Количество объединенных батчей
+	BatchesMerged int
+	// Ошибка обработки; пустая строка - задача выполнена
+	Error string
+	// Обработка завершилась паникой
+	Panicked bool
+	// Обработка не уложилась в таймаут воркера
+	Timeout bool
+	// Воркер счел ошибку временной, например ошибкой ввода-вывода
+	Transient bool
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "fmt"
+
+// TaskPriority определяет очередь, из которой воркеры берут задачу
+type TaskPriority string
+
+const (
+	PriorityHigh   TaskPriority = "high"   // Небольшие интерактивные запросы
+	PriorityNormal TaskPriority = "normal" // Приоритет по умолчанию
+	PriorityLow    TaskPriority = "low"    // Крупные фоновые задачи
+)
+
+// Priorities перечисляет приоритеты от высшего к низшему
+var Priorities = []TaskPriority{PriorityHigh, PriorityNormal, PriorityLow}
+
+func ParsePriority(value string) (TaskPriority, error) {
+	switch priority := TaskPriority(value); priority {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("unknown priority %q", value)
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// TaskProgress описывает ход обработки задачи
+type TaskProgress struct {
+	// Количество обработанных слов
+	WordsProcessed int `json:"words_processed"`
+	// Общее количество слов в задаче, 0 если неизвестно
+	TotalWords int `json:"total_words"`
+	// Количество объединенных батчей
+	BatchesMerged int `json:"batches_merged"`
+	// Время начала текущей попытки обработки
+	StartedAt time.Time `json:"started_at"`
+	// Время последнего обновления прогресса
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Percent возвращает долю обработанных слов в процентах
+func (p *TaskProgress) Percent() float64 {
+	if p.TotalWords <= 0 {
+		return 0
+	}
+	percent := float64(p.WordsProcessed) * 100 / float64(p.TotalWords)
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// ETA оценивает оставшееся время обработки по средней скорости с начала попытки.
+// Возвращает false, если скорость еще нельзя оценить.
+func (p *TaskProgress) ETA(now time.Time) (time.Duration, bool) {
+	if p.TotalWords <= 0 || p.WordsProcessed <= 0 || p.StartedAt.IsZero() {
+		return 0, false
+	}
+
+	elapsed := p.UpdatedAt.Sub(p.StartedAt)
+	remaining := p.TotalWords - p.WordsProcessed
+	if remaining <= 0 {
+		return 0, true
+	}
+
+	eta := time.Duration(float64(elapsed)*float64(remaining)/float64(p.WordsProcessed)) - now.Sub(p.UpdatedAt)
+	if eta < 0 {
+		eta = 0
+	}
+	return eta, true
+}
@@ -0,0 +1,13 @@
+package domain
+
+// ResultRef ссылается на результат задачи, вынесенный во внешнее хранилище результатов
+type ResultRef struct {
+	// Ключ результата в хранилище
+	Key string `json:"key"`
+	// Количество сжатых чанков
+	Chunks int `json:"chunks"`
+	// Количество групп в результате
+	Groups int `json:"groups"`
+	// Размер сжатых данных в байтах
+	CompressedBytes int64 `json:"compressed_bytes"`
+}
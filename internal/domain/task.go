@@ -6,9 +6,12 @@ import "time"
 type TaskStatus string
 
 const (
-	StatusProcessing TaskStatus = "processing" // Задача в обработке
-	StatusCompleted  TaskStatus = "completed"  // Задача завершена успешно
-	StatusFailed     TaskStatus = "failed"     // Задача завершена с ошибкой
+	StatusScheduled  TaskStatus = "scheduled"   // Задача ожидает времени запуска
+	StatusProcessing TaskStatus = "processing"  // Задача в обработке
+	StatusCompleted  TaskStatus = "completed"   // Задача завершена успешно
+	StatusFailed     TaskStatus = "failed"      // Задача завершена с ошибкой
+	StatusDeadLetter TaskStatus = "dead_letter" // Исчерпаны попытки повторной обработки
+	StatusCancelled  TaskStatus = "cancelled"   // Задача отменена клиентом
 )
 
 // Task представляет задачу по группировке анаграмм
@@ -23,12 +26,30 @@ type Task struct {
 	FilePath string `json:"-"`
 	// Учитывать ли регистр (скрыто из JSON)
 	CaseSensitive bool `json:"-"`
+	// Приоритет обработки задачи
+	Priority TaskPriority `json:"priority,omitempty" example:"normal"`
+	// Идентификатор клиента, создавшего задачу, для равномерного распределения воркеров (скрыто из JSON)
+	ClientID string `json:"-"`
+	// Хэш входных слов и параметров группировки (скрыто из JSON)
+	Fingerprint string `json:"-"`
 	// Результат группировки анаграмм
 	Result [][]string `json:"result,omitempty"`
+	// Ссылка на результат во внешнем хранилище, если он не хранится в задаче (скрыто из JSON)
+	ResultRef *ResultRef `json:"-"`
 	// Описание ошибки, если задача завершилась неудачно
 	Error string `json:"error,omitempty" example:"timeout exceeded"`
+	// Количество попыток обработки
+	Attempts int `json:"attempts,omitempty" example:"1"`
+	// Ошибка последней неудачной попытки
+	LastError string `json:"last_error,omitempty" example:"read input: input/output error"`
+	// Ход обработки задачи (скрыто из JSON, отдается в ответе GetResult вместе с оценкой времени)
+	Progress *TaskProgress `json:"-"`
+	// Время, не раньше которого задача будет поставлена в очередь
+	RunAt *time.Time `json:"run_at,omitempty" example:"2024-01-01T03:00:00Z"`
 	// Время создания задачи (скрыто из JSON)
 	CreatedAt time.Time `json:"-"`
+	// Время последней постановки в очередь (скрыто из JSON)
+	EnqueuedAt time.Time `json:"-"`
 	// Время обработки в миллисекундах
 	ProcessingTimeMS int64 `json:"processing_time_ms" example:"150"`
 	// Количество групп анаграмм
@@ -36,4 +57,39 @@ type Task struct {
 
 	// Контекст трассировки (скрыто из JSON)
 	TraceContext map[string]string `json:"-"`
+
+	// Версия задачи для оптимистичной блокировки (скрыто из JSON).
+	// 0 означает, что задача еще не сохранялась
+	Version uint64 `json:"-"`
+}
+
+// Clone возвращает поверхностную копию задачи, чтобы изменения полей
+// копии не затрагивали задачу, хранящуюся в хранилище.
+// Прогресс копируется отдельно, так как воркер обновляет его на месте.
+func (t *Task) Clone() *Task {
+	clone := *t
+	if t.Progress != nil {
+		progress := *t.Progress
+		clone.Progress = &progress
+	}
+	return &clone
+}
+
+// TaskOptions задает параметры создания задачи
+type TaskOptions struct {
+	// Учитывать ли регистр при группировке
+	CaseSensitive bool
+	// Не переиспользовать готовый результат для тех же входных данных
+	NoMemo bool
+	// Ключ идемпотентности запроса на создание
+	IdempotencyKey string
+	// Приоритет задачи; если не задан, определяется по размеру входных данных
+	Priority TaskPriority
+	// Идентификатор клиента: хэш API-ключа или IP-адрес
+	ClientID string
+	// Время запуска отложенной задачи; нулевое или прошедшее - запустить сразу
+	RunAt time.Time
+	// Задержка запуска из запроса, по которой вычислено RunAt. Повтор запроса
+	// с тем же delay дает другое RunAt, поэтому в хэш запроса входит задержка
+	Delay time.Duration
 }
@@ -0,0 +1,322 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const (
+	DoneDir   = "done"
+	FailedDir = "failed"
+
+	// ClientID - идентификатор клиента для задач, созданных из каталога
+	ClientID = "ingest"
+)
+
+var (
+	ErrEmptyFile    = errors.New("no words found in file")
+	ErrFileTooLarge = errors.New("file is too large")
+)
+
+// TaskCreator создает задачи и отдает их результат; реализуется AnagramService
+type TaskCreator interface {
+	CreateTask(ctx context.Context, words []string, opts domain.TaskOptions) (string, error)
+	GetTaskByID(ctx context.Context, id string) (*domain.Task, error)
+}
+
+// Options задает отслеживаемый каталог и правила отбора файлов
+type Options struct {
+	// Каталог, в который поступают файлы со словами
+	Dir string
+	// Шаблон имени файла в формате filepath.Match
+	Pattern string
+	// Как часто просматривать каталог
+	PollInterval time.Duration
+	// Учитывать ли регистр при группировке
+	CaseSensitive bool
+	// Файлы больше лимита переносятся в failed без обработки; 0 - без лимита
+	MaxFileSize int64
+}
+
+// fileState - размер и время изменения файла при последнем просмотре
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher создает задачи для новых файлов в каталоге. Файл берется в работу,
+// когда его размер и время изменения не менялись между двумя просмотрами,
+// чтобы не читать файл, который еще дописывается. После завершения задачи
+// файл переносится в done или failed, а рядом записывается результат.
+type Watcher struct {
+	service TaskCreator
+	opts    Options
+	logger  *zap.Logger
+
+	// seen - файлы, замеченные при прошлом просмотре; inFlight - задачи по файлам
+	seen     map[string]fileState
+	inFlight map[string]string
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewWatcher(service TaskCreator, opts Options, logger *zap.Logger) (*Watcher, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = "*"
+	}
+	if _, err := filepath.Match(opts.Pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid ingest pattern %q: %w", opts.Pattern, err)
+	}
+	for _, dir := range []string{opts.Dir, filepath.Join(opts.Dir, DoneDir), filepath.Join(opts.Dir, FailedDir)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create ingest dir: %w", err)
+		}
+	}
+
+	return &Watcher{
+		service:  service,
+		opts:     opts,
+		logger:   logger.With(zap.String("ingest_dir", opts.Dir)),
+		seen:     make(map[string]fileState),
+		inFlight: make(map[string]string),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start запускает периодический просмотр каталога
+func (w *Watcher) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.opts.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			w.Poll(context.Background())
+
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// Poll один раз просматривает каталог: завершает файлы с готовыми задачами
+// и создает задачи для файлов, которые перестали меняться
+func (w *Watcher) Poll(ctx context.Context) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "Ingest.Poll")
+	defer span.End()
+
+	w.collectFinished(ctx)
+
+	entries, err := os.ReadDir(w.opts.Dir)
+	if err != nil {
+		span.RecordError(err)
+		w.logger.Error("failed to read ingest dir", zap.Error(err))
+		return
+	}
+
+	current := make(map[string]fileState, len(entries))
+	created := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if matched, _ := filepath.Match(w.opts.Pattern, name); !matched {
+			continue
+		}
+		if _, ok := w.inFlight[name]; ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		current[name] = state
+
+		if previous, ok := w.seen[name]; !ok || previous != state {
+			continue
+		}
+		if w.ingest(ctx, name, state) {
+			delete(current, name)
+			created++
+		}
+	}
+	w.seen = current
+
+	span.SetAttributes(attribute.Int("created", created), attribute.Int("in_flight", len(w.inFlight)))
+}
+
+// ingest создает задачу для файла. Возвращает false, если файл нужно
+// попробовать снова при следующем просмотре.
+func (w *Watcher) ingest(ctx context.Context, name string, state fileState) bool {
+	fileLog := w.logger.With(zap.String("file", name))
+	path := filepath.Join(w.opts.Dir, name)
+
+	if w.opts.MaxFileSize > 0 && state.size > w.opts.MaxFileSize {
+		fileLog.Warn("ingest file is too large", zap.Int64("size", state.size))
+		w.finish(name, nil, ErrFileTooLarge)
+		return true
+	}
+
+	words, err := readWords(path)
+	if err != nil {
+		fileLog.Warn("failed to read ingest file", zap.Error(err))
+		w.finish(name, nil, err)
+		return true
+	}
+
+	// ключ идемпотентности не дает создать вторую задачу для того же файла
+	// после перезапуска, пока файл еще не перенесен
+	taskID, err := w.service.CreateTask(ctx, words, domain.TaskOptions{
+		CaseSensitive:  w.opts.CaseSensitive,
+		IdempotencyKey: idempotencyKey(name, state),
+		ClientID:       ClientID,
+	})
+	if err != nil {
+		if errors.Is(err, queue.ErrQueueFull) || errors.Is(err, service.ErrSpoolQuotaExceeded) || errors.Is(err, storage.ErrUnavailable) {
+			fileLog.Warn("failed to create task for ingest file, will retry", zap.Error(err))
+			return false
+		}
+		fileLog.Error("failed to create task for ingest file", zap.Error(err))
+		w.finish(name, nil, err)
+		return true
+	}
+
+	w.inFlight[name] = taskID
+	fileLog.Info("ingest task created", zap.String("task_id", taskID), zap.Int("words", len(words)))
+	return true
+}
+
+// collectFinished переносит файлы, задачи которых завершились
+func (w *Watcher) collectFinished(ctx context.Context) {
+	for name, taskID := range w.inFlight {
+		task, err := w.service.GetTaskByID(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				w.logger.Warn("ingest task disappeared", zap.String("file", name), zap.String("task_id", taskID))
+				delete(w.inFlight, name)
+				w.finish(name, nil, err)
+			}
+			continue
+		}
+
+		switch task.Status {
+		case domain.StatusScheduled, domain.StatusProcessing:
+			continue
+		case domain.StatusCompleted:
+			w.finish(name, task, nil)
+		default:
+			w.finish(name, task, errors.New(task.Error))
+		}
+		delete(w.inFlight, name)
+	}
+}
+
+// finish переносит файл в done или failed и записывает рядом результат задачи или ошибку
+func (w *Watcher) finish(name string, task *domain.Task, taskErr error) {
+	fileLog := w.logger.With(zap.String("file", name))
+
+	dir, suffix := DoneDir, ".result.json"
+	if taskErr != nil {
+		dir, suffix = FailedDir, ".error.json"
+	}
+
+	target := uniquePath(filepath.Join(w.opts.Dir, dir, name))
+	if err := os.Rename(filepath.Join(w.opts.Dir, name), target); err != nil {
+		fileLog.Error("failed to move ingest file", zap.String("target", target), zap.Error(err))
+		return
+	}
+
+	var report any = task
+	if taskErr != nil {
+		failure := struct {
+			TaskID string `json:"task_id,omitempty"`
+			Error  string `json:"error"`
+		}{Error: taskErr.Error()}
+		if task != nil {
+			failure.TaskID = task.ID
+		}
+		report = failure
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(target+suffix, data, 0o644)
+	}
+	if err != nil {
+		fileLog.Error("failed to write ingest result", zap.Error(err))
+		return
+	}
+	fileLog.Info("ingest file processed", zap.String("target", target))
+}
+
+func readWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// файл читается по словам, а не по строкам: весь файл может быть одной строкой
+	var words []string
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, ErrEmptyFile
+	}
+	return words, nil
+}
+
+func idempotencyKey(name string, state fileState) string {
+	return "ingest:" + name + ":" + strconv.FormatInt(state.size, 10) + ":" + strconv.FormatInt(state.modTime.UnixNano(), 10)
+}
+
+// uniquePath добавляет к имени метку времени, если файл с таким именем уже есть
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + ext
+}
@@ -0,0 +1,184 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+type fakeCreator struct {
+	tasks     map[string]*domain.Task
+	createErr error
+	created   []domain.TaskOptions
+}
+
+func (f *fakeCreator) CreateTask(ctx context.Context, words []string, opts domain.TaskOptions) (string, error) {
+	if f.createErr != nil {
+		return "", f.createErr
+	}
+	f.created = append(f.created, opts)
+	id := fmt.Sprintf("task-%d", len(f.created))
+	f.tasks[id] = &domain.Task{ID: id, Status: domain.StatusProcessing, Words: words}
+	return id, nil
+}
+
+func (f *fakeCreator) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
+	task, ok := f.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storage.ErrNotFound, id)
+	}
+	return task.Clone(), nil
+}
+
+func newTestWatcher(t *testing.T, creator *fakeCreator) (*Watcher, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	watcher, err := NewWatcher(creator, Options{Dir: dir, Pattern: "*.txt", PollInterval: time.Second}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return watcher, dir
+}
+
+func TestWatcher_ProcessesStableFile(t *testing.T) {
+	creator := &fakeCreator{tasks: make(map[string]*domain.Task)}
+	watcher, dir := newTestWatcher(t, creator)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dir, "words.txt"), []byte("кот ток\nрост торс\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("кот"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watcher.Poll(ctx)
+	if len(creator.created) != 0 {
+		t.Fatal("expected file to be ingested only after it stops changing")
+	}
+
+	watcher.Poll(ctx)
+	if len(creator.created) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(creator.created))
+	}
+	if opts := creator.created[0]; opts.ClientID != ClientID || opts.IdempotencyKey == "" {
+		t.Errorf("unexpected task options: %+v", opts)
+	}
+
+	watcher.Poll(ctx)
+	if len(creator.created) != 1 {
+		t.Fatal("expected in-flight file not to be ingested again")
+	}
+
+	task := creator.tasks["task-1"]
+	task.Status = domain.StatusCompleted
+	task.Result = [][]string{{"кот", "ток"}, {"рост", "торс"}}
+	task.GroupsCount = 2
+	watcher.Poll(ctx)
+
+	if _, err := os.Stat(filepath.Join(dir, DoneDir, "words.txt")); err != nil {
+		t.Fatalf("expected file to be moved to done: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, DoneDir, "words.txt.result.json"))
+	if err != nil {
+		t.Fatalf("expected result next to processed file: %v", err)
+	}
+	var result domain.Task
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != "task-1" || result.GroupsCount != 2 || len(result.Result) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.md")); err != nil {
+		t.Error("expected file not matching pattern to be left alone")
+	}
+}
+
+func TestWatcher_FailedFiles(t *testing.T) {
+	creator := &fakeCreator{tasks: make(map[string]*domain.Task)}
+	watcher, dir := newTestWatcher(t, creator)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dir, "empty.txt"), []byte("\n\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.txt"), []byte("кот"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watcher.Poll(ctx)
+	watcher.Poll(ctx)
+
+	if _, err := os.Stat(filepath.Join(dir, FailedDir, "empty.txt.error.json")); err != nil {
+		t.Errorf("expected empty file to be moved to failed: %v", err)
+	}
+
+	task := creator.tasks["task-1"]
+	task.Status = domain.StatusFailed
+	task.Error = "task processing timeout"
+	watcher.Poll(ctx)
+
+	data, err := os.ReadFile(filepath.Join(dir, FailedDir, "broken.txt.error.json"))
+	if err != nil {
+		t.Fatalf("expected failed task to be reported: %v", err)
+	}
+	var report struct {
+		TaskID string `json:"task_id"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.TaskID != "task-1" || report.Error != "task processing timeout" {
+		t.Errorf("unexpected error report: %+v", report)
+	}
+}
+
+func TestWatcher_RetriesWhenQueueFull(t *testing.T) {
+	creator := &fakeCreator{tasks: make(map[string]*domain.Task), createErr: queue.ErrQueueFull}
+	watcher, dir := newTestWatcher(t, creator)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dir, "words.txt"), []byte("кот ток"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watcher.Poll(ctx)
+	watcher.Poll(ctx)
+	if _, err := os.Stat(filepath.Join(dir, "words.txt")); err != nil {
+		t.Fatalf("expected file to stay in inbox while queue is full: %v", err)
+	}
+
+	creator.createErr = nil
+	watcher.Poll(ctx)
+	if len(creator.created) != 1 {
+		t.Errorf("expected task to be created once queue has room, got %d", len(creator.created))
+	}
+}
+
+func TestReadWords_SingleLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "line.txt")
+	line := strings.Repeat("кот ток ", 20000)
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	words, err := readWords(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 40000 || words[0] != "кот" || words[39999] != "ток" {
+		t.Errorf("unexpected words: %d", len(words))
+	}
+}
@@ -0,0 +1,207 @@
+package queue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+var _ PriorityQueue = (*DiskQueue)(nil)
+
+const taskFileExt = ".task"
+
+// DiskQueue - очередь задач, которая хранит каждую ожидающую задачу отдельным
+// файлом в каталоге, чтобы очередь переживала падение процесса. Файл пишется
+// до постановки задачи в очередь и удаляется, когда задачу берет воркер
+// или ее удаляют из очереди. При открытии задачи из каталога снова ставятся
+// в очередь.
+type DiskQueue struct {
+	queues *Queues
+	dir    string
+
+	// mu упорядочивает запись и удаление файлов одной и той же задачи
+	mu sync.Mutex
+}
+
+// OpenDiskQueue открывает очередь в каталоге dir и возвращает задачи,
+// которые ожидали в ней до перезапуска, в порядке постановки. Если таких
+// задач больше size, емкость очереди увеличивается, чтобы вместить их все.
+func OpenDiskQueue(dir string, size int) (*DiskQueue, []*domain.Task, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("create queue dir: %w", err)
+	}
+
+	tasks, err := loadTaskFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := &DiskQueue{queues: NewQueues(max(size, len(tasks))), dir: dir}
+	for _, task := range tasks {
+		q.queues.Push(task)
+	}
+	return q, tasks, nil
+}
+
+func loadTaskFiles(dir string) ([]*domain.Task, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read queue dir: %w", err)
+	}
+
+	type queuedFile struct {
+		task    *domain.Task
+		modTime time.Time
+	}
+	var files []queuedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if strings.Contains(name, ".part-") {
+			// файл, который не успели дописать до падения
+			_ = os.Remove(path)
+			continue
+		}
+		if entry.IsDir() || filepath.Ext(name) != taskFileExt {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat queued task %s: %w", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read queued task %s: %w", name, err)
+		}
+		task, err := storage.UnmarshalTask(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode queued task %s: %w", name, err)
+		}
+		files = append(files, queuedFile{task: task, modTime: info.ModTime()})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	tasks := make([]*domain.Task, 0, len(files))
+	for _, file := range files {
+		tasks = append(tasks, file.task)
+	}
+	return tasks, nil
+}
+
+// Enqueue сохраняет задачу на диск и ставит ее в очередь. Если место
+// в очереди не освободилось, файл удаляется.
+func (q *DiskQueue) Enqueue(ctx context.Context, task *domain.Task) error {
+	if err := q.writeTask(task); err != nil {
+		return err
+	}
+
+	if err := q.queues.Enqueue(ctx, task); err != nil {
+		q.forget(task.ID)
+		return err
+	}
+	return nil
+}
+
+func (q *DiskQueue) Dequeue(ctx context.Context) (*domain.Task, error) {
+	task, err := q.queues.Dequeue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q.forget(task.ID)
+	return task, nil
+}
+
+func (q *DiskQueue) Channel(priority domain.TaskPriority) <-chan *domain.Task {
+	return q.queues.Channel(priority)
+}
+
+func (q *DiskQueue) Claim(task *domain.Task) bool {
+	if !q.queues.Claim(task) {
+		return false
+	}
+	q.forget(task.ID)
+	return true
+}
+
+func (q *DiskQueue) Remove(id string) bool {
+	if !q.queues.Remove(id) {
+		return false
+	}
+	q.forget(id)
+	return true
+}
+
+func (q *DiskQueue) Contains(id string) bool {
+	return q.queues.Contains(id)
+}
+
+func (q *DiskQueue) Len() int {
+	return q.queues.Len()
+}
+
+func (q *DiskQueue) Close() {
+	q.queues.Close()
+}
+
+func (q *DiskQueue) writeTask(task *domain.Task) error {
+	data, err := storage.MarshalTask(task)
+	if err != nil {
+		return fmt.Errorf("encode queued task: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	path := q.taskPath(task.ID)
+	tmp, err := os.CreateTemp(q.dir, filepath.Base(path)+".part-*")
+	if err != nil {
+		return fmt.Errorf("create queued task file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write queued task: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync queued task: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close queued task: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace queued task: %w", err)
+	}
+	return nil
+}
+
+// forget удаляет файл задачи, если в очереди не осталось ее экземпляров
+func (q *DiskQueue) forget(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.queues.Contains(id) {
+		return
+	}
+	_ = os.Remove(q.taskPath(id))
+}
+
+// taskPath возвращает путь к файлу задачи; ID задачи хешируется,
+// так как импортированные задачи могут иметь произвольные ID
+func (q *DiskQueue) taskPath(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(q.dir, hex.EncodeToString(sum[:16])+taskFileExt)
+}
@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func countTaskFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return len(entries)
+}
+
+func TestDiskQueue_RestoresPendingTasks(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	q, restored, err := OpenDiskQueue(dir, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restored) != 0 {
+		t.Fatalf("expected empty queue, got %d tasks", len(restored))
+	}
+
+	tasks := []*domain.Task{
+		{ID: "words", Status: domain.StatusProcessing, Words: []string{"кот", "ток"}, Priority: domain.PriorityLow, ClientID: "ip:192.0.2.1", Version: 2},
+		{ID: "file", Status: domain.StatusProcessing, FilePath: "/tmp/input.txt", CaseSensitive: true},
+		{ID: "taken", Status: domain.StatusProcessing, Words: []string{"рост"}, Priority: domain.PriorityHigh},
+		{ID: "cancelled", Status: domain.StatusProcessing, Words: []string{"торс"}},
+	}
+	for _, task := range tasks {
+		if err := q.Enqueue(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// порядок восстановления определяется временем записи файла
+		time.Sleep(10 * time.Millisecond)
+	}
+	if countTaskFiles(t, dir) != 4 {
+		t.Fatalf("expected 4 task files, got %d", countTaskFiles(t, dir))
+	}
+
+	q.Remove("cancelled")
+	if task, err := q.Dequeue(ctx); err != nil || task.ID != "taken" {
+		t.Fatalf("expected high priority task, got %+v, %v", task, err)
+	}
+	if countTaskFiles(t, dir) != 2 {
+		t.Fatalf("expected 2 task files, got %d", countTaskFiles(t, dir))
+	}
+
+	reopened, restored, err := OpenDiskQueue(dir, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restored) != 2 || reopened.Len() != 2 {
+		t.Fatalf("expected 2 restored tasks, got %d (len %d)", len(restored), reopened.Len())
+	}
+
+	byID := make(map[string]*domain.Task)
+	for _, task := range restored {
+		byID[task.ID] = task
+	}
+	words := byID["words"]
+	if words == nil || words.Priority != domain.PriorityLow || words.ClientID != "ip:192.0.2.1" || words.Version != 2 || len(words.Words) != 2 {
+		t.Errorf("unexpected restored task: %+v", words)
+	}
+	file := byID["file"]
+	if file == nil || file.FilePath != "/tmp/input.txt" || !file.CaseSensitive {
+		t.Errorf("unexpected restored task: %+v", file)
+	}
+
+	first, err := reopened.Dequeue(ctx)
+	if err != nil || first.ID != "file" {
+		t.Fatalf("expected normal priority task first, got %+v, %v", first, err)
+	}
+}
+
+func TestDiskQueue_RejectedTaskIsNotPersisted(t *testing.T) {
+	dir := t.TempDir()
+	q, _, err := OpenDiskQueue(dir, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := q.Enqueue(context.Background(), &domain.Task{ID: "first"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "second"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if countTaskFiles(t, dir) != 1 {
+		t.Errorf("expected only the accepted task on disk, got %d files", countTaskFiles(t, dir))
+	}
+
+	tasks := Drain(q)
+	if len(tasks) != 1 || countTaskFiles(t, dir) != 0 {
+		t.Errorf("expected drained task to leave the disk, got %d tasks and %d files", len(tasks), countTaskFiles(t, dir))
+	}
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+// TaskQueue - очередь задач между сервисом и пулом воркеров
+type TaskQueue interface {
+	// Enqueue ставит задачу в очередь, ожидая свободного места до отмены ctx
+	Enqueue(ctx context.Context, task *domain.Task) error
+	// Dequeue возвращает следующую задачу, ожидая ее до отмены ctx.
+	// Если ctx уже отменен, возвращает только готовую задачу, не блокируясь.
+	// Для закрытой и пустой очереди возвращает ErrQueueClosed.
+	Dequeue(ctx context.Context) (*domain.Task, error)
+	// Remove удаляет из очереди ожидающую задачу; false - задачи в очереди нет
+	Remove(id string) bool
+	// Len возвращает количество ожидающих задач
+	Len() int
+	Close()
+}
+
+// PriorityQueue - очередь с отдельным каналом для каждого приоритета.
+// Пул воркеров читает такую очередь напрямую, выбирая приоритет по весам,
+// и отмечает прочитанные задачи взятыми через Claim.
+type PriorityQueue interface {
+	TaskQueue
+	Channel(priority domain.TaskPriority) <-chan *domain.Task
+	// Claim возвращает false, если задачу удалили из очереди и ее нужно пропустить
+	Claim(task *domain.Task) bool
+}
+
+// WithTimeout ограничивает ожидание места в очереди: если за timeout место
+// не освободилось, Enqueue вернет ErrQueueFull. Нулевой timeout означает
+// постановку без ожидания.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout, ErrQueueFull)
+}
+
+// Drain извлекает из очереди все ожидающие задачи, не блокируясь
+func Drain(q TaskQueue) []*domain.Task {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var tasks []*domain.Task
+	for {
+		task, err := q.Dequeue(ctx)
+		if err != nil {
+			return tasks
+		}
+		tasks = append(tasks, task)
+	}
+}
@@ -0,0 +1,279 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+var _ PriorityQueue = (*Queues)(nil)
+
+// ErrQueueFull возвращается, если очередь не освободилась за отведенное время
+var ErrQueueFull = errors.New("task queue is full")
+
+// ErrQueueClosed возвращается из Dequeue, если очередь закрыта и пуста
+var ErrQueueClosed = errors.New("task queue is closed")
+
+// Queues - очередь задач в памяти: набор каналов, по одному на каждый приоритет.
+// Сервис кладет задачу в очередь ее приоритета, а пул воркеров
+// выбирает очередь для чтения с учетом весов. Размер очереди ограничивает
+// число ожидающих задач всех приоритетов вместе.
+type Queues struct {
+	channels map[domain.TaskPriority]chan *domain.Task
+
+	// mu упорядочивает запись в каналы: задачи отправляются только под mu,
+	// поэтому Remove может разобрать канал и вернуть в него оставшиеся задачи.
+	// Задачи, которые читатель уже получил, но еще не отметил через Claim,
+	// учитываются в removed и отбрасываются в Claim.
+	mu      sync.Mutex
+	pending map[string]int
+	removed map[string]int
+	// size - ожидающие задачи всех приоритетов, включая прочитанные, но еще
+	// не отмеченные через Claim; вместе они не превышают capacity
+	size     int
+	capacity int
+	closed   bool
+	// space закрывается и пересоздается, когда в каналах освобождается место
+	space chan struct{}
+}
+
+func NewQueues(size int) *Queues {
+	channels := make(map[domain.TaskPriority]chan *domain.Task, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		channels[priority] = make(chan *domain.Task, size)
+	}
+	return &Queues{
+		channels: channels,
+		pending:  make(map[string]int),
+		capacity: size,
+		removed:  make(map[string]int),
+		space:    make(chan struct{}),
+	}
+}
+
+// Push ставит задачу в очередь ее приоритета и блокируется, если очередь заполнена.
+// Задача без приоритета попадает в очередь normal.
+func (q *Queues) Push(task *domain.Task) {
+	_ = q.Enqueue(context.Background(), task)
+}
+
+// Enqueue ставит задачу в очередь ее приоритета, ожидая свободного места
+// до отмены ctx. Если место не освободилось, возвращается context.Cause(ctx).
+func (q *Queues) Enqueue(ctx context.Context, task *domain.Task) error {
+	ch := q.channel(task.Priority)
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if q.size < q.capacity && len(ch) < cap(ch) {
+			// читатели только освобождают место, а отправители ждут mu,
+			// поэтому запись не блокируется
+			task.EnqueuedAt = time.Now()
+			q.pending[task.ID]++
+			q.size++
+			ch <- task
+			q.mu.Unlock()
+			return nil
+		}
+		space := q.space
+		q.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// notifySpaceLocked будит отправителей, ожидающих места в очереди
+func (q *Queues) notifySpaceLocked() {
+	close(q.space)
+	q.space = make(chan struct{})
+}
+
+// Dequeue возвращает задачу из непустой очереди с наивысшим приоритетом
+// и отмечает ее взятой в обработку. Удаленные из очереди задачи пропускаются.
+func (q *Queues) Dequeue(ctx context.Context) (*domain.Task, error) {
+	for {
+		task, err := q.receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if q.Claim(task) {
+			return task, nil
+		}
+	}
+}
+
+func (q *Queues) receive(ctx context.Context) (*domain.Task, error) {
+	channels := make([]chan *domain.Task, 0, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		channels = append(channels, q.channels[priority])
+	}
+
+	open := 0
+	for _, ch := range channels {
+		select {
+		case task, ok := <-ch:
+			if ok {
+				return task, nil
+			}
+			continue
+		default:
+		}
+		open++
+	}
+	if open == 0 {
+		return nil, ErrQueueClosed
+	}
+
+	high, normal, low := channels[0], channels[1], channels[2]
+	for high != nil || normal != nil || low != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case task, ok := <-high:
+			if !ok {
+				high = nil
+				continue
+			}
+			return task, nil
+		case task, ok := <-normal:
+			if !ok {
+				normal = nil
+				continue
+			}
+			return task, nil
+		case task, ok := <-low:
+			if !ok {
+				low = nil
+				continue
+			}
+			return task, nil
+		}
+	}
+	return nil, ErrQueueClosed
+}
+
+// Channel возвращает канал очереди приоритета для чтения задач
+func (q *Queues) Channel(priority domain.TaskPriority) <-chan *domain.Task {
+	return q.channel(priority)
+}
+
+func (q *Queues) channel(priority domain.TaskPriority) chan *domain.Task {
+	if ch, ok := q.channels[priority]; ok {
+		return ch
+	}
+	return q.channels[domain.PriorityNormal]
+}
+
+// Claim отмечает прочитанную из канала задачу как взятую в обработку.
+// Возвращает false, если задача была удалена из очереди и ее нужно пропустить.
+func (q *Queues) Claim(task *domain.Task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer q.notifySpaceLocked()
+
+	if q.removed[task.ID] > 0 {
+		q.removed[task.ID]--
+		if q.removed[task.ID] == 0 {
+			delete(q.removed, task.ID)
+		}
+		return false
+	}
+
+	if q.pending[task.ID] > 0 {
+		q.pending[task.ID]--
+		q.size--
+		if q.pending[task.ID] == 0 {
+			delete(q.pending, task.ID)
+		}
+	}
+	return true
+}
+
+// Remove удаляет из очередей все ожидающие экземпляры задачи и сразу
+// освобождает занятое ими место. Возвращает false, если задачи в очередях нет.
+func (q *Queues) Remove(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := q.pending[id]
+	if count == 0 {
+		return false
+	}
+	delete(q.pending, id)
+	q.size -= count
+	q.removed[id] += count
+
+	if !q.closed {
+		for _, ch := range q.channels {
+			q.compactLocked(ch, id)
+		}
+	}
+	q.notifySpaceLocked()
+	return true
+}
+
+// compactLocked убирает из канала экземпляры задачи id. Задачи, которые
+// параллельно забрал читатель, остаются в removed и отбрасываются в Claim.
+func (q *Queues) compactLocked(ch chan *domain.Task, id string) {
+	kept := make([]*domain.Task, 0, len(ch))
+	for len(ch) > 0 {
+		var task *domain.Task
+		select {
+		case task = <-ch:
+		default:
+		}
+		if task == nil {
+			break
+		}
+		if task.ID == id && q.removed[id] > 0 {
+			q.removed[id]--
+			if q.removed[id] == 0 {
+				delete(q.removed, id)
+			}
+			continue
+		}
+		kept = append(kept, task)
+	}
+	// пока mu захвачен, в канал никто не пишет, поэтому место для kept есть
+	for _, task := range kept {
+		ch <- task
+	}
+}
+
+// Len возвращает общее количество ожидающих задач во всех очередях
+func (q *Queues) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Contains сообщает, ожидает ли задача в очереди
+func (q *Queues) Contains(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.pending[id] > 0
+}
+
+func (q *Queues) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	for _, ch := range q.channels {
+		close(ch)
+	}
+	q.notifySpaceLocked()
+}
@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func TestQueues_DequeuePrefersHigherPriority(t *testing.T) {
+	q := NewQueues(10)
+	ctx := context.Background()
+
+	q.Push(&domain.Task{ID: "low", Priority: domain.PriorityLow})
+	q.Push(&domain.Task{ID: "normal"})
+	q.Push(&domain.Task{ID: "high", Priority: domain.PriorityHigh})
+
+	for _, want := range []string{"high", "normal", "low"} {
+		task, err := q.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if task.ID != want {
+			t.Errorf("expected %s, got %s", want, task.ID)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue, got %d", q.Len())
+	}
+}
+
+func TestQueues_DequeueSkipsRemovedAndReportsClosed(t *testing.T) {
+	q := NewQueues(10)
+	q.Push(&domain.Task{ID: "removed"})
+	q.Push(&domain.Task{ID: "kept"})
+	if !q.Remove("removed") {
+		t.Fatal("expected task to be removed")
+	}
+	q.Close()
+
+	task, err := q.Dequeue(context.Background())
+	if err != nil || task.ID != "kept" {
+		t.Fatalf("expected kept task, got %+v, %v", task, err)
+	}
+	if _, err := q.Dequeue(context.Background()); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("expected ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestQueues_DequeueWaitsForContext(t *testing.T) {
+	q := NewQueues(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.Dequeue(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestQueues_EnqueueWithTimeoutReportsFull(t *testing.T) {
+	q := NewQueues(1)
+	q.Push(&domain.Task{ID: "first"})
+
+	ctx, cancel := WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "second"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if q.Len() != 1 || q.Contains("second") {
+		t.Errorf("expected rejected task to be rolled back, len %d", q.Len())
+	}
+
+	ctx, cancel = WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "third"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull without waiting, got %v", err)
+	}
+}
+
+func TestQueues_SizeLimitsAllPriorities(t *testing.T) {
+	q := NewQueues(2)
+	q.Push(&domain.Task{ID: "high", Priority: domain.PriorityHigh})
+	q.Push(&domain.Task{ID: "low", Priority: domain.PriorityLow})
+
+	for _, priority := range domain.Priorities {
+		ctx, cancel := WithTimeout(context.Background(), 0)
+		err := q.Enqueue(ctx, &domain.Task{ID: "extra", Priority: priority})
+		cancel()
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("expected ErrQueueFull for %s task, got %v", priority, err)
+		}
+	}
+	if q.Len() != 2 {
+		t.Errorf("expected 2 pending tasks, got %d", q.Len())
+	}
+}
+
+func TestQueues_RemoveFreesCapacity(t *testing.T) {
+	const size = 4
+	q := NewQueues(size)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		q.Push(&domain.Task{ID: id})
+	}
+	q.Remove("b")
+	q.Remove("d")
+
+	for _, id := range []string{"e", "f"} {
+		ctx, cancel := WithTimeout(context.Background(), 0)
+		err := q.Enqueue(ctx, &domain.Task{ID: id})
+		cancel()
+		if err != nil {
+			t.Fatalf("expected %s to fit after removal, got %v", id, err)
+		}
+	}
+	if q.Len() != size {
+		t.Errorf("expected len %d, got %d", size, q.Len())
+	}
+
+	ctx, cancel := WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "g"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull at full size, got %v", err)
+	}
+
+	for _, want := range []string{"a", "c", "e", "f"} {
+		task, err := q.Dequeue(context.Background())
+		if err != nil || task.ID != want {
+			t.Fatalf("expected %s, got %+v, %v", want, task, err)
+		}
+	}
+}
+
+func TestQueues_RemoveWakesBlockedEnqueue(t *testing.T) {
+	q := NewQueues(1)
+	q.Push(&domain.Task{ID: "first"})
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		done <- q.Enqueue(ctx, &domain.Task{ID: "second"})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Remove("first")
+	if err := <-done; err != nil {
+		t.Fatalf("expected blocked enqueue to succeed, got %v", err)
+	}
+	if q.Len() != 1 || !q.Contains("second") {
+		t.Errorf("expected only second in queue, len %d", q.Len())
+	}
+}
+
+func TestDrain(t *testing.T) {
+	q := NewQueues(10)
+	for _, id := range []string{"a", "b", "c"} {
+		q.Push(&domain.Task{ID: id})
+	}
+	q.Remove("b")
+
+	tasks := Drain(q)
+	if len(tasks) != 2 || tasks[0].ID != "a" || tasks[1].ID != "c" {
+		t.Errorf("expected a and c, got %+v", tasks)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue, got %d", q.Len())
+	}
+}
@@ -1,82 +1,342 @@
 package service
 
 import (
-	"bufio"
 	"context"
-	"fmt"
+	"errors"
+	"io"
 	"os"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
 )
 
 var _ AnagramServiceProvider = (*AnagramService)(nil)
 
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+var ErrIdempotencyConflict = errors.New("idempotency key was already used with a different request")
+
+var ErrTaskNotDeadLetter = errors.New("only dead-letter tasks can be requeued")
+
+var ErrTaskNotCancellable = errors.New("only scheduled or processing tasks can be cancelled")
+
+var ErrSchedulingNotSupported = errors.New("delayed tasks are not supported")
+
+// TaskCanceller останавливает задачи, которые уже взяты воркером.
+// CancelTask возвращает true, если итоговый статус задачи сохранит воркер.
+type TaskCanceller interface {
+	CancelTask(id string) bool
+}
+
+// TaskScheduler ставит в очередь задачу, сохраненную в статусе scheduled,
+// когда наступает ее время запуска
+type TaskScheduler interface {
+	Schedule(id string, runAt time.Time)
+}
+
+type cacheInvalidator interface {
+	Invalidate(ctx context.Context, id string) error
+	InvalidateMatching(ctx context.Context, filter domain.CacheFilter) (int, error)
+	Summary(ctx context.Context) (domain.CacheSummary, error)
+}
+
 type AnagramService struct {
 	storage   storage.TaskStorage
-	taskQueue chan<- *domain.Task
+	taskQueue queue.TaskQueue
 	taskStats *TaskStats
 	batchSize int
+
+	idempotencyWindow time.Duration
+	priorities        PriorityThresholds
+	canceller         TaskCanceller
+	scheduler         TaskScheduler
+	enqueueTimeout    time.Duration
+	spool             *Spool
+}
+
+// PriorityThresholds задает границы размера входных данных, по которым
+// определяется приоритет задачи, если клиент его не указал
+type PriorityThresholds struct {
+	// Задачи не больше HighMaxWords слов получают приоритет high
+	HighMaxWords int
+	// Задачи не меньше LowMinWords слов получают приоритет low
+	LowMinWords int
 }
 
-func NewAnagramService(storage storage.TaskStorage, taskQueue chan<- *domain.Task, taskStats *TaskStats, batchSize int) *AnagramService {
+var DefaultPriorityThresholds = PriorityThresholds{HighMaxWords: 1000, LowMinWords: 100000}
+
+func NewAnagramService(storage storage.TaskStorage, taskQueue queue.TaskQueue, taskStats *TaskStats, batchSize int) *AnagramService {
 	return &AnagramService{
 		storage:   storage,
 		taskQueue: taskQueue,
 		taskStats: taskStats,
 		batchSize: batchSize,
+
+		idempotencyWindow: DefaultIdempotencyWindow,
+		priorities:        DefaultPriorityThresholds,
+		spool:             &Spool{},
 	}
 }
 
-func (as *AnagramService) CreateTask(ctx context.Context, words []string, caseSensitive bool) (string, error) {
+func (as *AnagramService) SetPriorityThresholds(thresholds PriorityThresholds) {
+	as.priorities = thresholds
+}
+
+func (as *AnagramService) priorityFor(words []string, requested domain.TaskPriority) domain.TaskPriority {
+	switch {
+	case requested != "":
+		return requested
+	case len(words) <= as.priorities.HighMaxWords:
+		return domain.PriorityHigh
+	case len(words) >= as.priorities.LowMinWords:
+		return domain.PriorityLow
+	default:
+		return domain.PriorityNormal
+	}
+}
+
+func (as *AnagramService) SetIdempotencyWindow(window time.Duration) {
+	as.idempotencyWindow = window
+}
+
+// SetEnqueueTimeout задает, сколько CreateTask ждет места в заполненной очереди
+// перед тем как вернуть queue.ErrQueueFull. 0 - не ждать.
+func (as *AnagramService) SetEnqueueTimeout(timeout time.Duration) {
+	as.enqueueTimeout = timeout
+}
+
+func (as *AnagramService) enqueue(ctx context.Context, task *domain.Task) error {
+	ctx, cancel := queue.WithTimeout(ctx, as.enqueueTimeout)
+	defer cancel()
+	return as.taskQueue.Enqueue(ctx, task)
+}
+
+// SetSpool задает каталог и квоту для слов задач больше batchSize.
+// По умолчанию они пишутся в системный временный каталог без ограничения.
+func (as *AnagramService) SetSpool(spool *Spool) {
+	as.spool = spool
+}
+
+func (as *AnagramService) SetTaskCanceller(canceller TaskCanceller) {
+	as.canceller = canceller
+}
+
+func (as *AnagramService) SetTaskScheduler(scheduler TaskScheduler) {
+	as.scheduler = scheduler
+}
+
+func (as *AnagramService) CreateTask(ctx context.Context, words []string, opts domain.TaskOptions) (string, error) {
 	tr := otel.Tracer("usecase")
 	ctx, span := tr.Start(ctx, "CreateTask")
 	defer span.End()
 
-	task := &domain.Task{
-		ID:           uuid.New().String(),
-		Status:       domain.StatusProcessing,
-		Words:        words,
-		CaseSensitive: caseSensitive,
-		CreatedAt:    time.Now(),
-		TraceContext: make(map[string]string),
+	fingerprint := Fingerprint(words, opts.CaseSensitive)
+
+	scheduled := opts.RunAt.After(time.Now())
+	if scheduled && as.scheduler == nil {
+		return "", ErrSchedulingNotSupported
 	}
 
-	if len(words) > as.batchSize {
-		tmpFile, err := os.CreateTemp("", "anagram-task-*.txt")
+	// отложенная задача должна выполниться в назначенное время,
+	// а не вернуть результат, посчитанный раньше
+	var memoized *domain.Task
+	if !opts.NoMemo && !scheduled {
+		memoized, _ = as.findMemoized(ctx, fingerprint)
+	}
+
+	taskID := uuid.New().String()
+	if memoized != nil {
+		taskID = memoized.ID
+	}
+
+	if opts.IdempotencyKey != "" {
+		existingID, reserved, err := as.reserveIdempotencyKey(ctx, clientIdempotencyKey(opts), RequestHash(fingerprint, opts), taskID)
 		if err != nil {
 			span.RecordError(err)
 			return "", err
 		}
-		defer tmpFile.Close()
+		if !reserved {
+			span.SetAttributes(attribute.Bool("idempotent_replay", true), attribute.String("task_id", existingID))
+			return existingID, nil
+		}
+	}
 
-		w := bufio.NewWriter(tmpFile)
-		for _, word := range words {
-			fmt.Fprintln(w, word)
+	if memoized != nil {
+		span.SetAttributes(attribute.Bool("memoized", true), attribute.String("task_id", memoized.ID))
+		logger.FromContext(ctx).Info("reusing result of identical task", zap.String("task_id", memoized.ID))
+		as.taskStats.IncrementMemoizedTasks()
+		return memoized.ID, nil
+	}
+
+	id, err := as.enqueueTask(ctx, taskID, words, opts, fingerprint)
+	if err != nil {
+		span.RecordError(err)
+		if opts.IdempotencyKey != "" {
+			as.releaseIdempotencyKey(ctx, clientIdempotencyKey(opts))
 		}
-		w.Flush()
+		return "", err
+	}
+	return id, nil
+}
+
+func (as *AnagramService) enqueueTask(ctx context.Context, id string, words []string, opts domain.TaskOptions, fingerprint string) (string, error) {
+	task := &domain.Task{
+		ID:            id,
+		Status:        domain.StatusProcessing,
+		Words:         words,
+		CaseSensitive: opts.CaseSensitive,
+		Priority:      as.priorityFor(words, opts.Priority),
+		ClientID:      opts.ClientID,
+		Fingerprint:   fingerprint,
+		Progress:      &domain.TaskProgress{TotalWords: len(words)},
+		CreatedAt:     time.Now(),
+		TraceContext:  make(map[string]string),
+	}
 
-		task.FilePath = tmpFile.Name()
+	if len(words) > as.batchSize {
+		path, err := as.spool.Write(words)
+		if err != nil {
+			if errors.Is(err, ErrSpoolQuotaExceeded) {
+				logger.FromContext(ctx).Warn("spool quota exceeded, rejecting task", zap.Int("words", len(words)), zap.Error(err))
+			}
+			return "", err
+		}
+		task.FilePath = path
 	} else {
 		task.Words = words
 	}
 
 	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(task.TraceContext))
 
+	if opts.RunAt.After(task.CreatedAt) {
+		runAt := opts.RunAt
+		task.Status = domain.StatusScheduled
+		task.RunAt = &runAt
+	}
+
 	if err := as.storage.Save(ctx, task); err != nil {
-		span.RecordError(err)
+		as.removeTaskFile(ctx, task)
 		return "", err
 	}
 
-	as.taskQueue <- task
+	if task.Status == domain.StatusScheduled {
+		as.scheduler.Schedule(task.ID, opts.RunAt)
+		as.taskStats.IncrementTotalTasks()
+		logger.FromContext(ctx).Info("task scheduled", zap.String("task_id", task.ID), zap.Time("run_at", opts.RunAt))
+		return task.ID, nil
+	}
+
+	if err := as.enqueue(ctx, task); err != nil {
+		logger.FromContext(ctx).Warn("task queue is full, rolling back task", zap.String("task_id", task.ID), zap.Error(err))
+		as.rollbackTask(ctx, task)
+		return "", err
+	}
 	as.taskStats.IncrementTotalTasks()
 	return task.ID, nil
 }
 
+// rollbackTask удаляет задачу, которую не удалось поставить в очередь.
+// Если хранилище не поддерживает удаление, задача помечается как failed,
+// чтобы не оставаться в статусе processing без воркера.
+func (as *AnagramService) rollbackTask(ctx context.Context, task *domain.Task) {
+	l := logger.FromContext(ctx)
+
+	// ctx запроса мог быть уже отменен, а откат должен выполниться в любом случае
+	ctx = context.WithoutCancel(ctx)
+
+	if deleter, ok := as.storage.(storage.TaskDeleter); ok {
+		if err := deleter.Delete(ctx, task.ID); err != nil {
+			l.Error("failed to delete task after enqueue failure", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	} else {
+		task.Status = domain.StatusFailed
+		task.Error = queue.ErrQueueFull.Error()
+		if err := as.storage.Save(ctx, task); err != nil {
+			l.Error("failed to mark task as failed after enqueue failure", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}
+
+	as.removeTaskFile(ctx, task)
+}
+
+func (as *AnagramService) removeTaskFile(ctx context.Context, task *domain.Task) {
+	if task.FilePath == "" {
+		return
+	}
+	if err := os.Remove(task.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.FromContext(ctx).Warn("failed to remove task file", zap.String("task_id", task.ID), zap.Error(err))
+		return
+	}
+	as.spool.forget(task.FilePath)
+}
+
+// clientIdempotencyKey ограничивает ключ идемпотентности клиентом, чтобы
+// одинаковые ключи разных клиентов не пересекались
+func clientIdempotencyKey(opts domain.TaskOptions) string {
+	return opts.ClientID + ":" + opts.IdempotencyKey
+}
+
+// reserveIdempotencyKey закрепляет ключ за taskID. Если ключ уже занят,
+// возвращает идентификатор ранее созданной задачи и false.
+func (as *AnagramService) reserveIdempotencyKey(ctx context.Context, key, requestHash, taskID string) (string, bool, error) {
+	store, ok := as.storage.(storage.IdempotencyStore)
+	if !ok {
+		return "", false, storage.ErrIdempotencyNotSupported
+	}
+
+	existing, reserved, err := store.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{
+		Key:         key,
+		TaskID:      taskID,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(as.idempotencyWindow),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if reserved {
+		return taskID, true, nil
+	}
+	if existing.RequestHash != requestHash {
+		return "", false, ErrIdempotencyConflict
+	}
+
+	logger.FromContext(ctx).Info("replaying idempotent request", zap.String("task_id", existing.TaskID))
+	return existing.TaskID, false, nil
+}
+
+func (as *AnagramService) releaseIdempotencyKey(ctx context.Context, key string) {
+	store, ok := as.storage.(storage.IdempotencyStore)
+	if !ok {
+		return
+	}
+	if err := store.ReleaseIdempotencyKey(ctx, key); err != nil {
+		logger.FromContext(ctx).Warn("failed to release idempotency key", zap.Error(err))
+	}
+}
+
+func (as *AnagramService) findMemoized(ctx context.Context, fingerprint string) (*domain.Task, bool) {
+	finder, ok := as.storage.(storage.FingerprintFinder)
+	if !ok {
+		return nil, false
+	}
+
+	task, found, err := finder.FindByFingerprint(ctx, fingerprint)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to look up memoized result", zap.Error(err))
+		return nil, false
+	}
+	return task, found
+}
+
 func (as *AnagramService) GetTaskByID(ctx context.Context, id string) (*domain.Task, error) {
 	tr := otel.Tracer("usecase")
 	ctx, span := tr.Start(ctx, "GetTaskByID")
@@ -85,8 +345,16 @@ func (as *AnagramService) GetTaskByID(ctx context.Context, id string) (*domain.T
 	task, err := as.storage.GetByID(ctx, id)
 	if err != nil {
 		span.RecordError(err)
+		return nil, err
 	}
-	return task, err
+
+	if loader, ok := as.storage.(storage.ResultLoader); ok {
+		if err := loader.LoadResult(ctx, task); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	return task, nil
 }
 
 func (as *AnagramService) ClearCache(ctx context.Context) error {
@@ -94,8 +362,214 @@ func (as *AnagramService) ClearCache(ctx context.Context) error {
 	ctx, span := tr.Start(ctx, "ClearCache")
 	defer span.End()
 
-	if flusher, ok := as.storage.(interface {Flush(ctx context.Context) error}); ok {
+	if flusher, ok := as.storage.(interface {
+		Flush(ctx context.Context) error
+	}); ok {
 		return flusher.Flush(ctx)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (as *AnagramService) InvalidateCache(ctx context.Context, id string) error {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "InvalidateCache")
+	defer span.End()
+
+	invalidator, ok := as.storage.(cacheInvalidator)
+	if !ok {
+		return storage.ErrNotCached
+	}
+
+	if err := invalidator.Invalidate(ctx, id); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (as *AnagramService) InvalidateCacheMatching(ctx context.Context, filter domain.CacheFilter) (int, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "InvalidateCacheMatching")
+	defer span.End()
+
+	invalidator, ok := as.storage.(cacheInvalidator)
+	if !ok {
+		return 0, nil
+	}
+
+	evicted, err := invalidator.InvalidateMatching(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return evicted, err
+}
+
+func (as *AnagramService) CacheSummary(ctx context.Context) (domain.CacheSummary, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "CacheSummary")
+	defer span.End()
+
+	invalidator, ok := as.storage.(cacheInvalidator)
+	if !ok {
+		return domain.CacheSummary{}, nil
+	}
+
+	summary, err := invalidator.Summary(ctx)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return summary, err
+}
+
+func (as *AnagramService) ExportTasks(ctx context.Context, w io.Writer, statuses []domain.TaskStatus) (int, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "ExportTasks")
+	defer span.End()
+
+	lister, ok := as.storage.(storage.TaskLister)
+	if !ok {
+		return 0, storage.ErrListNotSupported
+	}
+
+	count, err := archive.Export(ctx, w, lister, statuses)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return count, err
+}
+
+func (as *AnagramService) ImportTasks(ctx context.Context, r io.Reader, policy archive.ConflictPolicy) (archive.ImportReport, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "ImportTasks")
+	defer span.End()
+
+	report, err := archive.Import(ctx, r, as.storage, policy)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return report, err
+}
+
+func (as *AnagramService) ListDeadLetterTasks(ctx context.Context) ([]*domain.Task, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "ListDeadLetterTasks")
+	defer span.End()
+
+	lister, ok := as.storage.(storage.TaskLister)
+	if !ok {
+		return nil, storage.ErrListNotSupported
+	}
+
+	tasks, err := lister.List(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	deadLetter := make([]*domain.Task, 0)
+	for _, task := range tasks {
+		if task.Status == domain.StatusDeadLetter {
+			deadLetter = append(deadLetter, task)
+		}
+	}
+	return deadLetter, nil
+}
+
+// RequeueTask возвращает задачу из dead letter в очередь со сброшенным счетчиком попыток
+func (as *AnagramService) RequeueTask(ctx context.Context, id string) error {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "RequeueTask")
+	defer span.End()
+	span.SetAttributes(attribute.String("task_id", id))
+
+	task, err := as.storage.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if task.Status != domain.StatusDeadLetter {
+		return ErrTaskNotDeadLetter
+	}
+
+	task.Status = domain.StatusProcessing
+	previousError, attempts := task.Error, task.Attempts
+	task.Error = ""
+	task.Attempts = 0
+	if err := as.storage.Save(ctx, task); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := as.enqueue(ctx, task); err != nil {
+		span.RecordError(err)
+		task.Status = domain.StatusDeadLetter
+		task.Error = previousError
+		task.Attempts = attempts
+		if saveErr := as.storage.Save(context.WithoutCancel(ctx), task); saveErr != nil {
+			logger.FromContext(ctx).Error("failed to restore dead-letter status", zap.String("task_id", id), zap.Error(saveErr))
+		}
+		return err
+	}
+	logger.FromContext(ctx).Info("dead-letter task requeued", zap.String("task_id", id))
+	return nil
+}
+
+// requeueUncancelled возвращает в очередь задачу, которую CancelTask уже убрал
+// из очереди, но не смог отметить отмененной. При конфликте версий в очередь
+// ставится актуальная задача из хранилища, если она все еще ожидает обработки.
+func (as *AnagramService) requeueUncancelled(ctx context.Context, task *domain.Task) {
+	ctx = context.WithoutCancel(ctx)
+	if latest, err := as.storage.GetByID(ctx, task.ID); err == nil {
+		if latest.Status != domain.StatusProcessing {
+			return
+		}
+		task = latest
+	}
+	if err := as.enqueue(ctx, task); err != nil {
+		logger.FromContext(ctx).Error("failed to requeue task after failed cancellation", zap.String("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// CancelTask удаляет задачу из очереди или прерывает ее обработку.
+// Для выполняющейся задачи отмена асинхронна: статус cancelled сохранит воркер.
+func (as *AnagramService) CancelTask(ctx context.Context, id string) error {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "CancelTask")
+	defer span.End()
+	span.SetAttributes(attribute.String("task_id", id))
+
+	task, err := as.storage.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if task.Status != domain.StatusProcessing && task.Status != domain.StatusScheduled {
+		return ErrTaskNotCancellable
+	}
+
+	queued := as.taskQueue.Remove(id)
+	span.SetAttributes(attribute.Bool("queued", queued))
+	if !queued && as.canceller != nil && as.canceller.CancelTask(id) {
+		l.Info("running task cancellation requested", zap.String("task_id", id))
+		return nil
+	}
+
+	cancelled := task.Clone()
+	cancelled.Status = domain.StatusCancelled
+	cancelled.Error = "task cancelled"
+	if err := as.storage.Save(ctx, cancelled); err != nil {
+		span.RecordError(err)
+		if queued {
+			as.requeueUncancelled(ctx, task)
+		}
+		return err
+	}
+	as.taskStats.IncrementCancelledTasks()
+
+	as.removeTaskFile(ctx, cancelled)
+
+	l.Info("task cancelled", zap.String("task_id", id), zap.Bool("queued", queued))
+	return nil
+}
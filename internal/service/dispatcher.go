@@ -0,0 +1,271 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var _ TaskScheduler = (*TaskDispatcher)(nil)
+
+// DefaultDispatchRetryDelay - через сколько повторить постановку, если очередь заполнена
+const DefaultDispatchRetryDelay = 5 * time.Second
+
+// scheduledEntry - отложенная задача в очереди диспетчера
+type scheduledEntry struct {
+	id    string
+	runAt time.Time
+	// started - задача уже переведена в processing, но не попала в очередь,
+	// и вернуть ей статус scheduled не удалось
+	started bool
+}
+
+// scheduledHeap упорядочивает отложенные задачи по времени запуска
+type scheduledHeap []scheduledEntry
+
+func (h scheduledHeap) Len() int           { return len(h) }
+func (h scheduledHeap) Less(i, j int) bool { return h[i].runAt.Before(h[j].runAt) }
+func (h scheduledHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *scheduledHeap) Push(x any)        { *h = append(*h, x.(scheduledEntry)) }
+func (h *scheduledHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// TaskDispatcher ставит задачи в статусе scheduled в очередь воркеров,
+// когда наступает их время запуска. Сами задачи хранятся в хранилище,
+// поэтому с журналом или снимком очереди они переживают перезапуск.
+type TaskDispatcher struct {
+	storage    storage.TaskStorage
+	taskQueue  queue.TaskQueue
+	logger     *zap.Logger
+	retryDelay time.Duration
+
+	mu      sync.Mutex
+	pending scheduledHeap
+	wake    chan struct{}
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished chan struct{}
+}
+
+func NewTaskDispatcher(storage storage.TaskStorage, taskQueue queue.TaskQueue, logger *zap.Logger) *TaskDispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TaskDispatcher{
+		storage:    storage,
+		taskQueue:  taskQueue,
+		logger:     logger,
+		retryDelay: DefaultDispatchRetryDelay,
+		wake:       make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Schedule добавляет задачу, уже сохраненную в статусе scheduled
+func (d *TaskDispatcher) Schedule(id string, runAt time.Time) {
+	d.push(scheduledEntry{id: id, runAt: runAt})
+}
+
+func (d *TaskDispatcher) push(entry scheduledEntry) {
+	d.mu.Lock()
+	heap.Push(&d.pending, entry)
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Restore добавляет в диспетчер все задачи хранилища в статусе scheduled.
+// Вызывается при запуске после восстановления задач из журнала или снимка.
+func (d *TaskDispatcher) Restore(ctx context.Context) (int, error) {
+	lister, ok := d.storage.(storage.TaskLister)
+	if !ok {
+		return 0, nil
+	}
+
+	tasks, err := lister.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, task := range tasks {
+		if task.Status != domain.StatusScheduled {
+			continue
+		}
+		d.Schedule(task.ID, runAtOf(task))
+		restored++
+	}
+	return restored, nil
+}
+
+func (d *TaskDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.finished != nil {
+		return
+	}
+	d.finished = make(chan struct{})
+	go d.run(d.finished)
+}
+
+// Stop останавливает диспетчер и возвращает задачи, которые еще ждут запуска,
+// чтобы их можно было сохранить до следующего запуска
+func (d *TaskDispatcher) Stop() []*domain.Task {
+	d.cancel()
+
+	d.mu.Lock()
+	finished := d.finished
+	d.mu.Unlock()
+	if finished != nil {
+		<-finished
+	}
+
+	d.mu.Lock()
+	entries := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+
+	var tasks []*domain.Task
+	for _, entry := range entries {
+		task, err := d.storage.GetByID(context.Background(), entry.id)
+		if err != nil || (task.Status != domain.StatusScheduled && !(entry.started && task.Status == domain.StatusProcessing)) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func (d *TaskDispatcher) run(finished chan struct{}) {
+	defer close(finished)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		wait := d.dispatchDue(time.Now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue ставит в очередь наступившие задачи и возвращает время
+// до запуска следующей
+func (d *TaskDispatcher) dispatchDue(now time.Time) time.Duration {
+	for {
+		d.mu.Lock()
+		if len(d.pending) == 0 {
+			d.mu.Unlock()
+			return time.Hour
+		}
+		if wait := d.pending[0].runAt.Sub(now); wait > 0 {
+			d.mu.Unlock()
+			return wait
+		}
+		entry := heap.Pop(&d.pending).(scheduledEntry)
+		d.mu.Unlock()
+
+		if d.ctx.Err() != nil {
+			d.push(entry)
+			return time.Hour
+		}
+		d.dispatch(entry)
+	}
+}
+
+func (d *TaskDispatcher) dispatch(entry scheduledEntry) {
+	id := entry.id
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(d.ctx, "DispatchScheduledTask")
+	defer span.End()
+	span.SetAttributes(attribute.String("task_id", id))
+
+	taskLog := d.logger.With(zap.String("task_id", id))
+
+	task, err := d.storage.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		taskLog.Warn("failed to load scheduled task", zap.Error(err))
+		return
+	}
+	// задачу могли отменить, пока она ждала запуска
+	if entry.started {
+		if task.Status != domain.StatusProcessing {
+			return
+		}
+	} else {
+		if task.Status != domain.StatusScheduled {
+			return
+		}
+
+		task.Status = domain.StatusProcessing
+		if err := d.storage.Save(ctx, task); err != nil {
+			span.RecordError(err)
+			if !errors.Is(err, storage.ErrVersionConflict) {
+				taskLog.Error("failed to start scheduled task", zap.Error(err))
+				d.Schedule(id, time.Now().Add(d.retryDelay))
+			}
+			return
+		}
+	}
+
+	enqueueCtx, cancel := queue.WithTimeout(ctx, 0)
+	defer cancel()
+	if err := d.taskQueue.Enqueue(enqueueCtx, task); err != nil {
+		span.RecordError(err)
+		taskLog.Warn("failed to enqueue scheduled task, postponing", zap.Duration("delay", d.retryDelay), zap.Error(err))
+
+		task.Status = domain.StatusScheduled
+		if err := d.storage.Save(context.WithoutCancel(ctx), task); err != nil {
+			// задача осталась в processing вне очереди: постановка повторяется без смены статуса
+			taskLog.Error("failed to return task to scheduled status", zap.Error(err))
+			d.push(scheduledEntry{id: id, runAt: time.Now().Add(d.retryDelay), started: true})
+			return
+		}
+		d.Schedule(id, time.Now().Add(d.retryDelay))
+		return
+	}
+
+	taskLog.Info("scheduled task enqueued")
+}
+
+func runAtOf(task *domain.Task) time.Time {
+	if task.RunAt == nil {
+		return time.Time{}
+	}
+	return *task.RunAt
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	storagepkg "github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+func newScheduledTestService(t *testing.T) (*AnagramService, *TaskDispatcher, storagepkg.TaskStorage, *queue.Queues) {
+	t.Helper()
+
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	service := NewAnagramService(storage, queues, NewTaskStats(), 10)
+	dispatcher := NewTaskDispatcher(storage, queues, nil)
+	service.SetTaskScheduler(dispatcher)
+	dispatcher.Start()
+	t.Cleanup(func() { dispatcher.Stop() })
+
+	return service, dispatcher, storage, queues
+}
+
+func TestTaskDispatcher_EnqueuesDueTask(t *testing.T) {
+	service, _, storage, queues := newScheduledTestService(t)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{RunAt: time.Now().Add(50 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	task, _ := storage.GetByID(ctx, id)
+	if task.Status != domain.StatusScheduled || task.RunAt == nil {
+		t.Fatalf("expected scheduled task with run_at, got %+v", task)
+	}
+	if queues.Len() != 0 {
+		t.Fatalf("expected scheduled task to wait outside the queue, got %d queued", queues.Len())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	task, _ = storage.GetByID(ctx, id)
+	if task.Status != domain.StatusProcessing {
+		t.Errorf("expected due task to be processing, got %v", task.Status)
+	}
+	if queues.Len() != 1 {
+		t.Errorf("expected due task in queue, got %d queued", queues.Len())
+	}
+}
+
+func TestTaskDispatcher_SkipsCancelledTask(t *testing.T) {
+	service, _, storage, queues := newScheduledTestService(t)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{RunAt: time.Now().Add(50 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.CancelTask(ctx, id); err != nil {
+		t.Fatalf("expected scheduled task to be cancellable: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	task, _ := storage.GetByID(ctx, id)
+	if task.Status != domain.StatusCancelled {
+		t.Errorf("expected cancelled task, got %v", task.Status)
+	}
+	if queues.Len() != 0 {
+		t.Errorf("expected cancelled task not to be enqueued, got %d queued", queues.Len())
+	}
+}
+
+func TestTaskDispatcher_RestoreAndStop(t *testing.T) {
+	storage := storagepkg.NewInMemoryStorage()
+	ctx := context.Background()
+
+	runAt := time.Now().Add(time.Hour)
+	for _, task := range []*domain.Task{
+		{ID: "scheduled", Status: domain.StatusScheduled, RunAt: &runAt},
+		{ID: "done", Status: domain.StatusCompleted},
+	} {
+		if err := storage.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	dispatcher := NewTaskDispatcher(storage, queue.NewQueues(10), nil)
+	restored, err := dispatcher.Restore(ctx)
+	if err != nil || restored != 1 {
+		t.Fatalf("expected 1 restored task, got %d, %v", restored, err)
+	}
+	dispatcher.Start()
+
+	pending := dispatcher.Stop()
+	if len(pending) != 1 || pending[0].ID != "scheduled" {
+		t.Fatalf("expected scheduled task to be returned on stop, got %v", pending)
+	}
+}
+
+// unscheduleFailingStorage не дает вернуть задаче статус scheduled
+type unscheduleFailingStorage struct {
+	*storagepkg.InMemoryStorage
+	fail bool
+}
+
+func (s *unscheduleFailingStorage) Save(ctx context.Context, task *domain.Task) error {
+	if s.fail && task.Status == domain.StatusScheduled {
+		return storagepkg.ErrUnavailable
+	}
+	return s.InMemoryStorage.Save(ctx, task)
+}
+
+func TestTaskDispatcher_RetriesStartedTaskWhenRollbackFails(t *testing.T) {
+	storage := &unscheduleFailingStorage{InMemoryStorage: storagepkg.NewInMemoryStorage()}
+	queues := queue.NewQueues(1)
+	ctx := context.Background()
+
+	runAt := time.Now()
+	if err := storage.Save(ctx, &domain.Task{ID: "scheduled", Status: domain.StatusScheduled, RunAt: &runAt}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	queues.Push(&domain.Task{ID: "filler"})
+
+	dispatcher := NewTaskDispatcher(storage, queues, nil)
+	storage.fail = true
+	dispatcher.dispatch(scheduledEntry{id: "scheduled", runAt: runAt})
+
+	task, _ := storage.GetByID(ctx, "scheduled")
+	if task.Status != domain.StatusProcessing || queues.Contains("scheduled") {
+		t.Fatalf("expected task to stay processing outside the queue, got %s", task.Status)
+	}
+	if len(dispatcher.pending) != 1 || !dispatcher.pending[0].started {
+		t.Fatalf("expected stranded task to be retried, got %+v", dispatcher.pending)
+	}
+
+	storage.fail = false
+	if _, err := queues.Dequeue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dispatcher.dispatchDue(time.Now().Add(time.Hour))
+
+	if !queues.Contains("scheduled") {
+		t.Error("expected stranded task to be enqueued on retry")
+	}
+}
+
+func TestAnagramService_CreateTask_SchedulingNotSupported(t *testing.T) {
+	service := NewAnagramService(storagepkg.NewInMemoryStorage(), queue.NewQueues(10), NewTaskStats(), 10)
+
+	_, err := service.CreateTask(context.Background(), []string{"кот"}, domain.TaskOptions{RunAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, ErrSchedulingNotSupported) {
+		t.Fatalf("expected ErrSchedulingNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+const fingerprintVersion = "v1"
+
+// Fingerprint вычисляет хэш входных слов и параметров группировки.
+// Порядок слов учитывается, так как от него зависит порядок слов в группах.
+func Fingerprint(words []string, caseSensitive bool) string {
+	h := sha256.New()
+	h.Write([]byte(fingerprintVersion))
+	if caseSensitive {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+
+	var length [binary.MaxVarintLen64]byte
+	for _, word := range words {
+		n := binary.PutUvarint(length[:], uint64(len(word)))
+		h.Write(length[:n])
+		h.Write([]byte(word))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RequestHash вычисляет хэш запроса на создание задачи для проверки ключа
+// идемпотентности: помимо входных данных учитываются параметры, меняющие задачу.
+// Для задачи с delay учитывается задержка, а не вычисленное по ней время запуска.
+func RequestHash(fingerprint string, opts domain.TaskOptions) string {
+	h := sha256.New()
+	h.Write([]byte(fingerprint))
+	h.Write([]byte{0})
+	h.Write([]byte(opts.Priority))
+	if opts.NoMemo {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+
+	var schedule [9]byte
+	switch {
+	case opts.Delay > 0:
+		schedule[0] = 1
+		binary.BigEndian.PutUint64(schedule[1:], uint64(opts.Delay))
+	case !opts.RunAt.IsZero():
+		schedule[0] = 2
+		binary.BigEndian.PutUint64(schedule[1:], uint64(opts.RunAt.UnixNano()))
+	}
+	h.Write(schedule[:])
+
+	return hex.EncodeToString(h.Sum(nil))
+}
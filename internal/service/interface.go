@@ -2,20 +2,54 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 )
 
 type AnagramServiceProvider interface {
-	CreateTask(ctx context.Context, words []string, caseSensitive bool) (string, error)
+	CreateTask(ctx context.Context, words []string, opts domain.TaskOptions) (string, error)
 	GetTaskByID(ctx context.Context, id string) (*domain.Task, error)
 	ClearCache(ctx context.Context) error
+	InvalidateCache(ctx context.Context, id string) error
+	InvalidateCacheMatching(ctx context.Context, filter domain.CacheFilter) (int, error)
+	CacheSummary(ctx context.Context) (domain.CacheSummary, error)
+	ExportTasks(ctx context.Context, w io.Writer, statuses []domain.TaskStatus) (int, error)
+	ImportTasks(ctx context.Context, r io.Reader, policy archive.ConflictPolicy) (archive.ImportReport, error)
+	ListDeadLetterTasks(ctx context.Context) ([]*domain.Task, error)
+	RequeueTask(ctx context.Context, id string) error
+	CancelTask(ctx context.Context, id string) error
 }
 
+// WorkerPoolScaler позволяет менять количество воркеров во время работы
+type WorkerPoolScaler interface {
+	Size() int
+	Resize(size int) error
+}
+
+// TaskLeaser сдает задачи в аренду удаленным воркерам
+type TaskLeaser interface {
+	Acquire(ctx context.Context, workerID string, wait time.Duration) (*domain.Lease, error)
+	Heartbeat(ctx context.Context, leaseID string, wordsProcessed, batchesMerged int) (*domain.Lease, error)
+	OpenInput(ctx context.Context, leaseID string) (io.ReadCloser, error)
+	Complete(ctx context.Context, leaseID string, result domain.LeaseResult) error
+}
+
+// QueueDepthProvider сообщает количество задач, ожидающих в очереди
+type QueueDepthProvider interface {
+	Len() int
+}
 
 type TaskStatsProvider interface {
 	IncrementTotalTasks()
 	IncrementCompletedTasks()
 	IncrementFailedTasks()
+	IncrementMemoizedTasks()
+	IncrementRetriedTasks()
+	IncrementDeadLetterTasks()
+	IncrementCancelledTasks()
+	IncrementPanickedTasks()
 	Get() map[string]uint64
 }
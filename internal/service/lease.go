@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var _ TaskLeaser = (*LeaseManager)(nil)
+
+// DefaultLeaseTTL - сколько длится аренда задачи без продления
+const DefaultLeaseTTL = 30 * time.Second
+
+var (
+	ErrLeaseNotFound  = errors.New("lease not found or expired")
+	ErrLeasingStopped = errors.New("task leasing is stopped")
+)
+
+// TaskSource выдает задачи из очереди для обработки вне пула воркеров.
+// Задача, полученная через Take, возвращается через Release после обработки.
+type TaskSource interface {
+	Take(ctx context.Context) (*domain.Task, error)
+	Release(task *domain.Task)
+}
+
+// TaskRetrier - источник задач, который повторяет неудачные попытки по своей
+// политике повторов. Без него ошибка удаленного воркера сразу завершает задачу.
+type TaskRetrier interface {
+	// ShouldRetry сообщает, нужно ли повторить задачу после attempts попыток
+	ShouldRetry(err error, attempts int) bool
+	// Retryable сообщает, является ли ошибка временной
+	Retryable(err error) bool
+	// Retry возвращает задачу в очередь после задержки
+	Retry(task *domain.Task)
+}
+
+// RemoteTaskError - ошибка обработки задачи на удаленном воркере
+type RemoteTaskError struct {
+	Message   string
+	Timeout   bool
+	Transient bool
+}
+
+func (e *RemoteTaskError) Error() string {
+	return e.Message
+}
+
+// Is позволяет политике повторов распознать таймаут обработки на воркере
+func (e *RemoteTaskError) Is(target error) bool {
+	return e.Timeout && target == context.DeadlineExceeded
+}
+
+// LeaseManager сдает задачи в аренду удаленным воркерам. Воркер продлевает
+// аренду, пока обрабатывает задачу, и сдает результат; задача с истекшей
+// арендой возвращается в очередь.
+type LeaseManager struct {
+	storage   storage.TaskStorage
+	taskQueue queue.TaskQueue
+	source    TaskSource
+	taskStats *TaskStats
+	logger    *zap.Logger
+	ttl       time.Duration
+
+	mu      sync.Mutex
+	leases  map[string]*domain.Lease
+	stopped bool
+	// orphaned - задачи с истекшей арендой, которые не удалось вернуть в очередь до остановки
+	orphaned []*domain.Task
+	// acquiring - незавершенные вызовы Acquire; Stop дожидается их,
+	// чтобы взятые ими задачи попали в orphaned
+	acquiring sync.WaitGroup
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished chan struct{}
+}
+
+func NewLeaseManager(storage storage.TaskStorage, taskQueue queue.TaskQueue, source TaskSource, taskStats *TaskStats, logger *zap.Logger) *LeaseManager {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LeaseManager{
+		storage:   storage,
+		taskQueue: taskQueue,
+		source:    source,
+		taskStats: taskStats,
+		logger:    logger,
+		ttl:       DefaultLeaseTTL,
+		leases:    make(map[string]*domain.Lease),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// SetTTL задает, на сколько выдается и продлевается аренда
+func (m *LeaseManager) SetTTL(ttl time.Duration) {
+	if ttl > 0 {
+		m.ttl = ttl
+	}
+}
+
+// Acquire выдает воркеру задачу, ожидая ее не дольше wait.
+// Если задача не появилась, возвращает nil без ошибки.
+func (m *LeaseManager) Acquire(ctx context.Context, workerID string, wait time.Duration) (*domain.Lease, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "AcquireLease")
+	defer span.End()
+	span.SetAttributes(attribute.String("worker_id", workerID))
+
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil, ErrLeasingStopped
+	}
+	m.acquiring.Add(1)
+	m.mu.Unlock()
+	defer m.acquiring.Done()
+
+	// ожидание прерывается и остановкой менеджера
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	stopWait := context.AfterFunc(m.ctx, cancel)
+	defer stopWait()
+
+	task, err := m.source.Take(waitCtx)
+	if err != nil {
+		if m.ctx.Err() != nil {
+			return nil, ErrLeasingStopped
+		}
+		if waitCtx.Err() != nil && ctx.Err() == nil {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	task.Attempts++
+	total := 0
+	if task.Progress != nil {
+		total = task.Progress.TotalWords
+	}
+	task.Progress = &domain.TaskProgress{TotalWords: total, StartedAt: now, UpdatedAt: now}
+
+	lease := &domain.Lease{
+		ID:        uuid.New().String(),
+		WorkerID:  workerID,
+		Task:      task,
+		ExpiresAt: now.Add(m.ttl),
+	}
+
+	m.mu.Lock()
+	if m.stopped {
+		m.orphaned = append(m.orphaned, task)
+		m.mu.Unlock()
+		m.source.Release(task)
+		return nil, ErrLeasingStopped
+	}
+	m.leases[lease.ID] = lease
+	m.mu.Unlock()
+
+	span.SetAttributes(attribute.String("task_id", task.ID), attribute.String("lease_id", lease.ID))
+	m.logger.Info("task leased",
+		zap.String("task_id", task.ID),
+		zap.String("lease_id", lease.ID),
+		zap.String("worker_id", workerID),
+		zap.Int("attempt", task.Attempts),
+	)
+	return lease, nil
+}
+
+// Heartbeat продлевает аренду и сохраняет прогресс обработки. Если задачу
+// за это время изменили, например отменили, аренда прекращается
+// и возвращается ErrLeaseNotFound.
+func (m *LeaseManager) Heartbeat(ctx context.Context, leaseID string, wordsProcessed, batchesMerged int) (*domain.Lease, error) {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "HeartbeatLease")
+	defer span.End()
+	span.SetAttributes(attribute.String("lease_id", leaseID))
+
+	now := time.Now()
+
+	m.mu.Lock()
+	lease, ok := m.leases[leaseID]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrLeaseNotFound
+	}
+	lease.ExpiresAt = now.Add(m.ttl)
+	lease.Task.Progress.WordsProcessed = wordsProcessed
+	lease.Task.Progress.BatchesMerged = batchesMerged
+	lease.Task.Progress.UpdatedAt = now
+	// задачу аренды меняют expire и Complete, поэтому сохраняется копия
+	task := lease.Task.Clone()
+	renewed := *lease
+	m.mu.Unlock()
+
+	if err := m.storage.Save(ctx, task); err != nil {
+		span.RecordError(err)
+		if errors.Is(err, storage.ErrVersionConflict) {
+			m.logger.Info("leased task was modified, revoking lease", zap.String("task_id", task.ID), zap.String("lease_id", leaseID))
+			if removed := m.remove(leaseID); removed != nil {
+				m.source.Release(removed.Task)
+			}
+			return nil, ErrLeaseNotFound
+		}
+		m.logger.Warn("failed to save leased task progress", zap.String("task_id", task.ID), zap.Error(err))
+		return &renewed, nil
+	}
+
+	m.mu.Lock()
+	if current, ok := m.leases[leaseID]; ok && current.Task.Version < task.Version {
+		current.Task.Version = task.Version
+	}
+	m.mu.Unlock()
+	return &renewed, nil
+}
+
+// OpenInput возвращает слова арендованной задачи: содержимое загруженного
+// файла или список слов, по одному в строке
+func (m *LeaseManager) OpenInput(ctx context.Context, leaseID string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	lease, ok := m.leases[leaseID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrLeaseNotFound
+	}
+
+	task := lease.Task
+	if task.FilePath == "" {
+		return io.NopCloser(strings.NewReader(strings.Join(task.Words, "\n"))), nil
+	}
+	return os.Open(task.FilePath)
+}
+
+// Complete сохраняет результат арендованной задачи и завершает аренду
+func (m *LeaseManager) Complete(ctx context.Context, leaseID string, result domain.LeaseResult) error {
+	tr := otel.Tracer("usecase")
+	ctx, span := tr.Start(ctx, "CompleteLease")
+	defer span.End()
+	span.SetAttributes(attribute.String("lease_id", leaseID))
+
+	lease := m.remove(leaseID)
+	if lease == nil {
+		return ErrLeaseNotFound
+	}
+	task := lease.Task
+	defer m.source.Release(task)
+
+	taskLog := m.logger.With(zap.String("task_id", task.ID), zap.String("lease_id", leaseID), zap.String("worker_id", lease.WorkerID))
+	span.SetAttributes(attribute.String("task_id", task.ID))
+
+	task.Progress.WordsProcessed = result.WordsProcessed
+	task.Progress.BatchesMerged = result.BatchesMerged
+	task.Progress.UpdatedAt = time.Now()
+
+	retry := false
+	if result.Error != "" {
+		if result.Panicked {
+			m.taskStats.IncrementPanickedTasks()
+		}
+		task.LastError = result.Error
+
+		err := &RemoteTaskError{Message: result.Error, Timeout: result.Timeout, Transient: result.Transient}
+		retrier, ok := m.source.(TaskRetrier)
+		switch {
+		case ok && retrier.ShouldRetry(err, task.Attempts):
+			retry = true
+			m.taskStats.IncrementRetriedTasks()
+		case ok && retrier.Retryable(err):
+			taskLog.Warn("retry attempts exhausted, moving task to dead letter", zap.Int("attempts", task.Attempts))
+			task.Status = domain.StatusDeadLetter
+			task.Error = result.Error
+			m.taskStats.IncrementDeadLetterTasks()
+		default:
+			task.Status = domain.StatusFailed
+			task.Error = result.Error
+			m.taskStats.IncrementFailedTasks()
+		}
+	} else {
+		task.Status = domain.StatusCompleted
+		task.Result = result.Groups
+		task.ProcessingTimeMS = result.ProcessingTimeMS
+		task.GroupsCount = len(result.Groups)
+		m.taskStats.IncrementCompletedTasks()
+	}
+	span.SetAttributes(attribute.String("status", string(task.Status)), attribute.Bool("retry", retry))
+
+	if err := m.storage.Save(ctx, task); err != nil {
+		span.RecordError(err)
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			taskLog.Error("failed to save leased task result, requeueing", zap.Error(err))
+			task.Status = domain.StatusProcessing
+			task.Result = nil
+			task.Error = ""
+			m.requeue(task)
+			return err
+		}
+		taskLog.Warn("task was modified concurrently, result discarded", zap.Error(err))
+		retry = false
+	}
+
+	if retry {
+		taskLog.Info("leased task failed, scheduling retry", zap.String("error", result.Error))
+		m.source.(TaskRetrier).Retry(task)
+		return nil
+	}
+
+	// входные данные задач в dead letter сохраняются для повторной постановки
+	if task.FilePath != "" && task.Status != domain.StatusDeadLetter {
+		if err := os.Remove(task.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			taskLog.Warn("failed to remove file", zap.Error(err))
+		}
+	}
+	taskLog.Info("leased task finished", zap.String("status", string(task.Status)))
+	return nil
+}
+
+func (m *LeaseManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.finished != nil || m.stopped {
+		return
+	}
+	m.finished = make(chan struct{})
+	go m.run(m.finished)
+}
+
+// Stop прекращает выдачу задач и возвращает задачи из действующих аренд,
+// чтобы их можно было снова поставить в очередь или сохранить до следующего запуска.
+// Результаты, сданные после остановки, отбрасываются.
+func (m *LeaseManager) Stop() []*domain.Task {
+	m.mu.Lock()
+	m.stopped = true
+	finished := m.finished
+	m.mu.Unlock()
+
+	m.cancel()
+	if finished != nil {
+		<-finished
+	}
+	m.acquiring.Wait()
+
+	m.mu.Lock()
+	tasks := m.orphaned
+	m.orphaned = nil
+	for id, lease := range m.leases {
+		tasks = append(tasks, lease.Task)
+		delete(m.leases, id)
+		m.source.Release(lease.Task)
+	}
+	m.mu.Unlock()
+
+	for _, task := range tasks {
+		task.Progress = nil
+	}
+	return tasks
+}
+
+func (m *LeaseManager) run(finished chan struct{}) {
+	defer close(finished)
+
+	ticker := time.NewTicker(max(m.ttl/2, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.expire(now)
+		}
+	}
+}
+
+// expire возвращает в очередь задачи, аренда которых истекла
+func (m *LeaseManager) expire(now time.Time) {
+	var expired []*domain.Lease
+
+	m.mu.Lock()
+	for id, lease := range m.leases {
+		if now.After(lease.ExpiresAt) {
+			expired = append(expired, lease)
+			delete(m.leases, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, lease := range expired {
+		m.logger.Warn("task lease expired, requeueing",
+			zap.String("task_id", lease.Task.ID),
+			zap.String("lease_id", lease.ID),
+			zap.String("worker_id", lease.WorkerID),
+		)
+		m.source.Release(lease.Task)
+		lease.Task.Progress = nil
+		m.requeue(lease.Task)
+	}
+}
+
+// requeue возвращает задачу в очередь, ожидая свободного места до остановки
+func (m *LeaseManager) requeue(task *domain.Task) {
+	if err := m.taskQueue.Enqueue(m.ctx, task); err != nil {
+		m.mu.Lock()
+		m.orphaned = append(m.orphaned, task)
+		m.mu.Unlock()
+	}
+}
+
+func (m *LeaseManager) remove(leaseID string) *domain.Lease {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lease, ok := m.leases[leaseID]
+	if !ok {
+		return nil
+	}
+	delete(m.leases, leaseID)
+	return lease
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	storagepkg "github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+// queueSource выдает задачи прямо из очереди, без пула воркеров
+type queueSource struct {
+	queue queue.TaskQueue
+}
+
+func (s queueSource) Take(ctx context.Context) (*domain.Task, error) {
+	return s.queue.Dequeue(ctx)
+}
+
+func (s queueSource) Release(task *domain.Task) {}
+
+// retryingSource повторяет временные ошибки не более maxAttempts попыток
+type retryingSource struct {
+	queueSource
+	maxAttempts int
+}
+
+func (s retryingSource) ShouldRetry(err error, attempts int) bool {
+	return s.Retryable(err) && attempts < s.maxAttempts
+}
+
+func (s retryingSource) Retryable(err error) bool {
+	var remoteErr *RemoteTaskError
+	return errors.As(err, &remoteErr) && remoteErr.Transient
+}
+
+func (s retryingSource) Retry(task *domain.Task) {
+	_ = s.queue.Enqueue(context.Background(), task)
+}
+
+func newLeaseTestService(t *testing.T, ttl time.Duration) (*AnagramService, *LeaseManager, storagepkg.TaskStorage, *queue.Queues) {
+	t.Helper()
+
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, queues, stats, 10)
+	leases := NewLeaseManager(storage, queues, queueSource{queue: queues}, stats, nil)
+	leases.SetTTL(ttl)
+	leases.Start()
+	t.Cleanup(func() { leases.Stop() })
+
+	return service, leases, storage, queues
+}
+
+func TestLeaseManager_CompleteSavesResult(t *testing.T) {
+	service, leases, storage, _ := newLeaseTestService(t, time.Minute)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток", "дом"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+	if err != nil || lease == nil {
+		t.Fatalf("expected lease, got %v, %v", lease, err)
+	}
+	if lease.Task.ID != id || lease.Task.Attempts != 1 {
+		t.Fatalf("unexpected leased task %+v", lease.Task)
+	}
+
+	input, err := leases.OpenInput(ctx, lease.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := io.ReadAll(input)
+	input.Close()
+	if string(data) != "кот\nток\nдом" {
+		t.Errorf("unexpected input %q", data)
+	}
+
+	if _, err := leases.Heartbeat(ctx, lease.ID, 3, 1); err != nil {
+		t.Fatalf("unexpected heartbeat error: %v", err)
+	}
+
+	err = leases.Complete(ctx, lease.ID, domain.LeaseResult{
+		Groups:           [][]string{{"кот", "ток"}},
+		ProcessingTimeMS: 5,
+		WordsProcessed:   3,
+		BatchesMerged:    1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	task, _ := storage.GetByID(ctx, id)
+	if task.Status != domain.StatusCompleted || task.GroupsCount != 1 || task.Progress.WordsProcessed != 3 {
+		t.Errorf("expected completed task with result, got %+v", task)
+	}
+	if err := leases.Complete(ctx, lease.ID, domain.LeaseResult{}); !errors.Is(err, ErrLeaseNotFound) {
+		t.Errorf("expected ErrLeaseNotFound on second complete, got %v", err)
+	}
+}
+
+func TestLeaseManager_AcquireReturnsNilWhenQueueEmpty(t *testing.T) {
+	_, leases, _, _ := newLeaseTestService(t, time.Minute)
+
+	lease, err := leases.Acquire(context.Background(), "worker-1", 50*time.Millisecond)
+	if err != nil || lease != nil {
+		t.Errorf("expected no lease and no error, got %v, %v", lease, err)
+	}
+}
+
+func TestLeaseManager_ExpiredLeaseRequeuesTask(t *testing.T) {
+	service, leases, _, queues := newLeaseTestService(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+	if err != nil || lease == nil {
+		t.Fatalf("expected lease, got %v, %v", lease, err)
+	}
+	if queues.Len() != 0 {
+		t.Fatalf("expected leased task to leave the queue, got %d queued", queues.Len())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if _, err := leases.Heartbeat(ctx, lease.ID, 1, 1); !errors.Is(err, ErrLeaseNotFound) {
+		t.Errorf("expected ErrLeaseNotFound after expiry, got %v", err)
+	}
+	if !queues.Contains(id) {
+		t.Fatal("expected expired task back in queue")
+	}
+
+	again, err := leases.Acquire(ctx, "worker-2", time.Second)
+	if err != nil || again == nil {
+		t.Fatalf("expected task to be leased again, got %v, %v", again, err)
+	}
+	if again.Task.ID != id || again.Task.Attempts != 2 {
+		t.Errorf("expected second attempt of %s, got %+v", id, again.Task)
+	}
+}
+
+func TestLeaseManager_CompleteFailureFollowsRetryPolicy(t *testing.T) {
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, queues, stats, 10)
+	leases := NewLeaseManager(storage, queues, retryingSource{queueSource: queueSource{queue: queues}, maxAttempts: 2}, stats, nil)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+		if err != nil || lease == nil {
+			t.Fatalf("attempt %d: expected lease, got %v, %v", attempt, lease, err)
+		}
+		if _, err := leases.Heartbeat(ctx, lease.ID, 1, 0); err != nil {
+			t.Fatalf("attempt %d: unexpected heartbeat error: %v", attempt, err)
+		}
+		err = leases.Complete(ctx, lease.ID, domain.LeaseResult{Error: "read failed", Transient: true, Panicked: attempt == 2})
+		if err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt, err)
+		}
+	}
+
+	task, _ := storage.GetByID(ctx, id)
+	if task.Status != domain.StatusDeadLetter || task.Attempts != 2 {
+		t.Errorf("expected dead letter after 2 attempts, got %s after %d", task.Status, task.Attempts)
+	}
+	got := stats.Get()
+	if got["retried_tasks"] != 1 || got["dead_letter_tasks"] != 1 || got["panicked_tasks"] != 1 || got["failed_tasks"] != 0 {
+		t.Errorf("unexpected stats %v", got)
+	}
+
+	id, err = service.CreateTask(ctx, []string{"дом"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+	if err != nil || lease == nil {
+		t.Fatalf("expected lease, got %v, %v", lease, err)
+	}
+	if err := leases.Complete(ctx, lease.ID, domain.LeaseResult{Error: "bad input"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task, _ := storage.GetByID(ctx, id); task.Status != domain.StatusFailed {
+		t.Errorf("expected permanent error to fail task, got %s", task.Status)
+	}
+}
+
+func TestLeaseManager_CancelRevokesLease(t *testing.T) {
+	service, leases, storage, queues := newLeaseTestService(t, time.Minute)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+	if err != nil || lease == nil {
+		t.Fatalf("expected lease, got %v, %v", lease, err)
+	}
+
+	if err := service.CancelTask(ctx, id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := leases.Heartbeat(ctx, lease.ID, 1, 1); !errors.Is(err, ErrLeaseNotFound) {
+		t.Errorf("expected ErrLeaseNotFound for cancelled task, got %v", err)
+	}
+	if err := leases.Complete(ctx, lease.ID, domain.LeaseResult{Groups: [][]string{{"кот", "ток"}}}); !errors.Is(err, ErrLeaseNotFound) {
+		t.Errorf("expected ErrLeaseNotFound on complete after cancel, got %v", err)
+	}
+
+	task, _ := storage.GetByID(ctx, id)
+	if task.Status != domain.StatusCancelled {
+		t.Errorf("expected task to stay cancelled, got %v", task.Status)
+	}
+	if queues.Len() != 0 {
+		t.Errorf("expected cancelled task not to be requeued, got %d queued", queues.Len())
+	}
+}
+
+func TestLeaseManager_StopReturnsLeasedTasks(t *testing.T) {
+	service, leases, _, _ := newLeaseTestService(t, time.Minute)
+	ctx := context.Background()
+
+	id, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lease, err := leases.Acquire(ctx, "worker-1", time.Second)
+	if err != nil || lease == nil {
+		t.Fatalf("expected lease, got %v, %v", lease, err)
+	}
+
+	tasks := leases.Stop()
+	if len(tasks) != 1 || tasks[0].ID != id {
+		t.Fatalf("expected leased task to be returned, got %v", tasks)
+	}
+	if _, err := leases.Acquire(ctx, "worker-1", time.Second); !errors.Is(err, ErrLeasingStopped) {
+		t.Errorf("expected ErrLeasingStopped, got %v", err)
+	}
+	if err := leases.Complete(ctx, lease.ID, domain.LeaseResult{}); !errors.Is(err, ErrLeaseNotFound) {
+		t.Errorf("expected ErrLeaseNotFound after stop, got %v", err)
+	}
+}
+
+// lateSource отдает задачу только после release, даже если ожидание уже отменено,
+// как Take, который успел взять задачу одновременно с остановкой
+type lateSource struct {
+	taken   chan struct{}
+	release chan struct{}
+	task    *domain.Task
+}
+
+func (s lateSource) Take(ctx context.Context) (*domain.Task, error) {
+	close(s.taken)
+	<-s.release
+	return s.task, nil
+}
+
+func (s lateSource) Release(task *domain.Task) {}
+
+func TestLeaseManager_StopWaitsForPendingAcquire(t *testing.T) {
+	source := lateSource{taken: make(chan struct{}), release: make(chan struct{}), task: &domain.Task{ID: "late"}}
+	leases := NewLeaseManager(storagepkg.NewInMemoryStorage(), queue.NewQueues(1), source, NewTaskStats(), nil)
+	leases.Start()
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := leases.Acquire(context.Background(), "worker-1", time.Minute)
+		acquired <- err
+	}()
+	<-source.taken
+
+	stopped := make(chan []*domain.Task, 1)
+	go func() { stopped <- leases.Stop() }()
+
+	select {
+	case tasks := <-stopped:
+		t.Fatalf("expected Stop to wait for pending Acquire, got %v", tasks)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(source.release)
+	tasks := <-stopped
+	if len(tasks) != 1 || tasks[0].ID != "late" {
+		t.Fatalf("expected task taken during stop to be returned, got %v", tasks)
+	}
+	if err := <-acquired; !errors.Is(err, ErrLeasingStopped) {
+		t.Errorf("expected ErrLeasingStopped, got %v", err)
+	}
+}
+
+func TestLeaseManager_StopInterruptsLongPoll(t *testing.T) {
+	queues := queue.NewQueues(1)
+	leases := NewLeaseManager(storagepkg.NewInMemoryStorage(), queues, queueSource{queue: queues}, NewTaskStats(), nil)
+	leases.Start()
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := leases.Acquire(context.Background(), "worker-1", time.Minute)
+		acquired <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	leases.Stop()
+
+	select {
+	case err := <-acquired:
+		if !errors.Is(err, ErrLeasingStopped) {
+			t.Errorf("expected ErrLeasingStopped, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Stop to interrupt waiting Acquire")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
 )
 
@@ -47,11 +48,11 @@ func TestAnagramService_CreateTask_EdgeCases(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task), SaveErr: tc.saveErr}
-			taskQueue := make(chan *domain.Task, 1)
+			taskQueue := queue.NewQueues(1)
 			stats := NewTaskStats()
 			service := NewAnagramService(storage, taskQueue, stats, tc.batchSize)
 			ctx := context.Background()
-			id, err := service.CreateTask(ctx, tc.words, false)
+			id, err := service.CreateTask(ctx, tc.words, domain.TaskOptions{})
 			if tc.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -91,7 +92,7 @@ func TestAnagramService_GetTaskByID_EdgeCases(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task), GetErr: tc.getErr}
-			taskQueue := make(chan *domain.Task, 1)
+			taskQueue := queue.NewQueues(1)
 			stats := NewTaskStats()
 			service := NewAnagramService(storage, taskQueue, stats, 10)
 			ctx := context.Background()
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	storagepkg "github.com/grcflEgor/go-anagram-api/internal/storage"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
 )
 
@@ -27,16 +32,35 @@ func TestTaskStats_IncrementAndGet(t *testing.T) {
 	}
 }
 
+type cacheStatsMock struct{}
+
+func (cacheStatsMock) Stats() storagepkg.CacheStats {
+	return storagepkg.CacheStats{Hits: 3, Misses: 1, Evictions: 2, Bytes: 1024, Entries: 5}
+}
+
+func TestTaskStats_GetWithCacheStats(t *testing.T) {
+	ts := NewTaskStats()
+	ts.SetCacheStats(cacheStatsMock{})
+
+	stats := ts.Get()
+	if stats["cache_hits"] != 3 || stats["cache_misses"] != 1 || stats["cache_evictions"] != 2 {
+		t.Errorf("unexpected cache counters: %v", stats)
+	}
+	if stats["cache_bytes"] != 1024 || stats["cache_entries"] != 5 {
+		t.Errorf("unexpected cache size: %v", stats)
+	}
+}
+
 func TestAnagramService_CreateTask(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	stats := NewTaskStats()
 
 	service := NewAnagramService(storage, taskQueue, stats, 10)
 	ctx := context.Background()
 
 	words := []string{"one", "two"}
-	id, err := service.CreateTask(ctx, words, false)
+	id, err := service.CreateTask(ctx, words, domain.TaskOptions{})
 	if err != nil {
 		t.Fatalf("CreateTask error: %v", err)
 	}
@@ -47,7 +71,7 @@ func TestAnagramService_CreateTask(t *testing.T) {
 
 func TestAnagramService_GetTaskByID(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	stats := NewTaskStats()
 
 	service := NewAnagramService(storage, taskQueue, stats, 10)
@@ -76,7 +100,7 @@ func (f *flusherMock) Flush(ctx context.Context) error {
 
 func TestAnagramService_ClearCache(t *testing.T) {
 	storage := &flusherMock{}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	stats := NewTaskStats()
 
 	service := NewAnagramService(storage, taskQueue, stats, 10)
@@ -90,3 +114,393 @@ func TestAnagramService_ClearCache(t *testing.T) {
 		t.Error("Flush was not called")
 	}
 }
+
+func TestAnagramService_InvalidateCache_NotCachedStorage(t *testing.T) {
+	storage := &flusherMock{}
+	service := NewAnagramService(storage, queue.NewQueues(1), NewTaskStats(), 10)
+
+	err := service.InvalidateCache(context.Background(), "id1")
+	if !errors.Is(err, storagepkg.ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got %v", err)
+	}
+
+	evicted, err := service.InvalidateCacheMatching(context.Background(), domain.CacheFilter{Status: domain.StatusFailed})
+	if err != nil || evicted != 0 {
+		t.Errorf("expected no-op invalidation, got %d, %v", evicted, err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint([]string{"кот", "ток"}, false)
+
+	if base != Fingerprint([]string{"кот", "ток"}, false) {
+		t.Error("expected equal fingerprints for identical input")
+	}
+	if base == Fingerprint([]string{"кот", "ток"}, true) {
+		t.Error("expected case sensitivity to change fingerprint")
+	}
+	if base == Fingerprint([]string{"ток", "кот"}, false) {
+		t.Error("expected word order to change fingerprint")
+	}
+	if Fingerprint([]string{"ab", "c"}, false) == Fingerprint([]string{"a", "bc"}, false) {
+		t.Error("expected word boundaries to change fingerprint")
+	}
+}
+
+func TestAnagramService_CreateTask_Memoization(t *testing.T) {
+	words := []string{"кот", "ток"}
+	storage := &mocks.MockTaskStorage{Tasks: map[string]*domain.Task{
+		"done": {ID: "done", Status: domain.StatusCompleted, Fingerprint: Fingerprint(words, false)},
+	}}
+	taskQueue := queue.NewQueues(1)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, taskQueue, stats, 10)
+
+	id, err := service.CreateTask(context.Background(), words, domain.TaskOptions{})
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	if id != "done" {
+		t.Errorf("expected memoized task id, got %v", id)
+	}
+	if taskQueue.Len() != 0 {
+		t.Error("memoized task must not be enqueued")
+	}
+	if stats.MemoizedTasks.Load() != 1 {
+		t.Errorf("expected 1 memoized task, got %d", stats.MemoizedTasks.Load())
+	}
+
+	id, err = service.CreateTask(context.Background(), words, domain.TaskOptions{NoMemo: true})
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	if id == "done" {
+		t.Error("expected new task when memoization is disabled")
+	}
+	if taskQueue.Len() != 1 {
+		t.Error("expected task to be enqueued when memoization is disabled")
+	}
+	if storage.Tasks[id].Fingerprint != Fingerprint(words, false) {
+		t.Error("expected fingerprint to be stored on new task")
+	}
+}
+
+func TestAnagramService_CreateTask_IdempotencyKey(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(2)
+	service := NewAnagramService(storage, taskQueue, NewTaskStats(), 10)
+	ctx := context.Background()
+	opts := domain.TaskOptions{IdempotencyKey: "retry-1"}
+
+	first, err := service.CreateTask(ctx, []string{"кот", "ток"}, opts)
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	second, err := service.CreateTask(ctx, []string{"кот", "ток"}, opts)
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected retry to return %s, got %s", first, second)
+	}
+	if taskQueue.Len() != 1 {
+		t.Errorf("expected single enqueued task, got %d", taskQueue.Len())
+	}
+
+	_, err = service.CreateTask(ctx, []string{"кот"}, opts)
+	if !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("expected ErrIdempotencyConflict, got %v", err)
+	}
+}
+
+func TestAnagramService_CreateTask_IdempotencyKeyChecksOptions(t *testing.T) {
+	words := []string{"кот", "ток"}
+	base := domain.TaskOptions{IdempotencyKey: "retry-1"}
+	for name, opts := range map[string]domain.TaskOptions{
+		"no_memo":  {IdempotencyKey: "retry-1", NoMemo: true},
+		"priority": {IdempotencyKey: "retry-1", Priority: domain.PriorityHigh},
+		"delay":    {IdempotencyKey: "retry-1", Delay: time.Minute},
+		"run_at":   {IdempotencyKey: "retry-1", RunAt: time.Unix(1700000000, 0)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if RequestHash(Fingerprint(words, false), base) == RequestHash(Fingerprint(words, false), opts) {
+				t.Errorf("expected %s to change request hash", name)
+			}
+		})
+	}
+
+	// повтор запроса с delay дает другое RunAt, но тот же хэш
+	first := domain.TaskOptions{Delay: time.Minute, RunAt: time.Now().Add(time.Minute)}
+	retry := domain.TaskOptions{Delay: time.Minute, RunAt: first.RunAt.Add(time.Second)}
+	if RequestHash("f", first) != RequestHash("f", retry) {
+		t.Error("expected retry with the same delay to keep request hash")
+	}
+
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	service := NewAnagramService(storage, queue.NewQueues(2), NewTaskStats(), 10)
+	ctx := context.Background()
+	if _, err := service.CreateTask(ctx, words, base); err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	_, err := service.CreateTask(ctx, words, domain.TaskOptions{IdempotencyKey: "retry-1", NoMemo: true})
+	if !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("expected ErrIdempotencyConflict, got %v", err)
+	}
+}
+
+func TestAnagramService_CreateTask_IdempotencyKeyScopedByClient(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(3)
+	service := NewAnagramService(storage, taskQueue, NewTaskStats(), 10)
+	ctx := context.Background()
+
+	first, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{ClientID: "a", IdempotencyKey: "retry-1"})
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	second, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{ClientID: "b", IdempotencyKey: "retry-1"})
+	if err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+	if first == second {
+		t.Error("expected another client with the same key to get its own task")
+	}
+	if _, err := service.CreateTask(ctx, []string{"рост"}, domain.TaskOptions{ClientID: "c", IdempotencyKey: "retry-1"}); err != nil {
+		t.Errorf("expected different body from another client not to conflict, got %v", err)
+	}
+	if taskQueue.Len() != 3 {
+		t.Errorf("expected 3 enqueued tasks, got %d", taskQueue.Len())
+	}
+}
+
+func TestAnagramService_CreateTask_IdempotencyKeyExpired(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(2)
+	service := NewAnagramService(storage, taskQueue, NewTaskStats(), 10)
+	service.SetIdempotencyWindow(-time.Second)
+	ctx := context.Background()
+	opts := domain.TaskOptions{IdempotencyKey: "retry-1"}
+
+	first, _ := service.CreateTask(ctx, []string{"a"}, opts)
+	second, err := service.CreateTask(ctx, []string{"b"}, opts)
+	if err != nil {
+		t.Fatalf("expected expired key to be reusable, got %v", err)
+	}
+	if first == second {
+		t.Error("expected new task after key expiration")
+	}
+}
+
+func TestAnagramService_CreateTask_IdempotencyKeyReleasedOnError(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task), SaveErr: errors.New("save failed")}
+	service := NewAnagramService(storage, queue.NewQueues(1), NewTaskStats(), 10)
+	ctx := context.Background()
+
+	if _, err := service.CreateTask(ctx, []string{"a"}, domain.TaskOptions{IdempotencyKey: "retry-1"}); err == nil {
+		t.Fatal("expected save error")
+	}
+	if len(storage.Keys) != 0 {
+		t.Error("expected key to be released after failed creation")
+	}
+}
+
+func TestAnagramService_GetTaskByID_LoadsOffloadedResult(t *testing.T) {
+	results, err := storagepkg.NewFileResultStore(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	storage := storagepkg.NewOffloadingTaskStorage(storagepkg.NewInMemoryStorage(), results, 0)
+	service := NewAnagramService(storage, queue.NewQueues(1), NewTaskStats(), 10)
+	ctx := context.Background()
+
+	_ = storage.Save(ctx, &domain.Task{ID: "1", Status: domain.StatusCompleted, Result: [][]string{{"кот", "ток"}}})
+
+	task, err := service.GetTaskByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetTaskByID error: %v", err)
+	}
+	if task.ResultRef == nil || len(task.Result) != 1 || task.Result[0][1] != "ток" {
+		t.Errorf("expected offloaded result to be loaded, got %+v", task)
+	}
+}
+
+func TestAnagramService_CreateTask_Priority(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	service := NewAnagramService(storage, queue.NewQueues(10), NewTaskStats(), 100)
+	service.SetPriorityThresholds(PriorityThresholds{HighMaxWords: 2, LowMinWords: 5})
+	ctx := context.Background()
+
+	cases := []struct {
+		words     []string
+		requested domain.TaskPriority
+		want      domain.TaskPriority
+	}{
+		{[]string{"a", "b"}, "", domain.PriorityHigh},
+		{[]string{"a", "b", "c"}, "", domain.PriorityNormal},
+		{[]string{"a", "b", "c", "d", "e"}, "", domain.PriorityLow},
+		{[]string{"a", "b", "c", "d", "e"}, domain.PriorityHigh, domain.PriorityHigh},
+	}
+	for _, tc := range cases {
+		id, err := service.CreateTask(ctx, tc.words, domain.TaskOptions{Priority: tc.requested, NoMemo: true})
+		if err != nil {
+			t.Fatalf("CreateTask error: %v", err)
+		}
+		if got := storage.Tasks[id].Priority; got != tc.want {
+			t.Errorf("%d words, requested %q: expected %s, got %s", len(tc.words), tc.requested, tc.want, got)
+		}
+	}
+}
+
+func TestAnagramService_RequeueTask(t *testing.T) {
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	service := NewAnagramService(storage, queues, NewTaskStats(), 10)
+	ctx := context.Background()
+
+	_ = storage.Save(ctx, &domain.Task{ID: "dead", Status: domain.StatusDeadLetter, Attempts: 3, LastError: "read failed", Error: "read failed"})
+	_ = storage.Save(ctx, &domain.Task{ID: "done", Status: domain.StatusCompleted})
+
+	dead, err := service.ListDeadLetterTasks(ctx)
+	if err != nil {
+		t.Fatalf("ListDeadLetterTasks error: %v", err)
+	}
+	if len(dead) != 1 || dead[0].ID != "dead" {
+		t.Fatalf("expected only dead-letter task, got %+v", dead)
+	}
+
+	if err := service.RequeueTask(ctx, "done"); !errors.Is(err, ErrTaskNotDeadLetter) {
+		t.Errorf("expected ErrTaskNotDeadLetter, got %v", err)
+	}
+	if err := service.RequeueTask(ctx, "missing"); !errors.Is(err, storagepkg.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := service.RequeueTask(ctx, "dead"); err != nil {
+		t.Fatalf("RequeueTask error: %v", err)
+	}
+	task, _ := storage.GetByID(ctx, "dead")
+	if task.Status != domain.StatusProcessing || task.Attempts != 0 || task.Error != "" {
+		t.Errorf("expected reset task, got %+v", task)
+	}
+	if queues.Len() != 1 {
+		t.Errorf("expected task to be queued, got %d", queues.Len())
+	}
+}
+
+type fakeCanceller struct {
+	running map[string]bool
+}
+
+func (c *fakeCanceller) CancelTask(id string) bool {
+	return c.running[id]
+}
+
+func TestAnagramService_CancelTask(t *testing.T) {
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, queues, stats, 10)
+	service.SetTaskCanceller(&fakeCanceller{running: map[string]bool{"running": true}})
+	ctx := context.Background()
+
+	filePath := filepath.Join(t.TempDir(), "upload.txt")
+	_ = os.WriteFile(filePath, []byte("кот ток"), 0644)
+
+	queued := &domain.Task{ID: "queued", Status: domain.StatusProcessing, FilePath: filePath}
+	_ = storage.Save(ctx, queued)
+	queues.Push(queued)
+	_ = storage.Save(ctx, &domain.Task{ID: "running", Status: domain.StatusProcessing})
+	_ = storage.Save(ctx, &domain.Task{ID: "done", Status: domain.StatusCompleted})
+
+	if err := service.CancelTask(ctx, "queued"); err != nil {
+		t.Fatalf("CancelTask error: %v", err)
+	}
+	task, _ := storage.GetByID(ctx, "queued")
+	if task.Status != domain.StatusCancelled {
+		t.Errorf("expected queued task to be cancelled, got %s", task.Status)
+	}
+	if queues.Len() != 0 {
+		t.Errorf("expected task to be removed from queue, got %d", queues.Len())
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Error("expected file of cancelled task to be removed")
+	}
+
+	if err := service.CancelTask(ctx, "running"); err != nil {
+		t.Fatalf("CancelTask error: %v", err)
+	}
+	task, _ = storage.GetByID(ctx, "running")
+	if task.Status != domain.StatusProcessing {
+		t.Errorf("expected running task status to be left to worker, got %s", task.Status)
+	}
+
+	if err := service.CancelTask(ctx, "done"); !errors.Is(err, ErrTaskNotCancellable) {
+		t.Errorf("expected ErrTaskNotCancellable, got %v", err)
+	}
+	if stats.CancelledTasks.Load() != 1 {
+		t.Errorf("expected 1 cancelled task, got %d", stats.CancelledTasks.Load())
+	}
+}
+
+func TestAnagramService_CancelTask_SaveErrorKeepsTaskQueued(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	queues := queue.NewQueues(10)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, queues, stats, 10)
+	ctx := context.Background()
+
+	queued := &domain.Task{ID: "queued", Status: domain.StatusProcessing, Words: []string{"кот"}}
+	_ = storage.Save(ctx, queued.Clone())
+	queues.Push(queued)
+
+	storage.SaveErr = storagepkg.ErrUnavailable
+	if err := service.CancelTask(ctx, "queued"); !errors.Is(err, storagepkg.ErrUnavailable) {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+
+	if !queues.Contains("queued") || queues.Len() != 1 {
+		t.Errorf("expected task to stay queued after failed cancellation, got %d queued", queues.Len())
+	}
+	task, _ := storage.GetByID(ctx, "queued")
+	if task.Status != domain.StatusProcessing {
+		t.Errorf("expected task to stay processing, got %s", task.Status)
+	}
+	if stats.CancelledTasks.Load() != 0 {
+		t.Errorf("expected no cancelled tasks, got %d", stats.CancelledTasks.Load())
+	}
+}
+
+func TestAnagramService_CreateTask_QueueFull(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(1)
+	stats := NewTaskStats()
+	service := NewAnagramService(storage, queues, stats, 2)
+	service.SetEnqueueTimeout(10 * time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := service.CreateTask(ctx, []string{"a"}, domain.TaskOptions{NoMemo: true}); err != nil {
+		t.Fatalf("CreateTask error: %v", err)
+	}
+
+	_, err := service.CreateTask(ctx, []string{"кот", "ток", "рост"}, domain.TaskOptions{NoMemo: true, IdempotencyKey: "full-1"})
+	if !errors.Is(err, queue.ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	tasks, _ := storage.List(ctx)
+	if len(tasks) != 1 {
+		t.Errorf("expected rejected task to be rolled back, got %d tasks", len(tasks))
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("expected temp file of rejected task to be removed, got %d files", len(entries))
+	}
+	if _, reserved, _ := storage.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "full-1"}); !reserved {
+		t.Error("expected idempotency key to be released")
+	}
+	if queues.Len() != 1 || stats.TotalTasks.Load() != 1 {
+		t.Errorf("expected only first task to be queued, got %d queued, %d total", queues.Len(), stats.TotalTasks.Load())
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"go.uber.org/zap"
+)
+
+// spoolFilePattern - шаблон имени файла, в который выгружаются слова большой задачи
+const spoolFilePattern = "anagram-task-*.txt"
+
+var ErrSpoolQuotaExceeded = errors.New("spool disk quota exceeded")
+
+var ErrSpoolDirRequired = errors.New("spool dir is required")
+
+// Spool хранит слова больших задач файлами в отдельном каталоге, пока их
+// не обработает воркер. Квота ограничивает суммарный размер файлов в каталоге:
+// новая задача, которая в нее не помещается, отклоняется.
+type Spool struct {
+	dir   string
+	quota int64
+
+	mu sync.Mutex
+	// reserved - байты файлов, которые сейчас записываются
+	reserved int64
+	// files - размеры файлов каталога, used - их сумма. Файлы удаляют воркеры,
+	// поэтому учет сверяется с диском, только когда квота кажется исчерпанной.
+	files map[string]int64
+	used  int64
+}
+
+// NewSpool создает каталог dir и учитывает уже лежащие в нем файлы задач.
+// Каталог должен принадлежать одному экземпляру сервиса: Sweep удаляет в нем
+// файлы, на которые не ссылаются его задачи. quota 0 - без ограничения размера.
+func NewSpool(dir string, quota int64) (*Spool, error) {
+	if dir == "" {
+		return nil, ErrSpoolDirRequired
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+
+	s := &Spool{dir: dir, quota: quota, files: make(map[string]int64)}
+	paths, err := filepath.Glob(filepath.Join(dir, spoolFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("stat spool file: %w", err)
+		}
+		s.files[path] = info.Size()
+		s.used += info.Size()
+	}
+	return s, nil
+}
+
+// Dir возвращает каталог, в который выгружаются задачи
+func (s *Spool) Dir() string {
+	if s.dir == "" {
+		return os.TempDir()
+	}
+	return s.dir
+}
+
+// Write записывает слова в новый файл, по одному в строке, и возвращает его путь
+func (s *Spool) Write(words []string) (string, error) {
+	var size int64
+	for _, word := range words {
+		size += int64(len(word)) + 1
+	}
+
+	if err := s.reserve(size); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(s.dir, spoolFilePattern)
+	if err != nil {
+		s.release(size, "")
+		return "", err
+	}
+
+	w := bufio.NewWriter(file)
+	for _, word := range words {
+		fmt.Fprintln(w, word)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		s.release(size, "")
+		return "", fmt.Errorf("write spool file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		s.release(size, "")
+		return "", fmt.Errorf("close spool file: %w", err)
+	}
+	s.release(size, file.Name())
+	return file.Name(), nil
+}
+
+func (s *Spool) reserve(size int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quota > 0 && s.used+s.reserved+size > s.quota {
+		// часть файлов могла быть уже удалена воркерами
+		s.reconcileLocked()
+		if s.used+s.reserved+size > s.quota {
+			return fmt.Errorf("%w: task needs %d bytes, %d of %d in use", ErrSpoolQuotaExceeded, size, s.used+s.reserved, s.quota)
+		}
+	}
+	s.reserved += size
+	return nil
+}
+
+// release снимает резерв и учитывает записанный файл path, если он есть
+func (s *Spool) release(size int64, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reserved -= size
+	if path != "" {
+		if s.files == nil {
+			s.files = make(map[string]int64)
+		}
+		s.files[path] = size
+		s.used += size
+	}
+}
+
+// reconcileLocked убирает из учета файлы, которых больше нет на диске
+func (s *Spool) reconcileLocked() {
+	for path, size := range s.files {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(s.files, path)
+			s.used -= size
+		}
+	}
+}
+
+// forget убирает удаленный файл из учета
+func (s *Spool) forget(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if size, ok := s.files[path]; ok {
+		delete(s.files, path)
+		s.used -= size
+	}
+}
+
+// Usage возвращает суммарный размер файлов задач в каталоге
+func (s *Spool) Usage() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reconcileLocked()
+	return s.used, nil
+}
+
+// Sweep удаляет файлы задач, на которые не ссылается ни одна незавершенная
+// задача хранилища: они остаются, если процесс упал или очередь потерялась
+// до обработки. Вызывается при запуске, после восстановления задач.
+// Без собственного каталога, во временном каталоге системы, ничего не удаляет.
+func (s *Spool) Sweep(ctx context.Context, taskStorage storage.TaskStorage, logger *zap.Logger) (int, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if s.dir == "" {
+		return 0, nil
+	}
+
+	lister, ok := taskStorage.(storage.TaskLister)
+	if !ok {
+		return 0, storage.ErrListNotSupported
+	}
+
+	tasks, err := lister.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// файлы задач в dead letter хранятся для повторной постановки
+	referenced := make(map[string]struct{})
+	for _, task := range tasks {
+		if task.FilePath == "" {
+			continue
+		}
+		switch task.Status {
+		case domain.StatusProcessing, domain.StatusScheduled, domain.StatusDeadLetter:
+			if path, err := filepath.Abs(task.FilePath); err == nil {
+				referenced[path] = struct{}{}
+			}
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(s.Dir(), spoolFilePattern))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		if _, ok := referenced[abs]; ok {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("failed to remove orphaned spool file", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		s.forget(path)
+		removed++
+	}
+	return removed, nil
+}
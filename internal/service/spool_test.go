@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	storagepkg "github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+func TestSpool_WriteRespectsQuota(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := NewSpool(dir, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, err := spool.Write([]string{"кот", "ток"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("expected file in spool dir, got %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "кот\nток\n" {
+		t.Errorf("unexpected file content %q", data)
+	}
+
+	if _, err := spool.Write([]string{"дом", "мод"}); !errors.Is(err, ErrSpoolQuotaExceeded) {
+		t.Fatalf("expected ErrSpoolQuotaExceeded, got %v", err)
+	}
+	if used, _ := spool.Usage(); used != 14 {
+		t.Errorf("expected rejected task not to use space, got %d bytes", used)
+	}
+
+	os.Remove(path)
+	if _, err := spool.Write([]string{"дом", "мод"}); err != nil {
+		t.Errorf("expected space to be available after file removal, got %v", err)
+	}
+}
+
+func TestSpool_CountsExistingFilesAndRequiresDir(t *testing.T) {
+	if _, err := NewSpool("", 0); !errors.Is(err, ErrSpoolDirRequired) {
+		t.Fatalf("expected ErrSpoolDirRequired, got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "anagram-task-1.txt"), []byte("кот\nток\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spool, err := NewSpool(dir, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used, _ := spool.Usage(); used != 14 {
+		t.Errorf("expected existing file to be counted, got %d bytes", used)
+	}
+	if _, err := spool.Write([]string{"дом", "мод"}); !errors.Is(err, ErrSpoolQuotaExceeded) {
+		t.Errorf("expected ErrSpoolQuotaExceeded, got %v", err)
+	}
+}
+
+func TestSpool_SweepSkipsSharedTempDir(t *testing.T) {
+	spool := &Spool{}
+	path, err := spool.Write([]string{"кот"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(path)
+
+	removed, err := spool.Sweep(context.Background(), storagepkg.NewInMemoryStorage(), nil)
+	if err != nil || removed != 0 {
+		t.Fatalf("expected sweep to be skipped, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected file in temp dir to be kept, got %v", err)
+	}
+}
+
+func TestSpool_SweepRemovesOrphans(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	spool, err := NewSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	storage := storagepkg.NewInMemoryStorage()
+	paths := make(map[domain.TaskStatus]string)
+	for _, status := range []domain.TaskStatus{domain.StatusProcessing, domain.StatusDeadLetter, domain.StatusCompleted} {
+		path, err := spool.Write([]string{"кот", "ток"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		paths[status] = path
+		if err := storage.Save(ctx, &domain.Task{ID: string(status), Status: status, FilePath: path}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	orphan, _ := spool.Write([]string{"дом", "мод"})
+	foreign := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(foreign, []byte("keep"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	removed, err := spool.Sweep(ctx, storage, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 orphaned files removed, got %d", removed)
+	}
+
+	for path, want := range map[string]bool{
+		paths[domain.StatusProcessing]: true,
+		paths[domain.StatusDeadLetter]: true,
+		paths[domain.StatusCompleted]:  false,
+		orphan:                         false,
+		foreign:                        true,
+	} {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != want {
+			t.Errorf("%s: expected exists=%v, got %v", filepath.Base(path), want, exists)
+		}
+	}
+}
+
+func TestCreateTask_RejectsLargeTaskOverSpoolQuota(t *testing.T) {
+	ctx := context.Background()
+	storage := storagepkg.NewInMemoryStorage()
+	queues := queue.NewQueues(10)
+	service := NewAnagramService(storage, queues, NewTaskStats(), 2)
+
+	spool, err := NewSpool(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service.SetSpool(spool)
+
+	if _, err := service.CreateTask(ctx, []string{"кот", "ток", "окт"}, domain.TaskOptions{}); !errors.Is(err, ErrSpoolQuotaExceeded) {
+		t.Fatalf("expected ErrSpoolQuotaExceeded, got %v", err)
+	}
+	if queues.Len() != 0 {
+		t.Errorf("expected rejected task not to be queued, got %d", queues.Len())
+	}
+
+	if _, err := service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{}); err != nil {
+		t.Errorf("expected small task to bypass spool, got %v", err)
+	}
+}
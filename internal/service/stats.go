@@ -1,17 +1,41 @@
 package service
 
-import "sync/atomic"
+import (
+	"sync/atomic"
+
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+type CacheStatsProvider interface {
+	Stats() storage.CacheStats
+}
 
 type TaskStats struct {
-	TotalTasks atomic.Uint64
-	CompletedTasks atomic.Uint64
-	FailedTasks atomic.Uint64
+	TotalTasks      atomic.Uint64
+	CompletedTasks  atomic.Uint64
+	FailedTasks     atomic.Uint64
+	MemoizedTasks   atomic.Uint64
+	RetriedTasks    atomic.Uint64
+	DeadLetterTasks atomic.Uint64
+	CancelledTasks  atomic.Uint64
+	PanickedTasks   atomic.Uint64
+
+	cache CacheStatsProvider
+	queue QueueDepthProvider
 }
 
 func NewTaskStats() *TaskStats {
 	return &TaskStats{}
 }
 
+func (ts *TaskStats) SetCacheStats(cache CacheStatsProvider) {
+	ts.cache = cache
+}
+
+func (ts *TaskStats) SetQueue(queue QueueDepthProvider) {
+	ts.queue = queue
+}
+
 func (ts *TaskStats) IncrementTotalTasks() {
 	ts.TotalTasks.Add(1)
 }
@@ -24,11 +48,50 @@ func (ts *TaskStats) IncrementFailedTasks() {
 	ts.FailedTasks.Add(1)
 }
 
+func (ts *TaskStats) IncrementMemoizedTasks() {
+	ts.MemoizedTasks.Add(1)
+}
+
+func (ts *TaskStats) IncrementRetriedTasks() {
+	ts.RetriedTasks.Add(1)
+}
+
+func (ts *TaskStats) IncrementDeadLetterTasks() {
+	ts.DeadLetterTasks.Add(1)
+}
+
+func (ts *TaskStats) IncrementCancelledTasks() {
+	ts.CancelledTasks.Add(1)
+}
+
+func (ts *TaskStats) IncrementPanickedTasks() {
+	ts.PanickedTasks.Add(1)
+}
 
 func (ts *TaskStats) Get() map[string]uint64 {
-	return map[string]uint64{
-		"total_tasks": ts.TotalTasks.Load(),
-		"completed_tasks": ts.CompletedTasks.Load(),
-		"failed_tasks": ts.FailedTasks.Load(),
+	stats := map[string]uint64{
+		"total_tasks":       ts.TotalTasks.Load(),
+		"completed_tasks":   ts.CompletedTasks.Load(),
+		"failed_tasks":      ts.FailedTasks.Load(),
+		"memoized_tasks":    ts.MemoizedTasks.Load(),
+		"retried_tasks":     ts.RetriedTasks.Load(),
+		"dead_letter_tasks": ts.DeadLetterTasks.Load(),
+		"cancelled_tasks":   ts.CancelledTasks.Load(),
+		"panicked_tasks":    ts.PanickedTasks.Load(),
 	}
-}
\ No newline at end of file
+
+	if ts.cache != nil {
+		cacheStats := ts.cache.Stats()
+		stats["cache_hits"] = cacheStats.Hits
+		stats["cache_misses"] = cacheStats.Misses
+		stats["cache_evictions"] = cacheStats.Evictions
+		stats["cache_bytes"] = cacheStats.Bytes
+		stats["cache_entries"] = cacheStats.Entries
+	}
+
+	if ts.queue != nil {
+		stats["queue_depth"] = uint64(ts.queue.Len())
+	}
+
+	return stats
+}
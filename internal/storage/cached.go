@@ -2,10 +2,11 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 	"github.com/grcflEgor/go-anagram-api/pkg/logger"
-	"github.com/patrickmn/go-cache"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
@@ -13,12 +14,20 @@ import (
 
 var _ TaskStorage = (*CachedTaskStorage)(nil)
 
+var ErrNotCached = errors.New("task is not cached")
+
+var ErrListNotSupported = errors.New("storage does not support listing tasks")
+
+var ErrIdempotencyNotSupported = errors.New("storage does not support idempotency keys")
+
+var ErrDeleteNotSupported = errors.New("storage does not support deleting tasks")
+
 type CachedTaskStorage struct {
 	next  TaskStorage
-	cache *cache.Cache
+	cache *LRUCache
 }
 
-func NewCachedTaskStorage(next TaskStorage, cache *cache.Cache) *CachedTaskStorage {
+func NewCachedTaskStorage(next TaskStorage, cache *LRUCache) *CachedTaskStorage {
 	return &CachedTaskStorage{
 		next:  next,
 		cache: cache,
@@ -33,12 +42,12 @@ func (r *CachedTaskStorage) GetByID(ctx context.Context, id string) (*domain.Tas
 	defer span.End()
 
 	if task, found := r.cache.Get(id); found {
-		l.Info("cache HIT for task", zap.String("task_id", id))
+		l.Debug("cache HIT for task", zap.String("task_id", id))
 		span.SetAttributes(attribute.String("cache", "HIT"))
-		return task.(*domain.Task), nil
+		return task.Clone(), nil
 	}
 	span.SetAttributes(attribute.String("cache", "MISS"))
-	l.Info("cache MISS for task", zap.String("task_id", id))
+	l.Debug("cache MISS for task", zap.String("task_id", id))
 
 	task, err := r.next.GetByID(ctx, id)
 	if err != nil {
@@ -46,7 +55,7 @@ func (r *CachedTaskStorage) GetByID(ctx context.Context, id string) (*domain.Tas
 		return nil, err
 	}
 
-	r.cache.Set(id, task, cache.DefaultExpiration)
+	r.cache.Set(id, cacheable(task))
 
 	return task, nil
 }
@@ -59,16 +68,47 @@ func (r *CachedTaskStorage) Save(ctx context.Context, task *domain.Task) error {
 	defer span.End()
 
 	if err := r.next.Save(ctx, task); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			r.cache.Delete(task.ID)
+		}
 		span.RecordError(err)
 		return err
 	}
 
-	r.cache.Set(task.ID, task, cache.DefaultExpiration)
+	r.cache.Set(task.ID, cacheable(task))
 	l.Info("task saved and cache updated", zap.String("task_id", task.ID))
 
 	return nil
 }
 
+// cacheable возвращает копию задачи для кэша. Вынесенный результат в кэш
+// не попадает: он подгружается через LoadResult, когда нужен.
+func cacheable(task *domain.Task) *domain.Task {
+	cached := task.Clone()
+	if cached.ResultRef != nil {
+		cached.Result = nil
+	}
+	return cached
+}
+
+func (r *CachedTaskStorage) Delete(ctx context.Context, id string) error {
+	deleter, ok := r.next.(TaskDeleter)
+	if !ok {
+		return ErrDeleteNotSupported
+	}
+
+	tr := otel.Tracer("repository")
+	ctx, span := tr.Start(ctx, "CachedTaskStorage.Delete")
+	defer span.End()
+
+	r.cache.Delete(id)
+	if err := deleter.Delete(ctx, id); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
 func (r *CachedTaskStorage) Flush(ctx context.Context) error {
 	l := logger.FromContext(ctx)
 
@@ -81,4 +121,123 @@ func (r *CachedTaskStorage) Flush(ctx context.Context) error {
 	l.Info("cache flushed")
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *CachedTaskStorage) List(ctx context.Context) ([]*domain.Task, error) {
+	lister, ok := r.next.(TaskLister)
+	if !ok {
+		return nil, ErrListNotSupported
+	}
+	return lister.List(ctx)
+}
+
+func (r *CachedTaskStorage) Stats() CacheStats {
+	return r.cache.Stats()
+}
+
+func (r *CachedTaskStorage) Invalidate(ctx context.Context, id string) error {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "CachedTaskStorage.Invalidate")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("task_id", id))
+
+	if !r.cache.Delete(id) {
+		return ErrNotCached
+	}
+
+	l.Info("task evicted from cache", zap.String("task_id", id))
+
+	return nil
+}
+
+func (r *CachedTaskStorage) InvalidateMatching(ctx context.Context, filter domain.CacheFilter) (int, error) {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "CachedTaskStorage.InvalidateMatching")
+	defer span.End()
+
+	ids := make(map[string]struct{}, len(filter.TaskIDs))
+	for _, id := range filter.TaskIDs {
+		ids[id] = struct{}{}
+	}
+	now := time.Now()
+
+	evicted := r.cache.DeleteFunc(func(info CacheEntryInfo) bool {
+		if len(ids) > 0 {
+			if _, ok := ids[info.Key]; !ok {
+				return false
+			}
+		}
+		if filter.Status != "" && info.Task.Status != filter.Status {
+			return false
+		}
+		if filter.OlderThan > 0 && now.Sub(info.CachedAt) < filter.OlderThan {
+			return false
+		}
+		return true
+	})
+
+	span.SetAttributes(attribute.Int("evicted", evicted))
+	l.Info("cache entries invalidated", zap.Int("evicted", evicted))
+
+	return evicted, nil
+}
+
+func (r *CachedTaskStorage) Summary(ctx context.Context) (domain.CacheSummary, error) {
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "CachedTaskStorage.Summary")
+	defer span.End()
+
+	stats := r.cache.Stats()
+	summary := domain.CacheSummary{
+		Entries:  stats.Entries,
+		Bytes:    stats.Bytes,
+		MaxBytes: r.cache.MaxBytes(),
+	}
+
+	if oldest, ok := r.cache.Oldest(); ok {
+		summary.OldestTaskID = oldest.Key
+		summary.OldestCachedAt = oldest.CachedAt
+	}
+
+	return summary, nil
+}
+
+func (r *CachedTaskStorage) FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error) {
+	finder, ok := r.next.(FingerprintFinder)
+	if !ok {
+		return nil, false, nil
+	}
+	return finder.FindByFingerprint(ctx, fingerprint)
+}
+
+func (r *CachedTaskStorage) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return domain.IdempotencyRecord{}, false, ErrIdempotencyNotSupported
+	}
+	return store.ReserveIdempotencyKey(ctx, record)
+}
+
+func (r *CachedTaskStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return ErrIdempotencyNotSupported
+	}
+	return store.ReleaseIdempotencyKey(ctx, key)
+}
+
+func (r *CachedTaskStorage) LoadResult(ctx context.Context, task *domain.Task) error {
+	loader, ok := r.next.(ResultLoader)
+	if !ok {
+		if task.ResultRef != nil && task.Result == nil {
+			return ErrResultNotFound
+		}
+		return nil
+	}
+	return loader.LoadResult(ctx, task)
+}
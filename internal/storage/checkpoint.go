@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// checkpoint - снимок необработанных задач, сохраненный при остановке сервиса
+type checkpoint struct {
+	SavedAt time.Time      `json:"saved_at"`
+	Tasks   []*journalTask `json:"tasks"`
+}
+
+// WriteCheckpoint атомарно сохраняет необработанные задачи в файл, чтобы
+// поставить их в очередь при следующем запуске. Пустой список удаляет файл.
+func WriteCheckpoint(ctx context.Context, path string, tasks []*domain.Task) error {
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "Checkpoint.Write")
+	defer span.End()
+	span.SetAttributes(attribute.Int("tasks", len(tasks)))
+
+	if len(tasks) == 0 {
+		return RemoveCheckpoint(path)
+	}
+
+	state := checkpoint{SavedAt: time.Now(), Tasks: make([]*journalTask, 0, len(tasks))}
+	for _, task := range tasks {
+		state.Tasks = append(state.Tasks, newJournalTask(task))
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("create checkpoint dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".part-*")
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		span.RecordError(err)
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		span.RecordError(err)
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("close checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint читает задачи, сохраненные WriteCheckpoint. Отсутствие файла
+// не считается ошибкой. Задачи, чей входной файл пропал, помечаются как failed.
+func LoadCheckpoint(ctx context.Context, path string) ([]*domain.Task, error) {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "Checkpoint.Load")
+	defer span.End()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var state checkpoint
+	if err := json.Unmarshal(data, &state); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("decode checkpoint: %w", err)
+	}
+
+	tasks := make([]*domain.Task, 0, len(state.Tasks))
+	for _, record := range state.Tasks {
+		if record == nil {
+			continue
+		}
+		task := record.toDomain()
+		if task.FilePath != "" {
+			if _, err := os.Stat(task.FilePath); err != nil {
+				l.Warn("input file of checkpointed task is missing", zap.String("task_id", task.ID), zap.String("file_path", task.FilePath))
+				task.Status = domain.StatusFailed
+				task.Error = lostInputError
+			}
+		}
+		tasks = append(tasks, task)
+	}
+
+	span.SetAttributes(attribute.Int("tasks", len(tasks)))
+	l.Info("checkpoint loaded", zap.String("path", path), zap.Int("tasks", len(tasks)), zap.Time("saved_at", state.SavedAt))
+	return tasks, nil
+}
+
+// RemoveCheckpoint удаляет файл снимка после того, как задачи снова поставлены в очередь
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove checkpoint: %w", err)
+	}
+	return nil
+}
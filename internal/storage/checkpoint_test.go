@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func TestCheckpoint_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.checkpoint")
+	ctx := context.Background()
+
+	inputPath := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(inputPath, []byte("кот\nток\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tasks := []*domain.Task{
+		{ID: "words", Status: domain.StatusProcessing, Words: []string{"рост", "торс"}, CaseSensitive: true, Priority: domain.PriorityHigh, Attempts: 1, CreatedAt: time.Now(), Version: 3},
+		{ID: "file", Status: domain.StatusProcessing, FilePath: inputPath, CreatedAt: time.Now(), Version: 1},
+		{ID: "lost", Status: domain.StatusProcessing, FilePath: filepath.Join(dir, "missing.txt"), CreatedAt: time.Now(), Version: 1},
+	}
+	if err := WriteCheckpoint(ctx, path, tasks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(ctx, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(loaded))
+	}
+
+	words := loaded[0]
+	if words.ID != "words" || words.Status != domain.StatusProcessing || len(words.Words) != 2 || !words.CaseSensitive ||
+		words.Priority != domain.PriorityHigh || words.Attempts != 1 || words.Version != 3 {
+		t.Errorf("unexpected restored task: %+v", words)
+	}
+	if loaded[1].FilePath != inputPath || loaded[1].Status != domain.StatusProcessing {
+		t.Errorf("expected file task to keep its input, got %+v", loaded[1])
+	}
+	if loaded[2].Status != domain.StatusFailed || loaded[2].Error != lostInputError {
+		t.Errorf("expected task with missing input to fail, got %+v", loaded[2])
+	}
+}
+
+func TestCheckpoint_EmptyRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.checkpoint")
+	ctx := context.Background()
+
+	if err := WriteCheckpoint(ctx, path, []*domain.Task{{ID: "t1", Status: domain.StatusProcessing}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteCheckpoint(ctx, path, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected checkpoint to be removed, got %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(ctx, path)
+	if err != nil || loaded != nil {
+		t.Fatalf("expected no tasks without checkpoint, got %v, %v", loaded, err)
+	}
+}
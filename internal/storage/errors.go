@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound - задачи с таким ID нет в хранилище
+	ErrNotFound = errors.New("task not found")
+	// ErrVersionConflict - задача была изменена параллельно
+	ErrVersionConflict = errors.New("task version conflict")
+	// ErrUnavailable - хранилище временно не может выполнить операцию (сбой диска, журнала)
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// unavailable помечает сбой нижележащего хранилища как ErrUnavailable, сохраняя исходную ошибку
+func unavailable(op string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrUnavailable, op, err)
+}
+
+// VersionConflictError возвращается из Save, если задача была изменена
+// после того, как ее прочитал вызывающий код
+type VersionConflictError struct {
+	TaskID   string
+	Expected uint64
+	Actual   uint64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("task %s: expected version %d, got %d", e.TaskID, e.Expected, e.Actual)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
@@ -10,3 +10,28 @@ type TaskStorage interface {
 	Save(ctx context.Context, task *domain.Task) error
 	GetByID(ctx context.Context, id string) (*domain.Task, error)
 }
+
+type TaskLister interface {
+	List(ctx context.Context) ([]*domain.Task, error)
+}
+
+type TaskDeleter interface {
+	// Delete удаляет задачу. Удаление отсутствующей задачи не считается ошибкой
+	Delete(ctx context.Context, id string) error
+}
+
+type FingerprintFinder interface {
+	FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error)
+}
+
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey сохраняет запись, если для ключа нет действующей,
+	// иначе возвращает уже существующую запись и false.
+	ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+type ResultLoader interface {
+	// LoadResult подгружает в задачу результат, вынесенный из хранилища метаданных
+	LoadResult(ctx context.Context, task *domain.Task) error
+}
@@ -0,0 +1,639 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var _ TaskStorage = (*JournaledTaskStorage)(nil)
+var _ IdempotencyStore = (*JournaledTaskStorage)(nil)
+var _ TaskDeleter = (*JournaledTaskStorage)(nil)
+
+const lostInputError = "task input was lost during restart"
+
+var errJournalClosed = errors.New("journal is closed")
+
+const (
+	eventIdempotencyReserved = "idempotency_reserved"
+	eventIdempotencyReleased = "idempotency_released"
+	eventTaskDeleted         = "deleted"
+	eventTaskProgress        = "progress"
+)
+
+// journalRecord - одна строка журнала: снимок задачи в момент сохранения
+// либо изменение ключа идемпотентности
+type journalRecord struct {
+	Event       string                    `json:"event"`
+	At          time.Time                 `json:"at"`
+	Task        *journalTask              `json:"task,omitempty"`
+	Idempotency *domain.IdempotencyRecord `json:"idempotency,omitempty"`
+}
+
+// journalState - состояние, восстановленное из журнала
+type journalState struct {
+	tasks map[string]*domain.Task
+	order []string
+	keys  map[string]domain.IdempotencyRecord
+}
+
+type journalTask struct {
+	ID               string               `json:"id"`
+	Status           domain.TaskStatus    `json:"status"`
+	Words            []string             `json:"words,omitempty"`
+	FilePath         string               `json:"file_path,omitempty"`
+	CaseSensitive    bool                 `json:"case_sensitive,omitempty"`
+	Priority         domain.TaskPriority  `json:"priority,omitempty"`
+	ClientID         string               `json:"client_id,omitempty"`
+	Fingerprint      string               `json:"fingerprint,omitempty"`
+	Result           [][]string           `json:"result,omitempty"`
+	ResultRef        *domain.ResultRef    `json:"result_ref,omitempty"`
+	Error            string               `json:"error,omitempty"`
+	Attempts         int                  `json:"attempts,omitempty"`
+	LastError        string               `json:"last_error,omitempty"`
+	Progress         *domain.TaskProgress `json:"progress,omitempty"`
+	RunAt            *time.Time           `json:"run_at,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	ProcessingTimeMS int64                `json:"processing_time_ms,omitempty"`
+	GroupsCount      int                  `json:"groups_count,omitempty"`
+	TraceContext     map[string]string    `json:"trace_context,omitempty"`
+	Version          uint64               `json:"version,omitempty"`
+}
+
+func newJournalTask(task *domain.Task) *journalTask {
+	return &journalTask{
+		ID:               task.ID,
+		Status:           task.Status,
+		Words:            task.Words,
+		FilePath:         task.FilePath,
+		CaseSensitive:    task.CaseSensitive,
+		Priority:         task.Priority,
+		ClientID:         task.ClientID,
+		Fingerprint:      task.Fingerprint,
+		Result:           task.Result,
+		ResultRef:        task.ResultRef,
+		Error:            task.Error,
+		Attempts:         task.Attempts,
+		LastError:        task.LastError,
+		Progress:         task.Progress,
+		RunAt:            task.RunAt,
+		CreatedAt:        task.CreatedAt,
+		ProcessingTimeMS: task.ProcessingTimeMS,
+		GroupsCount:      task.GroupsCount,
+		TraceContext:     task.TraceContext,
+		Version:          task.Version,
+	}
+}
+
+func (jt journalTask) toDomain() *domain.Task {
+	traceContext := jt.TraceContext
+	if traceContext == nil {
+		traceContext = make(map[string]string)
+	}
+
+	return &domain.Task{
+		ID:               jt.ID,
+		Status:           jt.Status,
+		Words:            jt.Words,
+		FilePath:         jt.FilePath,
+		CaseSensitive:    jt.CaseSensitive,
+		Priority:         jt.Priority,
+		ClientID:         jt.ClientID,
+		Fingerprint:      jt.Fingerprint,
+		Result:           jt.Result,
+		ResultRef:        jt.ResultRef,
+		Error:            jt.Error,
+		Attempts:         jt.Attempts,
+		LastError:        jt.LastError,
+		Progress:         jt.Progress,
+		RunAt:            jt.RunAt,
+		CreatedAt:        jt.CreatedAt,
+		ProcessingTimeMS: jt.ProcessingTimeMS,
+		GroupsCount:      jt.GroupsCount,
+		TraceContext:     traceContext,
+		Version:          jt.Version,
+	}
+}
+
+// MarshalTask кодирует задачу вместе с полями, скрытыми из ответов API,
+// в формате записей журнала
+func MarshalTask(task *domain.Task) ([]byte, error) {
+	return json.Marshal(newJournalTask(task))
+}
+
+// UnmarshalTask восстанавливает задачу, закодированную MarshalTask
+func UnmarshalTask(data []byte) (*domain.Task, error) {
+	var jt journalTask
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return nil, err
+	}
+	return jt.toDomain(), nil
+}
+
+// Journal - журнал событий жизненного цикла задач на локальном диске,
+// в который дописываются снимки задач при каждом сохранении.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+	sync bool
+
+	// size - текущий размер файла; при достижении compactAt журнал
+	// сжимается до последних снимков задач
+	size      int64
+	maxBytes  int64
+	compactAt int64
+}
+
+// OpenJournal воспроизводит существующий журнал в target, сжимает его до
+// последних снимков задач и открывает на дозапись. Возвращает незавершенные
+// задачи, которые нужно снова поставить в очередь.
+func OpenJournal(ctx context.Context, path string, syncWrites bool, target TaskStorage) (*Journal, []*domain.Task, error) {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("repository")
+	ctx, span := tr.Start(ctx, "Journal.Open")
+	defer span.End()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("create journal dir: %w", err)
+	}
+
+	state, err := readJournal(ctx, path)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	var pending []*domain.Task
+	snapshot := make([]*domain.Task, 0, len(state.order))
+	for _, id := range state.order {
+		task := state.tasks[id]
+
+		if (task.Status == domain.StatusProcessing || task.Status == domain.StatusScheduled) && task.FilePath != "" {
+			if _, err := os.Stat(task.FilePath); err != nil {
+				l.Warn("input file of unfinished task is missing", zap.String("task_id", task.ID), zap.String("file_path", task.FilePath))
+				task.Status = domain.StatusFailed
+				task.Error = lostInputError
+			}
+		}
+
+		if err := target.Save(ctx, task); err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("restore task %s: %w", task.ID, err)
+		}
+
+		if task.Status == domain.StatusProcessing {
+			pending = append(pending, task)
+		}
+		snapshot = append(snapshot, task)
+	}
+
+	keys := make([]domain.IdempotencyRecord, 0, len(state.keys))
+	if store, ok := target.(IdempotencyStore); ok {
+		now := time.Now()
+		for _, record := range state.keys {
+			if record.Expired(now) {
+				continue
+			}
+			if _, _, err := store.ReserveIdempotencyKey(ctx, record); err != nil {
+				span.RecordError(err)
+				return nil, nil, fmt.Errorf("restore idempotency key: %w", err)
+			}
+			keys = append(keys, record)
+		}
+	}
+
+	if err := compactJournal(path, snapshot, keys); err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	file, size, err := openJournalFile(path)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	span.SetAttributes(attribute.Int("restored", len(snapshot)), attribute.Int("pending", len(pending)))
+	l.Info("journal replayed", zap.String("path", path), zap.Int("restored", len(snapshot)), zap.Int("pending", len(pending)))
+
+	return &Journal{file: file, path: path, sync: syncWrites, size: size}, pending, nil
+}
+
+func openJournalFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open journal: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("stat journal: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// SetMaxBytes задает размер, при достижении которого журнал сжимается во время
+// работы, а не только при запуске. 0 - сжимать только при запуске.
+func (j *Journal) SetMaxBytes(maxBytes int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.maxBytes = maxBytes
+	j.compactAt = max(maxBytes, 2*j.size)
+}
+
+func (j *Journal) Append(task *domain.Task) error {
+	return j.appendRecord(journalRecord{
+		Event: string(task.Status),
+		At:    time.Now(),
+		Task:  newJournalTask(task),
+	})
+}
+
+func (j *Journal) appendRecord(record journalRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return unavailable("append journal record", errJournalClosed)
+	}
+
+	if _, err := j.file.Write(line); err != nil {
+		return unavailable("write journal record", err)
+	}
+	if j.sync {
+		if err := j.file.Sync(); err != nil {
+			return unavailable("sync journal", err)
+		}
+	}
+	j.size += int64(len(line))
+
+	if j.maxBytes > 0 && j.size >= j.compactAt {
+		// запись уже в журнале, поэтому ошибка сжатия ее не отменяет
+		if err := j.compactLocked(); err != nil {
+			logger.FromContext(context.Background()).Warn("failed to compact journal", zap.String("path", j.path), zap.Error(err))
+		}
+		// если живых задач много, сжатый журнал остается большим: следующее
+		// сжатие откладывается, чтобы не повторять его на каждой записи
+		j.compactAt = max(j.maxBytes, 2*j.size)
+	}
+
+	return nil
+}
+
+// compactLocked переписывает журнал последними снимками задач и действующими
+// ключами идемпотентности и продолжает дозапись в новый файл
+func (j *Journal) compactLocked() error {
+	state, err := readJournal(context.Background(), j.path)
+	if err != nil {
+		return err
+	}
+
+	tasks := make([]*domain.Task, 0, len(state.order))
+	for _, id := range state.order {
+		tasks = append(tasks, state.tasks[id])
+	}
+	keys := make([]domain.IdempotencyRecord, 0, len(state.keys))
+	now := time.Now()
+	for _, record := range state.keys {
+		if !record.Expired(now) {
+			keys = append(keys, record)
+		}
+	}
+
+	if err := compactJournal(j.path, tasks, keys); err != nil {
+		return err
+	}
+
+	file, size, err := openJournalFile(j.path)
+	if err != nil {
+		return err
+	}
+	_ = j.file.Close()
+	j.file = file
+	j.size = size
+	return nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func readJournal(ctx context.Context, path string) (*journalState, error) {
+	l := logger.FromContext(ctx)
+
+	state := &journalState{
+		tasks: make(map[string]*domain.Task),
+		keys:  make(map[string]domain.IdempotencyRecord),
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineNo := 0
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNo++
+
+			var record journalRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				// хвост записи может быть оборван при падении процесса
+				l.Warn("skipping corrupted journal record", zap.Int("line", lineNo), zap.Error(err))
+			} else {
+				state.apply(record)
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("read journal: %w", readErr)
+		}
+	}
+
+	return state, nil
+}
+
+func (s *journalState) apply(record journalRecord) {
+	switch {
+	case record.Event == eventIdempotencyReserved && record.Idempotency != nil:
+		s.keys[record.Idempotency.Key] = *record.Idempotency
+	case record.Event == eventIdempotencyReleased && record.Idempotency != nil:
+		delete(s.keys, record.Idempotency.Key)
+	case record.Event == eventTaskProgress && record.Task != nil:
+		if task, seen := s.tasks[record.Task.ID]; seen {
+			task.Progress = record.Task.Progress
+			task.Version = record.Task.Version
+		}
+	case record.Event == eventTaskDeleted && record.Task != nil:
+		if _, seen := s.tasks[record.Task.ID]; seen {
+			delete(s.tasks, record.Task.ID)
+			s.order = slices.DeleteFunc(s.order, func(id string) bool { return id == record.Task.ID })
+		}
+	case record.Task != nil:
+		if _, seen := s.tasks[record.Task.ID]; !seen {
+			s.order = append(s.order, record.Task.ID)
+		}
+		s.tasks[record.Task.ID] = record.Task.toDomain()
+	}
+}
+
+func compactJournal(path string, tasks []*domain.Task, keys []domain.IdempotencyRecord) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".compact-*")
+	if err != nil {
+		return fmt.Errorf("create compacted journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(w)
+	now := time.Now()
+	for _, task := range tasks {
+		if err := encoder.Encode(journalRecord{Event: string(task.Status), At: now, Task: newJournalTask(task)}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("write compacted journal: %w", err)
+		}
+	}
+	for i := range keys {
+		if err := encoder.Encode(journalRecord{Event: eventIdempotencyReserved, At: now, Idempotency: &keys[i]}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("write compacted journal: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write compacted journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close compacted journal: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace journal: %w", err)
+	}
+	return nil
+}
+
+// JournaledTaskStorage дописывает каждое сохранение задачи в журнал
+// перед тем, как передать его в следующее хранилище.
+type JournaledTaskStorage struct {
+	next    TaskStorage
+	journal *Journal
+
+	// mu упорядочивает сохранения: проверка версии, запись в журнал и
+	// применение изменения выполняются без параллельных сохранений
+	mu sync.Mutex
+}
+
+func NewJournaledTaskStorage(next TaskStorage, journal *Journal) *JournaledTaskStorage {
+	return &JournaledTaskStorage{
+		next:    next,
+		journal: journal,
+	}
+}
+
+func (r *JournaledTaskStorage) Save(ctx context.Context, task *domain.Task) error {
+	tr := otel.Tracer("repository")
+	ctx, span := tr.Start(ctx, "JournaledTaskStorage.Save")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.next.GetByID(ctx, task.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		return err
+	}
+
+	// версия проверяется до записи в журнал, чтобы отклоненные изменения
+	// не попали в журнал и не применились при восстановлении
+	version := task.Version
+	switch {
+	case current != nil && current.Version != task.Version:
+		err := &VersionConflictError{TaskID: task.ID, Expected: task.Version, Actual: current.Version}
+		span.RecordError(err)
+		return err
+	case current != nil:
+		version++
+	case version == 0:
+		version = 1
+	}
+
+	record := journalRecord{Event: string(task.Status), At: time.Now(), Task: newJournalTask(task)}
+	if current != nil && progressOnly(current, task) {
+		// прогресс сохраняется часто, поэтому полный снимок задачи не пишется
+		record.Event = eventTaskProgress
+		record.Task = &journalTask{ID: task.ID, Status: task.Status, Progress: task.Progress}
+	}
+	record.Task.Version = version
+
+	if err := r.journal.appendRecord(record); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := r.next.Save(ctx, task); err != nil {
+		span.RecordError(err)
+		r.rollback(ctx, task.ID, current)
+		return err
+	}
+
+	return nil
+}
+
+// rollback отменяет в журнале изменение, которое не удалось применить,
+// дописывая прежний снимок задачи
+func (r *JournaledTaskStorage) rollback(ctx context.Context, id string, previous *domain.Task) {
+	record := journalRecord{Event: eventTaskDeleted, At: time.Now(), Task: &journalTask{ID: id}}
+	if previous != nil {
+		record = journalRecord{Event: string(previous.Status), At: time.Now(), Task: newJournalTask(previous)}
+	}
+	if err := r.journal.appendRecord(record); err != nil {
+		logger.FromContext(ctx).Error("failed to roll back journal record", zap.String("task_id", id), zap.Error(err))
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// progressOnly сообщает, что сохранение меняет только прогресс обработки задачи
+func progressOnly(current, task *domain.Task) bool {
+	return current.Status == task.Status &&
+		current.Attempts == task.Attempts &&
+		current.Error == task.Error &&
+		current.LastError == task.LastError &&
+		current.FilePath == task.FilePath &&
+		current.Priority == task.Priority &&
+		sameTime(current.RunAt, task.RunAt) &&
+		len(current.Words) == len(task.Words) &&
+		current.Result == nil && task.Result == nil &&
+		current.ResultRef == nil && task.ResultRef == nil &&
+		current.Progress != nil && task.Progress != nil
+}
+
+func (r *JournaledTaskStorage) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *JournaledTaskStorage) List(ctx context.Context) ([]*domain.Task, error) {
+	lister, ok := r.next.(TaskLister)
+	if !ok {
+		return nil, ErrListNotSupported
+	}
+	return lister.List(ctx)
+}
+
+func (r *JournaledTaskStorage) FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error) {
+	finder, ok := r.next.(FingerprintFinder)
+	if !ok {
+		return nil, false, nil
+	}
+	return finder.FindByFingerprint(ctx, fingerprint)
+}
+
+func (r *JournaledTaskStorage) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return domain.IdempotencyRecord{}, false, ErrIdempotencyNotSupported
+	}
+
+	existing, reserved, err := store.ReserveIdempotencyKey(ctx, record)
+	if err != nil || !reserved {
+		return existing, reserved, err
+	}
+
+	if err := r.journal.appendRecord(journalRecord{Event: eventIdempotencyReserved, At: time.Now(), Idempotency: &record}); err != nil {
+		_ = store.ReleaseIdempotencyKey(ctx, record.Key)
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	return existing, true, nil
+}
+
+func (r *JournaledTaskStorage) Delete(ctx context.Context, id string) error {
+	deleter, ok := r.next.(TaskDeleter)
+	if !ok {
+		return ErrDeleteNotSupported
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := deleter.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return r.journal.appendRecord(journalRecord{
+		Event: eventTaskDeleted,
+		At:    time.Now(),
+		Task:  &journalTask{ID: id},
+	})
+}
+
+func (r *JournaledTaskStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return ErrIdempotencyNotSupported
+	}
+
+	if err := store.ReleaseIdempotencyKey(ctx, key); err != nil {
+		return err
+	}
+
+	return r.journal.appendRecord(journalRecord{
+		Event:       eventIdempotencyReleased,
+		At:          time.Now(),
+		Idempotency: &domain.IdempotencyRecord{Key: key},
+	})
+}
@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func openTestJournal(t *testing.T, path string) (*JournaledTaskStorage, *InMemoryStorage, []*domain.Task) {
+	t.Helper()
+
+	base := NewInMemoryStorage()
+	journal, pending, err := OpenJournal(context.Background(), path, false, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = journal.Close() })
+
+	return NewJournaledTaskStorage(base, journal), base, pending
+}
+
+func TestJournal_ReplayRestoresTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	store, _, pending := openTestJournal(t, path)
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending tasks in empty journal, got %d", len(pending))
+	}
+
+	done := &domain.Task{ID: "done", Status: domain.StatusProcessing, Words: []string{"кот", "ток"}, CreatedAt: time.Now()}
+	queued := &domain.Task{ID: "queued", Status: domain.StatusProcessing, Words: []string{"рост", "торс"}, CaseSensitive: true, CreatedAt: time.Now()}
+	for _, task := range []*domain.Task{done, queued} {
+		if err := store.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	done.Status = domain.StatusCompleted
+	done.Result = [][]string{{"кот", "ток"}}
+	done.GroupsCount = 1
+	if err := store.Save(ctx, done); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = store.journal.Close()
+
+	_, base, pending := openTestJournal(t, path)
+
+	restored, err := base.GetByID(ctx, "done")
+	if err != nil {
+		t.Fatalf("expected completed task to be restored: %v", err)
+	}
+	if restored.Status != domain.StatusCompleted || restored.GroupsCount != 1 || len(restored.Result) != 1 {
+		t.Errorf("unexpected restored task: %+v", restored)
+	}
+	if restored.Version != 2 {
+		t.Errorf("expected version 2 to survive restart, got %d", restored.Version)
+	}
+
+	if len(pending) != 1 || pending[0].ID != "queued" {
+		t.Fatalf("expected queued task to be pending, got %+v", pending)
+	}
+	if !pending[0].CaseSensitive || len(pending[0].Words) != 2 {
+		t.Errorf("expected pending task input to be restored, got %+v", pending[0])
+	}
+}
+
+func TestJournal_MissingInputFileFailsTask(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.journal")
+	ctx := context.Background()
+
+	kept := filepath.Join(dir, "kept.txt")
+	if err := os.WriteFile(kept, []byte("кот\nток\n"), 0o644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	store, _, _ := openTestJournal(t, path)
+	_ = store.Save(ctx, &domain.Task{ID: "kept", Status: domain.StatusProcessing, FilePath: kept})
+	_ = store.Save(ctx, &domain.Task{ID: "lost", Status: domain.StatusProcessing, FilePath: filepath.Join(dir, "lost.txt")})
+	_ = store.journal.Close()
+
+	_, base, pending := openTestJournal(t, path)
+
+	if len(pending) != 1 || pending[0].ID != "kept" {
+		t.Fatalf("expected only task with existing file to be pending, got %+v", pending)
+	}
+
+	lost, err := base.GetByID(ctx, "lost")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lost.Status != domain.StatusFailed || lost.Error != lostInputError {
+		t.Errorf("expected lost task to be failed, got %+v", lost)
+	}
+}
+
+func TestJournal_SkipsCorruptedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	store, _, _ := openTestJournal(t, path)
+	_ = store.Save(ctx, &domain.Task{ID: "1", Status: domain.StatusProcessing, Words: []string{"a"}})
+	_ = store.journal.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	_, _ = file.WriteString(`{"event":"processing","task":{"id":"2"`)
+	_ = file.Close()
+
+	_, base, pending := openTestJournal(t, path)
+
+	if len(pending) != 1 || pending[0].ID != "1" {
+		t.Fatalf("expected task 1 to be pending, got %+v", pending)
+	}
+	if _, err := base.GetByID(ctx, "2"); err == nil {
+		t.Error("expected corrupted record to be skipped")
+	}
+}
+
+func TestJournal_PersistsIdempotencyKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	store, _, _ := openTestJournal(t, path)
+	for _, key := range []string{"kept", "released"} {
+		record := domain.IdempotencyRecord{Key: key, TaskID: "task-" + key, RequestHash: "hash", ExpiresAt: expiresAt}
+		if _, _, err := store.ReserveIdempotencyKey(ctx, record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	_, _, _ = store.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "expired", ExpiresAt: time.Now().Add(-time.Second)})
+	if err := store.ReleaseIdempotencyKey(ctx, "released"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = store.journal.Close()
+
+	_, base, _ := openTestJournal(t, path)
+
+	existing, reserved, _ := base.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "kept", TaskID: "other"})
+	if reserved || existing.TaskID != "task-kept" || existing.RequestHash != "hash" {
+		t.Errorf("expected key to survive restart, got %+v", existing)
+	}
+	if _, reserved, _ := base.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "released"}); !reserved {
+		t.Error("expected released key to stay released after restart")
+	}
+	if _, reserved, _ := base.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "expired"}); !reserved {
+		t.Error("expected expired key to be dropped on restart")
+	}
+}
+
+func TestJournal_ClosedJournalIsUnavailable(t *testing.T) {
+	store, _, _ := openTestJournal(t, filepath.Join(t.TempDir(), "tasks.journal"))
+	_ = store.journal.Close()
+
+	err := store.Save(context.Background(), &domain.Task{ID: "1", Status: domain.StatusProcessing})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestJournal_PersistsDeletes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	store, _, _ := openTestJournal(t, path)
+	for _, id := range []string{"kept", "deleted"} {
+		if err := store.Save(ctx, &domain.Task{ID: id, Status: domain.StatusProcessing, Words: []string{"кот"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := store.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = store.journal.Close()
+
+	_, base, pending := openTestJournal(t, path)
+
+	if _, err := base.GetByID(ctx, "deleted"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleted task to stay deleted after restart, got %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "kept" {
+		t.Errorf("expected only kept task to be pending, got %+v", pending)
+	}
+}
+
+type failingSaveStorage struct {
+	*InMemoryStorage
+	err error
+}
+
+func (s *failingSaveStorage) Save(ctx context.Context, task *domain.Task) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.InMemoryStorage.Save(ctx, task)
+}
+
+func TestJournal_RejectedSavesAreNotReplayed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	base := &failingSaveStorage{InMemoryStorage: NewInMemoryStorage()}
+	journal, _, err := OpenJournal(ctx, path, false, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := NewJournaledTaskStorage(base, journal)
+
+	task := &domain.Task{ID: "1", Status: domain.StatusProcessing, Words: []string{"кот"}}
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale := task.Clone()
+	stale.Version = 0
+	stale.Status = domain.StatusFailed
+	if err := store.Save(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+
+	base.err = errors.New("storage failed")
+	failed := task.Clone()
+	failed.Status = domain.StatusCompleted
+	if err := store.Save(ctx, failed); !errors.Is(err, base.err) {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+	if err := store.Save(ctx, &domain.Task{ID: "2", Status: domain.StatusProcessing}); !errors.Is(err, base.err) {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+	_ = journal.Close()
+
+	_, restored, pending := openTestJournal(t, path)
+
+	got, err := restored.GetByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("expected task to be restored: %v", err)
+	}
+	if got.Status != domain.StatusProcessing || got.Version != 1 {
+		t.Errorf("expected rejected saves to be skipped on replay, got status %s version %d", got.Status, got.Version)
+	}
+	if _, err := restored.GetByID(ctx, "2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected failed new task to be rolled back, got %v", err)
+	}
+	if len(pending) != 1 {
+		t.Errorf("expected one pending task, got %d", len(pending))
+	}
+}
+
+func TestJournal_ProgressSavesAreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	store, _, _ := openTestJournal(t, path)
+
+	words := make([]string, 1000)
+	for i := range words {
+		words[i] = "абракадабра"
+	}
+	task := &domain.Task{ID: "1", Status: domain.StatusProcessing, Words: words, Progress: &domain.TaskProgress{TotalWords: len(words)}}
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot := store.journal.size
+
+	for processed := 100; processed <= 500; processed += 100 {
+		task.Progress = &domain.TaskProgress{WordsProcessed: processed, TotalWords: len(words)}
+		if err := store.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if grown := store.journal.size - snapshot; grown >= snapshot {
+		t.Errorf("expected progress records to be smaller than one snapshot, journal grew by %d bytes (snapshot %d)", grown, snapshot)
+	}
+	_ = store.journal.Close()
+
+	_, base, pending := openTestJournal(t, path)
+
+	restored, err := base.GetByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("expected task to be restored: %v", err)
+	}
+	if restored.Progress == nil || restored.Progress.WordsProcessed != 500 || restored.Version != 6 {
+		t.Errorf("expected last progress and version to be restored, got %+v version %d", restored.Progress, restored.Version)
+	}
+	if len(pending) != 1 || len(pending[0].Words) != len(words) {
+		t.Errorf("expected task input to survive progress records, got %+v", pending)
+	}
+}
+
+func TestJournal_CompactsWhenOverMaxBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	ctx := context.Background()
+
+	store, _, _ := openTestJournal(t, path)
+	store.journal.SetMaxBytes(4096)
+
+	task := &domain.Task{ID: "1", Status: domain.StatusProcessing, Words: []string{"кот", "ток"}}
+	for attempt := 1; attempt <= 200; attempt++ {
+		task.Attempts = attempt
+		if err := store.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Size() >= 8192 {
+		t.Errorf("expected journal to be compacted below 8192 bytes, got %d", info.Size())
+	}
+	if info.Size() != store.journal.size {
+		t.Errorf("expected tracked size %d to match file size %d", store.journal.size, info.Size())
+	}
+	_ = store.journal.Close()
+
+	_, base, _ := openTestJournal(t, path)
+
+	restored, err := base.GetByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("expected task to be restored: %v", err)
+	}
+	if restored.Attempts != 200 || restored.Version != 200 {
+		t.Errorf("expected last snapshot to survive compaction, got attempts %d version %d", restored.Attempts, restored.Version)
+	}
+}
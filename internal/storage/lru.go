@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+const (
+	taskOverheadBytes = 256
+	sliceHeaderBytes  = 24
+	stringHeaderBytes = 16
+)
+
+// CacheStats содержит счетчики работы кэша результатов
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Bytes     uint64
+	Entries   uint64
+}
+
+// CacheEntryInfo описывает запись кэша без копирования результата
+type CacheEntryInfo struct {
+	Key      string
+	Task     *domain.Task
+	Size     int64
+	CachedAt time.Time
+}
+
+type lruEntry struct {
+	key       string
+	task      *domain.Task
+	size      int64
+	cachedAt  time.Time
+	expiresAt time.Time
+}
+
+// LRUCache ограничивает кэш задач по приблизительному объему памяти,
+// вытесняя давно не использованные записи.
+type LRUCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	ttl      time.Duration
+	bytes    int64
+	ll       *list.List
+	items    map[string]*list.Element
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewLRUCache(maxBytes int64, ttl, cleanupInterval time.Duration) *LRUCache {
+	c := &LRUCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		stop:     make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 {
+		go c.janitor(cleanupInterval)
+	}
+
+	return c
+}
+
+func (c *LRUCache) Get(key string) (*domain.Task, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if c.expired(entry, time.Now()) {
+		c.removeElement(elem)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	c.hits.Add(1)
+	return entry.task, true
+}
+
+func (c *LRUCache) Set(key string, task *domain.Task) {
+	size := TaskSize(task)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	now := time.Now()
+	entry := &lruEntry{key: key, task: task, size: size, cachedAt: now}
+	if c.ttl > 0 {
+		entry.expiresAt = now.Add(c.ttl)
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	c.bytes += size
+
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *LRUCache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	c.removeElement(elem)
+	return true
+}
+
+func (c *LRUCache) DeleteFunc(match func(info CacheEntryInfo) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := 0
+	for elem := c.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		if match(elem.Value.(*lruEntry).info()) {
+			c.removeElement(elem)
+			deleted++
+		}
+		elem = prev
+	}
+
+	return deleted
+}
+
+func (c *LRUCache) Oldest() (CacheEntryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var oldest *lruEntry
+	for elem := c.ll.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*lruEntry)
+		if oldest == nil || entry.cachedAt.Before(oldest.cachedAt) {
+			oldest = entry
+		}
+	}
+
+	if oldest == nil {
+		return CacheEntryInfo{}, false
+	}
+	return oldest.info(), true
+}
+
+func (c *LRUCache) MaxBytes() int64 {
+	return c.maxBytes
+}
+
+func (c *LRUCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.bytes = 0
+}
+
+func (c *LRUCache) DeleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		if c.expired(elem.Value.(*lruEntry), now) {
+			c.removeElement(elem)
+		}
+		elem = prev
+	}
+}
+
+func (c *LRUCache) Stats() CacheStats {
+	c.mu.Lock()
+	bytes := c.bytes
+	entries := c.ll.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Bytes:     uint64(bytes),
+		Entries:   uint64(entries),
+	}
+}
+
+func (c *LRUCache) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *LRUCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (e *lruEntry) info() CacheEntryInfo {
+	return CacheEntryInfo{
+		Key:      e.key,
+		Task:     e.task,
+		Size:     e.size,
+		CachedAt: e.cachedAt,
+	}
+}
+
+func (c *LRUCache) expired(entry *lruEntry, now time.Time) bool {
+	return !entry.expiresAt.IsZero() && now.After(entry.expiresAt)
+}
+
+func (c *LRUCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	c.ll.Remove(elem)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// TaskSize приблизительно оценивает объем памяти, занимаемый задачей
+// вместе с результатом группировки.
+func TaskSize(task *domain.Task) int64 {
+	size := int64(taskOverheadBytes + len(task.ID) + len(task.Error) + len(task.FilePath))
+
+	size += ResultSize(task.Result)
+
+	for _, word := range task.Words {
+		size += int64(stringHeaderBytes + len(word))
+	}
+
+	return size
+}
+
+// ResultSize оценивает объем памяти, занимаемый результатом группировки
+func ResultSize(result [][]string) int64 {
+	size := int64(sliceHeaderBytes)
+	for _, group := range result {
+		size += sliceHeaderBytes
+		for _, word := range group {
+			size += int64(stringHeaderBytes + len(word))
+		}
+	}
+	return size
+}
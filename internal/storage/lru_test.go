@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	task1 := &domain.Task{ID: "1"}
+	task2 := &domain.Task{ID: "2"}
+	task3 := &domain.Task{ID: "3"}
+
+	c := NewLRUCache(TaskSize(task1)+TaskSize(task2), time.Minute, 0)
+	c.Set(task1.ID, task1)
+	c.Set(task2.ID, task2)
+
+	if _, ok := c.Get("1"); !ok {
+		t.Fatal("expected task 1 in cache")
+	}
+
+	c.Set(task3.ID, task3)
+
+	if _, ok := c.Get("2"); ok {
+		t.Error("expected task 2 to be evicted")
+	}
+	if _, ok := c.Get("1"); !ok {
+		t.Error("expected task 1 to stay in cache")
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Errorf("expected 1 eviction, got %d", stats.Evictions)
+	}
+	if stats.Entries != 2 {
+		t.Errorf("expected 2 entries, got %d", stats.Entries)
+	}
+	if stats.Bytes != uint64(TaskSize(task1)+TaskSize(task3)) {
+		t.Errorf("unexpected bytes: %d", stats.Bytes)
+	}
+}
+
+func TestLRUCache_SkipsOversizedTask(t *testing.T) {
+	c := NewLRUCache(64, time.Minute, 0)
+
+	task := &domain.Task{ID: "big", Result: [][]string{{"кот", "ток"}}}
+	c.Set(task.ID, task)
+
+	if _, ok := c.Get("big"); ok {
+		t.Error("expected oversized task not to be cached")
+	}
+	if c.Stats().Bytes != 0 {
+		t.Errorf("expected 0 bytes, got %d", c.Stats().Bytes)
+	}
+}
+
+func TestLRUCache_UpdateRecalculatesSize(t *testing.T) {
+	c := NewLRUCache(1<<20, time.Minute, 0)
+
+	task := &domain.Task{ID: "1"}
+	c.Set(task.ID, task)
+	before := c.Stats().Bytes
+
+	task.Result = [][]string{{"кот", "ток"}, {"рост", "торс"}}
+	c.Set(task.ID, task)
+
+	stats := c.Stats()
+	if stats.Bytes <= before {
+		t.Errorf("expected bytes to grow after result update, got %d <= %d", stats.Bytes, before)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("expected 1 entry, got %d", stats.Entries)
+	}
+}
+
+func TestLRUCache_Expiration(t *testing.T) {
+	c := NewLRUCache(1<<20, time.Millisecond, 0)
+	c.Set("1", &domain.Task{ID: "1"})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("1"); ok {
+		t.Error("expected expired task to be missing")
+	}
+
+	c.Set("2", &domain.Task{ID: "2"})
+	time.Sleep(5 * time.Millisecond)
+	c.DeleteExpired()
+
+	if c.Stats().Entries != 0 {
+		t.Errorf("expected expired entries to be removed, got %d", c.Stats().Entries)
+	}
+}
+
+func TestLRUCache_HitMissCounters(t *testing.T) {
+	c := NewLRUCache(1<<20, time.Minute, 0)
+	c.Set("1", &domain.Task{ID: "1"})
+
+	c.Get("1")
+	c.Get("1")
+	c.Get("missing")
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d/%d", stats.Hits, stats.Misses)
+	}
+}
@@ -3,39 +3,207 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 )
 
 var _ TaskStorage = (*InMemoryStorage)(nil)
+var _ TaskLister = (*InMemoryStorage)(nil)
+var _ TaskDeleter = (*InMemoryStorage)(nil)
+var _ FingerprintFinder = (*InMemoryStorage)(nil)
+var _ IdempotencyStore = (*InMemoryStorage)(nil)
 
-type InMemoryStorage struct {
+const idempotencySweepInterval = time.Minute
+
+// shardCount - количество сегментов с отдельными блокировками.
+// Степень двойки, чтобы номер сегмента вычислялся маской.
+const shardCount = 64
+
+type taskShard struct {
 	mu    sync.RWMutex
 	tasks map[string]*domain.Task
 }
 
+type fingerprintShard struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// InMemoryStorage хранит задачи в сегментах, выбираемых по хэшу ID задачи,
+// чтобы параллельные Save и GetByID разных задач не конкурировали за одну блокировку
+type InMemoryStorage struct {
+	shards       [shardCount]taskShard
+	fingerprints [shardCount]fingerprintShard
+
+	idempotencyMu sync.Mutex
+	idempotency   map[string]domain.IdempotencyRecord
+	lastSweep     time.Time
+}
+
 func NewInMemoryStorage() *InMemoryStorage {
-	return &InMemoryStorage{
-		tasks: make(map[string]*domain.Task),
+	r := &InMemoryStorage{
+		idempotency: make(map[string]domain.IdempotencyRecord),
+	}
+	for i := range r.shards {
+		r.shards[i].tasks = make(map[string]*domain.Task)
+		r.fingerprints[i].ids = make(map[string]string)
+	}
+	return r
+}
+
+// shardIndex считает FNV-1a без аллокаций, поскольку вызывается на каждую операцию
+func shardIndex(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
 	}
+	return h & (shardCount - 1)
 }
 
+func (r *InMemoryStorage) shard(id string) *taskShard {
+	return &r.shards[shardIndex(id)]
+}
+
+func (r *InMemoryStorage) fingerprintShard(fingerprint string) *fingerprintShard {
+	return &r.fingerprints[shardIndex(fingerprint)]
+}
+
+// Save сохраняет копию задачи, если ее версия совпадает с сохраненной,
+// и увеличивает версию. Задача, которой еще нет в хранилище, сохраняется
+// с версией 1 либо с уже заданной версией (при восстановлении и импорте).
 func (r *InMemoryStorage) Save(ctx context.Context, task *domain.Task) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	shard := r.shard(task.ID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
-	r.tasks[task.ID] = task
+	if existing, ok := shard.tasks[task.ID]; ok {
+		if existing.Version != task.Version {
+			return &VersionConflictError{TaskID: task.ID, Expected: task.Version, Actual: existing.Version}
+		}
+		task.Version++
+	} else if task.Version == 0 {
+		task.Version = 1
+	}
+
+	shard.tasks[task.ID] = task.Clone()
+	if task.Fingerprint != "" && task.Status == domain.StatusCompleted {
+		fpShard := r.fingerprintShard(task.Fingerprint)
+		fpShard.mu.Lock()
+		fpShard.ids[task.Fingerprint] = task.ID
+		fpShard.mu.Unlock()
+	}
 	return nil
 }
 
 func (r *InMemoryStorage) GetByID(ctx context.Context, id string) (*domain.Task, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	shard := r.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
 
-	task, ok := r.tasks[id]
+	task, ok := shard.tasks[id]
 	if !ok {
-		return nil, fmt.Errorf("task with id %s not found", id)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
-	return task, nil
+	return task.Clone(), nil
+}
+
+func (r *InMemoryStorage) Delete(ctx context.Context, id string) error {
+	shard := r.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	task, ok := shard.tasks[id]
+	if !ok {
+		return nil
+	}
+	delete(shard.tasks, id)
+
+	if task.Fingerprint != "" {
+		fpShard := r.fingerprintShard(task.Fingerprint)
+		fpShard.mu.Lock()
+		if fpShard.ids[task.Fingerprint] == id {
+			delete(fpShard.ids, task.Fingerprint)
+		}
+		fpShard.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *InMemoryStorage) List(ctx context.Context) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.RLock()
+		for _, task := range shard.tasks {
+			tasks = append(tasks, task.Clone())
+		}
+		shard.mu.RUnlock()
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	return tasks, nil
+}
+
+func (r *InMemoryStorage) FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error) {
+	fpShard := r.fingerprintShard(fingerprint)
+	fpShard.mu.RLock()
+	id, ok := fpShard.ids[fingerprint]
+	fpShard.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	shard := r.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	task, ok := shard.tasks[id]
+	if !ok || task.Status != domain.StatusCompleted || task.Fingerprint != fingerprint {
+		return nil, false, nil
+	}
+	return task.Clone(), true, nil
+}
+
+func (r *InMemoryStorage) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	r.idempotencyMu.Lock()
+	defer r.idempotencyMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) >= idempotencySweepInterval {
+		for key, existing := range r.idempotency {
+			if existing.Expired(now) {
+				delete(r.idempotency, key)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	if existing, ok := r.idempotency[record.Key]; ok && !existing.Expired(now) {
+		return existing, false, nil
+	}
+
+	r.idempotency[record.Key] = record
+	return record, true, nil
+}
+
+func (r *InMemoryStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	r.idempotencyMu.Lock()
+	defer r.idempotencyMu.Unlock()
+
+	delete(r.idempotency, key)
+	return nil
 }
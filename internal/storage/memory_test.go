@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+// lockedStorage - прежняя реализация с одной блокировкой на все задачи,
+// оставлена для сравнения в бенчмарках
+type lockedStorage struct {
+	mu    sync.RWMutex
+	tasks map[string]*domain.Task
+}
+
+func (r *lockedStorage) Save(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.tasks[task.ID]; ok {
+		if existing.Version != task.Version {
+			return &VersionConflictError{TaskID: task.ID, Expected: task.Version, Actual: existing.Version}
+		}
+		task.Version++
+	} else if task.Version == 0 {
+		task.Version = 1
+	}
+	r.tasks[task.ID] = task.Clone()
+	return nil
+}
+
+func (r *lockedStorage) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("task with id %s not found", id)
+	}
+	return task.Clone(), nil
+}
+
+func TestInMemoryStorage_ConcurrentSaves(t *testing.T) {
+	store := NewInMemoryStorage()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				task := &domain.Task{ID: fmt.Sprintf("%d-%d", w, i), Status: domain.StatusProcessing}
+				_ = store.Save(ctx, task)
+				task.Status = domain.StatusCompleted
+				if err := store.Save(ctx, task); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	tasks, _ := store.List(ctx)
+	if len(tasks) != 1600 {
+		t.Fatalf("expected 1600 tasks, got %d", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Version != 2 || task.Status != domain.StatusCompleted {
+			t.Fatalf("unexpected task state: %+v", task)
+		}
+	}
+}
+
+// benchmarkStorage имитирует нагрузку воркеров и опроса результатов:
+// каждая горутина сохраняет задачи и читает случайные существующие
+func benchmarkStorage(b *testing.B, store TaskStorage, readsPerWrite int) {
+	ctx := context.Background()
+
+	const preloaded = 10000
+	ids := make([]string, preloaded)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("task-%d", i)
+		_ = store.Save(ctx, &domain.Task{ID: ids[i], Status: domain.StatusCompleted})
+	}
+
+	var seq atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		n := 0
+		for pb.Next() {
+			if n%(readsPerWrite+1) == 0 {
+				_ = store.Save(ctx, &domain.Task{ID: fmt.Sprintf("new-%d", seq.Add(1)), Status: domain.StatusProcessing})
+			} else {
+				_, _ = store.GetByID(ctx, ids[n%preloaded])
+			}
+			n++
+		}
+	})
+}
+
+func BenchmarkStorage_WriteHeavy(b *testing.B) {
+	b.Run("Locked", func(b *testing.B) {
+		benchmarkStorage(b, &lockedStorage{tasks: make(map[string]*domain.Task)}, 1)
+	})
+	b.Run("Sharded", func(b *testing.B) {
+		benchmarkStorage(b, NewInMemoryStorage(), 1)
+	})
+}
+
+func BenchmarkStorage_ReadHeavy(b *testing.B) {
+	b.Run("Locked", func(b *testing.B) {
+		benchmarkStorage(b, &lockedStorage{tasks: make(map[string]*domain.Task)}, 10)
+	})
+	b.Run("Sharded", func(b *testing.B) {
+		benchmarkStorage(b, NewInMemoryStorage(), 10)
+	})
+}
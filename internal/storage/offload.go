@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var _ TaskStorage = (*OffloadingTaskStorage)(nil)
+var _ ResultLoader = (*OffloadingTaskStorage)(nil)
+var _ TaskDeleter = (*OffloadingTaskStorage)(nil)
+
+// OffloadingTaskStorage выносит большие результаты задач в ResultStore,
+// оставляя в нижележащем хранилище только метаданные и ссылку на результат
+type OffloadingTaskStorage struct {
+	next      TaskStorage
+	results   ResultStore
+	threshold int64
+}
+
+func NewOffloadingTaskStorage(next TaskStorage, results ResultStore, threshold int64) *OffloadingTaskStorage {
+	return &OffloadingTaskStorage{
+		next:      next,
+		results:   results,
+		threshold: threshold,
+	}
+}
+
+func (r *OffloadingTaskStorage) Save(ctx context.Context, task *domain.Task) error {
+	l := logger.FromContext(ctx)
+
+	tr := otel.Tracer("repository")
+	ctx, span := tr.Start(ctx, "OffloadingTaskStorage.Save")
+	defer span.End()
+
+	// задача без загруженного результата сохраняется со ссылкой как есть
+	if task.Result == nil && task.ResultRef != nil {
+		return r.next.Save(ctx, task)
+	}
+
+	stored := task.Clone()
+	stored.ResultRef = nil
+
+	if task.Result != nil && ResultSize(task.Result) > r.threshold {
+		ref, err := r.results.Put(ctx, task.Result)
+		if err != nil {
+			span.RecordError(err)
+			return unavailable("store result", err)
+		}
+		stored.Result = nil
+		stored.ResultRef = ref
+		span.SetAttributes(attribute.Bool("offloaded", true), attribute.Int64("compressed_bytes", ref.CompressedBytes))
+	}
+
+	previous, _ := r.next.GetByID(ctx, task.ID)
+
+	if err := r.next.Save(ctx, stored); err != nil {
+		if stored.ResultRef != nil {
+			_ = r.results.Delete(ctx, stored.ResultRef)
+		}
+		span.RecordError(err)
+		return err
+	}
+	task.Version = stored.Version
+	task.ResultRef = stored.ResultRef
+
+	// успешное сохранение с ожидаемой версией означает, что previous - замененная версия
+	if previous != nil && previous.Version+1 == stored.Version && previous.ResultRef != nil {
+		if err := r.results.Delete(ctx, previous.ResultRef); err != nil {
+			l.Warn("failed to delete replaced result", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (r *OffloadingTaskStorage) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// Delete удаляет задачу и вынесенный из нее результат
+func (r *OffloadingTaskStorage) Delete(ctx context.Context, id string) error {
+	deleter, ok := r.next.(TaskDeleter)
+	if !ok {
+		return ErrDeleteNotSupported
+	}
+
+	previous, _ := r.next.GetByID(ctx, id)
+	if err := deleter.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if previous != nil && previous.ResultRef != nil {
+		if err := r.results.Delete(ctx, previous.ResultRef); err != nil {
+			logger.FromContext(ctx).Warn("failed to delete result of deleted task", zap.String("task_id", id), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// LoadResult подгружает вынесенный результат в задачу
+func (r *OffloadingTaskStorage) LoadResult(ctx context.Context, task *domain.Task) error {
+	if task.ResultRef == nil || task.Result != nil {
+		return nil
+	}
+
+	tr := otel.Tracer("repository")
+	ctx, span := tr.Start(ctx, "OffloadingTaskStorage.LoadResult")
+	defer span.End()
+
+	result, err := r.results.Get(ctx, task.ResultRef)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, ErrResultNotFound) {
+			return err
+		}
+		return unavailable("load result", err)
+	}
+	task.Result = result
+	return nil
+}
+
+func (r *OffloadingTaskStorage) List(ctx context.Context) ([]*domain.Task, error) {
+	lister, ok := r.next.(TaskLister)
+	if !ok {
+		return nil, ErrListNotSupported
+	}
+	return lister.List(ctx)
+}
+
+func (r *OffloadingTaskStorage) FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error) {
+	finder, ok := r.next.(FingerprintFinder)
+	if !ok {
+		return nil, false, nil
+	}
+	return finder.FindByFingerprint(ctx, fingerprint)
+}
+
+func (r *OffloadingTaskStorage) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return domain.IdempotencyRecord{}, false, ErrIdempotencyNotSupported
+	}
+	return store.ReserveIdempotencyKey(ctx, record)
+}
+
+func (r *OffloadingTaskStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	store, ok := r.next.(IdempotencyStore)
+	if !ok {
+		return ErrIdempotencyNotSupported
+	}
+	return store.ReleaseIdempotencyKey(ctx, key)
+}
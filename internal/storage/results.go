@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrResultNotFound = errors.New("result not found")
+
+// ResultStore хранит результаты задач отдельно от их метаданных
+type ResultStore interface {
+	Put(ctx context.Context, result [][]string) (*domain.ResultRef, error)
+	Get(ctx context.Context, ref *domain.ResultRef) ([][]string, error)
+	Delete(ctx context.Context, ref *domain.ResultRef) error
+}
+
+var _ ResultStore = (*FileResultStore)(nil)
+
+// FileResultStore хранит каждый результат в отдельном каталоге в виде
+// последовательности gzip-чанков, каждый из которых содержит группы в формате NDJSON
+type FileResultStore struct {
+	dir       string
+	chunkSize int64
+}
+
+func NewFileResultStore(dir string, chunkSize int64) (*FileResultStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create result store dir: %w", err)
+	}
+	return &FileResultStore{dir: dir, chunkSize: chunkSize}, nil
+}
+
+func (s *FileResultStore) Put(ctx context.Context, result [][]string) (*domain.ResultRef, error) {
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "FileResultStore.Put")
+	defer span.End()
+
+	ref := &domain.ResultRef{Key: uuid.New().String(), Groups: len(result)}
+
+	tmpDir, err := os.MkdirTemp(s.dir, ".tmp-")
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("create result dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for start := 0; start < len(result) || ref.Chunks == 0; {
+		end, written, err := s.writeChunk(filepath.Join(tmpDir, chunkName(ref.Chunks)), result[start:])
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		start += end
+		ref.Chunks++
+		ref.CompressedBytes += written
+	}
+
+	if err := os.Rename(tmpDir, s.path(ref)); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("store result: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("chunks", ref.Chunks), attribute.Int64("compressed_bytes", ref.CompressedBytes))
+	return ref, nil
+}
+
+// writeChunk пишет группы в чанк, пока объем несжатых данных не превысит chunkSize,
+// и возвращает количество записанных групп и размер файла
+func (s *FileResultStore) writeChunk(path string, groups [][]string) (int, int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("create result chunk: %w", err)
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	encoder := json.NewEncoder(zw)
+
+	var raw int64
+	n := 0
+	for n < len(groups) && (n == 0 || raw < s.chunkSize) {
+		if err := encoder.Encode(groups[n]); err != nil {
+			return 0, 0, fmt.Errorf("write result chunk: %w", err)
+		}
+		raw += ResultSize(groups[n : n+1])
+		n++
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, 0, fmt.Errorf("write result chunk: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	return n, info.Size(), file.Close()
+}
+
+func (s *FileResultStore) Get(ctx context.Context, ref *domain.ResultRef) ([][]string, error) {
+	tr := otel.Tracer("repository")
+	_, span := tr.Start(ctx, "FileResultStore.Get")
+	defer span.End()
+
+	result := make([][]string, 0, ref.Groups)
+	for i := 0; i < ref.Chunks; i++ {
+		var err error
+		result, err = readChunk(filepath.Join(s.path(ref), chunkName(i)), result)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if len(result) != ref.Groups {
+		err := fmt.Errorf("result %s: expected %d groups, got %d", ref.Key, ref.Groups, len(result))
+		span.RecordError(err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func readChunk(path string, result [][]string) ([][]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrResultNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("read result chunk: %w", err)
+	}
+	defer zr.Close()
+
+	decoder := json.NewDecoder(zr)
+	for {
+		var group []string
+		if err := decoder.Decode(&group); errors.Is(err, io.EOF) {
+			return result, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("read result chunk: %w", err)
+		}
+		result = append(result, group)
+	}
+}
+
+func (s *FileResultStore) Delete(ctx context.Context, ref *domain.ResultRef) error {
+	return os.RemoveAll(s.path(ref))
+}
+
+func (s *FileResultStore) path(ref *domain.ResultRef) string {
+	return filepath.Join(s.dir, filepath.Base(ref.Key))
+}
+
+func chunkName(i int) string {
+	return fmt.Sprintf("chunk-%05d.ndjson.gz", i)
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+func newTestResultStore(t *testing.T, chunkSize int64) *FileResultStore {
+	t.Helper()
+
+	store, err := NewFileResultStore(t.TempDir(), chunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return store
+}
+
+func largeResult(groups int) [][]string {
+	result := make([][]string, groups)
+	for i := range result {
+		result[i] = []string{fmt.Sprintf("кот%d", i), fmt.Sprintf("ток%d", i)}
+	}
+	return result
+}
+
+func TestFileResultStore_RoundTrip(t *testing.T) {
+	store := newTestResultStore(t, 256)
+	ctx := context.Background()
+	result := largeResult(100)
+
+	ref, err := store.Put(ctx, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.Chunks < 2 || ref.Groups != 100 || ref.CompressedBytes == 0 {
+		t.Errorf("expected chunked result, got %+v", ref)
+	}
+
+	got, err := store.Get(ctx, ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 100 || got[99][1] != "ток99" {
+		t.Errorf("unexpected result: %v", got)
+	}
+
+	if err := store.Delete(ctx, ref); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Get(ctx, ref); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("expected ErrResultNotFound, got %v", err)
+	}
+}
+
+func TestFileResultStore_EmptyResult(t *testing.T) {
+	store := newTestResultStore(t, 256)
+
+	ref, err := store.Put(context.Background(), [][]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := store.Get(context.Background(), ref)
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected empty result, got %v, %v", got, err)
+	}
+}
+
+func TestOffloadingStorage_OffloadsLargeResults(t *testing.T) {
+	results := newTestResultStore(t, 1024)
+	base := NewInMemoryStorage()
+	store := NewOffloadingTaskStorage(base, results, 512)
+	ctx := context.Background()
+
+	small := &domain.Task{ID: "small", Status: domain.StatusCompleted, Result: [][]string{{"кот", "ток"}}}
+	large := &domain.Task{ID: "large", Status: domain.StatusCompleted, Result: largeResult(100)}
+	for _, task := range []*domain.Task{small, large} {
+		if err := store.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	stored, _ := base.GetByID(ctx, "small")
+	if stored.ResultRef != nil || len(stored.Result) != 1 {
+		t.Errorf("expected small result to stay inline, got %+v", stored)
+	}
+
+	stored, _ = base.GetByID(ctx, "large")
+	if stored.ResultRef == nil || stored.Result != nil {
+		t.Fatalf("expected large result to be offloaded, got %+v", stored)
+	}
+	if err := store.LoadResult(ctx, stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored.Result) != 100 {
+		t.Errorf("expected loaded result, got %d groups", len(stored.Result))
+	}
+}
+
+func TestCachedStorage_CachesOffloadedResultByRef(t *testing.T) {
+	ctx := context.Background()
+	journaled, _, _ := openTestJournal(t, filepath.Join(t.TempDir(), "journal.log"))
+	offloading := NewOffloadingTaskStorage(journaled, newTestResultStore(t, 1024), 512)
+	cache := NewLRUCache(1<<20, time.Minute, 0)
+	store := NewCachedTaskStorage(offloading, cache)
+
+	result := largeResult(100)
+	task := &domain.Task{ID: "large", Status: domain.StatusCompleted, Result: result}
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cached, found := cache.Get("large")
+	if !found || cached.ResultRef == nil || cached.Result != nil {
+		t.Fatalf("expected metadata-only cache entry, got %+v", cached)
+	}
+	if bytes := cache.Stats().Bytes; int64(bytes) >= ResultSize(result) {
+		t.Errorf("expected cache entry smaller than result, got %d bytes", bytes)
+	}
+
+	got, err := store.GetByID(ctx, "large")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.LoadResult(ctx, got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Result) != 100 || got.Result[99][1] != "ток99" {
+		t.Errorf("expected result loaded on demand, got %d groups", len(got.Result))
+	}
+	if again, _ := cache.Get("large"); again.Result != nil {
+		t.Error("loading a result must not fill the cached task")
+	}
+}
+
+func TestOffloadingStorage_ReplacesAndCleansUpResults(t *testing.T) {
+	results := newTestResultStore(t, 1024)
+	store := NewOffloadingTaskStorage(NewInMemoryStorage(), results, 512)
+	ctx := context.Background()
+
+	task := &domain.Task{ID: "1", Status: domain.StatusCompleted, Result: largeResult(100)}
+	_ = store.Save(ctx, task)
+	first := task.ResultRef
+
+	stale := task.Clone()
+	task.Result = largeResult(50)
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(results.path(first)); !os.IsNotExist(err) {
+		t.Error("expected replaced result to be deleted")
+	}
+
+	stale.Result = largeResult(10)
+	if err := store.Save(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	entries, _ := os.ReadDir(results.dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only current result on disk, got %d entries", len(entries))
+	}
+}
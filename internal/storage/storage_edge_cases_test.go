@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
@@ -22,8 +23,8 @@ func TestCachedStorage_GetByID_BaseError(t *testing.T) {
 func TestInMemoryStorage_GetByID_NotFound(t *testing.T) {
 	store := NewInMemoryStorage()
 	_, err := store.GetByID(context.Background(), "missing")
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
 }
 
@@ -70,3 +71,97 @@ func TestMockTaskStorage_Flush_Default(t *testing.T) {
 		t.Fatal("expected tasks to be cleared on default Flush")
 	}
 }
+
+func TestInMemoryStorage_FindByFingerprint(t *testing.T) {
+	store := NewInMemoryStorage()
+	ctx := context.Background()
+
+	task := &domain.Task{ID: "1", Status: domain.StatusProcessing, Fingerprint: "fp"}
+	_ = store.Save(ctx, task)
+
+	if _, found, _ := store.FindByFingerprint(ctx, "fp"); found {
+		t.Fatal("unfinished task must not be returned")
+	}
+
+	task.Status = domain.StatusCompleted
+	_ = store.Save(ctx, task)
+
+	got, found, err := store.FindByFingerprint(ctx, "fp")
+	if err != nil || !found || got.ID != "1" {
+		t.Fatalf("expected completed task, got %v, %v, %v", got, found, err)
+	}
+
+	if _, found, _ := store.FindByFingerprint(ctx, "other"); found {
+		t.Error("unexpected task for unknown fingerprint")
+	}
+}
+
+func TestInMemoryStorage_ReserveIdempotencyKey(t *testing.T) {
+	store := NewInMemoryStorage()
+	ctx := context.Background()
+
+	first := domain.IdempotencyRecord{Key: "k", TaskID: "1", ExpiresAt: time.Now().Add(time.Hour)}
+	if _, reserved, _ := store.ReserveIdempotencyKey(ctx, first); !reserved {
+		t.Fatal("expected first reservation to succeed")
+	}
+
+	existing, reserved, _ := store.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "k", TaskID: "2"})
+	if reserved || existing.TaskID != "1" {
+		t.Errorf("expected existing record for task 1, got %+v, reserved=%v", existing, reserved)
+	}
+
+	_ = store.ReleaseIdempotencyKey(ctx, "k")
+	if _, reserved, _ := store.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{Key: "k", TaskID: "2"}); !reserved {
+		t.Error("expected released key to be reusable")
+	}
+}
+
+func TestInMemoryStorage_Save_VersionConflict(t *testing.T) {
+	store := NewInMemoryStorage()
+	ctx := context.Background()
+
+	task := &domain.Task{ID: "1", Status: domain.StatusProcessing}
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Version != 1 {
+		t.Fatalf("expected version 1 after create, got %d", task.Version)
+	}
+
+	stale, _ := store.GetByID(ctx, "1")
+	fresh, _ := store.GetByID(ctx, "1")
+
+	fresh.Status = domain.StatusFailed
+	if err := store.Save(ctx, fresh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale.Status = domain.StatusCompleted
+	err := store.Save(ctx, stale)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+		t.Errorf("unexpected conflict details: %+v", conflict)
+	}
+
+	stored, _ := store.GetByID(ctx, "1")
+	if stored.Status != domain.StatusFailed || stored.Version != 2 {
+		t.Errorf("stale save must not overwrite task, got %+v", stored)
+	}
+}
+
+func TestInMemoryStorage_GetByID_ReturnsCopy(t *testing.T) {
+	store := NewInMemoryStorage()
+	ctx := context.Background()
+	_ = store.Save(ctx, &domain.Task{ID: "1", Status: domain.StatusProcessing})
+
+	task, _ := store.GetByID(ctx, "1")
+	task.Status = domain.StatusCompleted
+
+	stored, _ := store.GetByID(ctx, "1")
+	if stored.Status != domain.StatusProcessing {
+		t.Error("unsaved changes must not leak into storage")
+	}
+}
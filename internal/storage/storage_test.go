@@ -2,16 +2,16 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
-	"github.com/patrickmn/go-cache"
 )
 
 func newTestCache(base TaskStorage) *CachedTaskStorage {
-	c := cache.New(5*time.Minute, 10*time.Minute)
+	c := NewLRUCache(1<<20, 5*time.Minute, 0)
 	return NewCachedTaskStorage(base, c)
 }
 
@@ -38,7 +38,7 @@ func TestCachedStorage_GetByID_CacheHit(t *testing.T) {
 	cache := newTestCache(base)
 
 	task := &domain.Task{ID: "2"}
-	cache.cache.Set("2", task, time.Minute)
+	cache.cache.Set("2", task)
 
 	got, err := cache.GetByID(context.Background(), "2")
 	if err != nil {
@@ -54,7 +54,7 @@ func TestCachedStorage_Flush(t *testing.T) {
 	cache := newTestCache(base)
 
 	task := &domain.Task{ID: "1"}
-	cache.cache.Set(task.ID, task, time.Minute)
+	cache.cache.Set(task.ID, task)
 
 	err := cache.Flush(context.Background())
 	if err != nil {
@@ -65,3 +65,108 @@ func TestCachedStorage_Flush(t *testing.T) {
 		t.Error("expected cache to be flushed, but task still exists")
 	}
 }
+
+func TestCachedStorage_Invalidate(t *testing.T) {
+	base := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	cache := newTestCache(base)
+
+	task := &domain.Task{ID: "1"}
+	if err := cache.Save(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cache.Invalidate(context.Background(), "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, found := cache.cache.Get("1"); found {
+		t.Error("expected task to be evicted from cache")
+	}
+	if _, ok := base.Tasks["1"]; !ok {
+		t.Error("expected task to stay in base storage")
+	}
+
+	if err := cache.Invalidate(context.Background(), "1"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got %v", err)
+	}
+}
+
+func TestCachedStorage_InvalidateMatching(t *testing.T) {
+	base := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	cache := newTestCache(base)
+
+	cache.cache.Set("1", &domain.Task{ID: "1", Status: domain.StatusCompleted})
+	cache.cache.Set("2", &domain.Task{ID: "2", Status: domain.StatusFailed})
+	cache.cache.Set("3", &domain.Task{ID: "3", Status: domain.StatusFailed})
+
+	evicted, err := cache.InvalidateMatching(context.Background(), domain.CacheFilter{
+		TaskIDs: []string{"1", "2"},
+		Status:  domain.StatusFailed,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evicted != 1 {
+		t.Errorf("expected 1 evicted, got %d", evicted)
+	}
+	if _, found := cache.cache.Get("2"); found {
+		t.Error("expected task 2 to be evicted")
+	}
+
+	evicted, _ = cache.InvalidateMatching(context.Background(), domain.CacheFilter{OlderThan: time.Hour})
+	if evicted != 0 {
+		t.Errorf("expected fresh entries to stay, got %d evicted", evicted)
+	}
+}
+
+func TestCachedStorage_Summary(t *testing.T) {
+	base := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	cache := newTestCache(base)
+
+	summary, err := cache.Summary(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Entries != 0 || summary.OldestTaskID != "" {
+		t.Errorf("expected empty summary, got %+v", summary)
+	}
+
+	cache.cache.Set("1", &domain.Task{ID: "1"})
+	time.Sleep(time.Millisecond)
+	cache.cache.Set("2", &domain.Task{ID: "2"})
+	cache.cache.Get("1")
+
+	summary, _ = cache.Summary(context.Background())
+	if summary.Entries != 2 {
+		t.Errorf("expected 2 entries, got %d", summary.Entries)
+	}
+	if summary.OldestTaskID != "1" {
+		t.Errorf("expected oldest task 1, got %q", summary.OldestTaskID)
+	}
+	if summary.MaxBytes != 1<<20 {
+		t.Errorf("unexpected max bytes: %d", summary.MaxBytes)
+	}
+}
+
+func TestCachedStorage_Save_VersionConflict(t *testing.T) {
+	cache := newTestCache(NewInMemoryStorage())
+	ctx := context.Background()
+
+	_ = cache.Save(ctx, &domain.Task{ID: "1", Status: domain.StatusProcessing})
+	stale, _ := cache.GetByID(ctx, "1")
+	fresh, _ := cache.GetByID(ctx, "1")
+
+	fresh.Status = domain.StatusFailed
+	if err := cache.Save(ctx, fresh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale.Status = domain.StatusCompleted
+	if err := cache.Save(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	got, _ := cache.GetByID(ctx, "1")
+	if got.Status != domain.StatusFailed || got.Version != 2 {
+		t.Errorf("expected latest version from cache, got %+v", got)
+	}
+}
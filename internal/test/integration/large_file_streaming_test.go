@@ -21,11 +21,11 @@ import (
 	"github.com/grcflEgor/go-anagram-api/internal/config"
 	v1 "github.com/grcflEgor/go-anagram-api/internal/controller/http/v1"
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/service"
 	"github.com/grcflEgor/go-anagram-api/internal/storage"
 	"github.com/grcflEgor/go-anagram-api/internal/worker"
 	"github.com/grcflEgor/go-anagram-api/pkg/logger"
-	"github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -95,10 +95,11 @@ func TestLargeFileStreamingProcessing(t *testing.T) {
 	require.NoError(t, err)
 
 	memoryStorage := storage.NewInMemoryStorage()
-	cacheInstance := cache.New(config.Cache.DefaultExpiration, config.Cache.CleanupInterval)
+	cacheInstance := storage.NewLRUCache(config.Cache.MaxBytes, config.Cache.DefaultExpiration, config.Cache.CleanupInterval)
+	defer cacheInstance.Close()
 	cachedStorage := storage.NewCachedTaskStorage(memoryStorage, cacheInstance)
 
-	taskQueue := make(chan *domain.Task, config.Task.QueueSize)
+	taskQueue := queue.NewQueues(config.Task.QueueSize)
 	stats := service.NewTaskStats()
 
 	batchSize := 1000
@@ -178,7 +179,7 @@ func TestLargeFileStreamingProcessing(t *testing.T) {
 			TraceContext:  make(map[string]string),
 		}
 		_ = cachedStorage.Save(context.Background(), task)
-		taskQueue <- task
+		taskQueue.Push(task)
 		stats.IncrementTotalTasks()
 
 		var resultTask *domain.Task
@@ -201,7 +202,7 @@ func TestLargeFileStreamingProcessing(t *testing.T) {
 
 	t.Run("ResultsCorrectnessTest", func(t *testing.T) {
 		testWords := []string{"ток", "рост", "кот", "торс", "Кто", "фывап", "рок", "hello", "world", "olleh", "dlrow", "test", "tset", "апельсин", "спаниель", "лиса", "сила", "мама", "амма"}
-		taskID, _ := anagramService.CreateTask(context.Background(), testWords, false)
+		taskID, _ := anagramService.CreateTask(context.Background(), testWords, domain.TaskOptions{})
 
 		var task *domain.Task
 	Loop3:
@@ -2,7 +2,9 @@ package mocks
 
 import (
 	"context"
+	"io"
 
+	"github.com/grcflEgor/go-anagram-api/internal/archive"
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -11,8 +13,8 @@ type MockAnagramService struct {
 	mock.Mock
 }
 
-func (m *MockAnagramService) CreateTask(ctx context.Context, words []string, caseSensitive bool) (string, error) {
-	args := m.Called(ctx, words, caseSensitive)
+func (m *MockAnagramService) CreateTask(ctx context.Context, words []string, opts domain.TaskOptions) (string, error) {
+	args := m.Called(ctx, words, opts)
 	return args.String(0), args.Error(1)
 }
 
@@ -27,4 +29,47 @@ func (m *MockAnagramService) GetTaskByID(ctx context.Context, taskID string) (*d
 func (m *MockAnagramService) ClearCache(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+
+func (m *MockAnagramService) InvalidateCache(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAnagramService) InvalidateCacheMatching(ctx context.Context, filter domain.CacheFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAnagramService) CacheSummary(ctx context.Context) (domain.CacheSummary, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(domain.CacheSummary), args.Error(1)
+}
+
+func (m *MockAnagramService) ExportTasks(ctx context.Context, w io.Writer, statuses []domain.TaskStatus) (int, error) {
+	args := m.Called(ctx, w, statuses)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAnagramService) ImportTasks(ctx context.Context, r io.Reader, policy archive.ConflictPolicy) (archive.ImportReport, error) {
+	args := m.Called(ctx, r, policy)
+	return args.Get(0).(archive.ImportReport), args.Error(1)
+}
+
+func (m *MockAnagramService) ListDeadLetterTasks(ctx context.Context) ([]*domain.Task, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Task), args.Error(1)
+}
+
+func (m *MockAnagramService) RequeueTask(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAnagramService) CancelTask(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -18,6 +18,26 @@ func (m *MockTaskStats) IncrementFailedTasks() {
 	m.Called()
 }
 
+func (m *MockTaskStats) IncrementMemoizedTasks() {
+	m.Called()
+}
+
+func (m *MockTaskStats) IncrementRetriedTasks() {
+	m.Called()
+}
+
+func (m *MockTaskStats) IncrementDeadLetterTasks() {
+	m.Called()
+}
+
+func (m *MockTaskStats) IncrementCancelledTasks() {
+	m.Called()
+}
+
+func (m *MockTaskStats) IncrementPanickedTasks() {
+	m.Called()
+}
+
 func (m *MockTaskStats) Get() map[string]uint64 {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -30,5 +50,10 @@ var _ interface {
 	IncrementTotalTasks()
 	IncrementCompletedTasks()
 	IncrementFailedTasks()
+	IncrementMemoizedTasks()
+	IncrementRetriedTasks()
+	IncrementDeadLetterTasks()
+	IncrementCancelledTasks()
+	IncrementPanickedTasks()
 	Get() map[string]uint64
 } = (*MockTaskStats)(nil)
\ No newline at end of file
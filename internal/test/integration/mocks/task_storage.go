@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
 )
@@ -13,6 +14,7 @@ type MockTaskStorage struct {
 	SaveErr error
 	GetErr error
 	FlushFn func(ctx context.Context) error
+	Keys map[string]domain.IdempotencyRecord
 }
 
 func (m *MockTaskStorage) Save(ctx context.Context, task *domain.Task) error {
@@ -40,6 +42,50 @@ func (m *MockTaskStorage) GetByID(ctx context.Context, id string) (*domain.Task,
 	return t, nil
 }
 
+func (m *MockTaskStorage) Delete(ctx context.Context, id string) error {
+	delete(m.Tasks, id)
+	return nil
+}
+
+func (m *MockTaskStorage) List(ctx context.Context) ([]*domain.Task, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	tasks := make([]*domain.Task, 0, len(m.Tasks))
+	for _, t := range m.Tasks {
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (m *MockTaskStorage) FindByFingerprint(ctx context.Context, fingerprint string) (*domain.Task, bool, error) {
+	if m.GetErr != nil {
+		return nil, false, m.GetErr
+	}
+	for _, t := range m.Tasks {
+		if t.Fingerprint == fingerprint && t.Status == domain.StatusCompleted {
+			return t, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *MockTaskStorage) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	if m.Keys == nil {
+		m.Keys = make(map[string]domain.IdempotencyRecord)
+	}
+	if existing, ok := m.Keys[record.Key]; ok && !existing.Expired(time.Now()) {
+		return existing, false, nil
+	}
+	m.Keys[record.Key] = record
+	return record, true, nil
+}
+
+func (m *MockTaskStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	delete(m.Keys, key)
+	return nil
+}
+
 func (m *MockTaskStorage) Flush(ctx context.Context) error {
 	if m.FlushFn != nil {
 		return m.FlushFn(ctx)
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/grcflEgor/go-anagram-api/internal/config"
+	v1 "github.com/grcflEgor/go-anagram-api/internal/controller/http/v1"
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+	"github.com/grcflEgor/go-anagram-api/internal/worker"
+	"github.com/grcflEgor/go-anagram-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const remoteWorkerToken = "test-token"
+
+// queueSource выдает задачи удаленным воркерам прямо из очереди,
+// чтобы их не перехватывали локальные воркеры пула
+type queueSource struct {
+	queue queue.TaskQueue
+}
+
+func (s queueSource) Take(ctx context.Context) (*domain.Task, error) {
+	return s.queue.Dequeue(ctx)
+}
+
+func (s queueSource) Release(task *domain.Task) {}
+
+type remoteTestEnv struct {
+	service *service.AnagramService
+	storage storage.TaskStorage
+	queue   *queue.Queues
+	server  *httptest.Server
+}
+
+func newRemoteTestEnv(t *testing.T, ttl time.Duration) *remoteTestEnv {
+	t.Helper()
+
+	logger.InitLogger()
+
+	config := &config.Config{}
+	config.Remote.Enabled = true
+	config.Remote.LeaseTTL = ttl
+	config.Remote.MaxLeaseWait = 200 * time.Millisecond
+	config.Remote.Token = remoteWorkerToken
+
+	taskStorage := storage.NewInMemoryStorage()
+	taskQueue := queue.NewQueues(100)
+	stats := service.NewTaskStats()
+	anagramService := service.NewAnagramService(taskStorage, taskQueue, stats, 10)
+
+	leases := service.NewLeaseManager(taskStorage, taskQueue, queueSource{queue: taskQueue}, stats, logger.AppLogger)
+	leases.SetTTL(ttl)
+	leases.Start()
+	t.Cleanup(func() { leases.Stop() })
+
+	handlers := v1.NewHandlers(anagramService, validator.New(), config, stats)
+	handlers.SetTaskLeaser(leases)
+
+	router := chi.NewRouter()
+	router.Route("/api/v1/internal/leases", func(r chi.Router) {
+		r.Post("/", handlers.AcquireLease)
+		r.Get("/{id}/input", handlers.LeaseInput)
+		r.Post("/{id}/heartbeat", handlers.HeartbeatLease)
+		r.Post("/{id}/complete", handlers.CompleteLease)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &remoteTestEnv{service: anagramService, storage: taskStorage, queue: taskQueue, server: server}
+}
+
+func (env *remoteTestEnv) waitFinished(t *testing.T, ids []string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		for _, id := range ids {
+			task, err := env.storage.GetByID(context.Background(), id)
+			if err != nil || task.Status == domain.StatusProcessing {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+func TestRemoteWorker_ProcessesTasksOverLoopback(t *testing.T) {
+	env := newRemoteTestEnv(t, 2*time.Second)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, err := env.service.CreateTask(ctx, []string{"кот", "ток", "окт", "дом", "мод", strings.Repeat("я", i+1)}, domain.TaskOptions{})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	client := worker.NewLeaseClient(env.server.URL, remoteWorkerToken, env.server.Client())
+	remote := worker.NewRemoteWorker(client, "loopback", 2, 2, 5*time.Second, logger.AppLogger)
+	remote.SetLeaseWait(100 * time.Millisecond)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		remote.Run(runCtx)
+		close(stopped)
+	}()
+
+	env.waitFinished(t, ids)
+	cancel()
+	<-stopped
+
+	for _, id := range ids {
+		task, err := env.storage.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, domain.StatusCompleted, task.Status, "task %s: %s", id, task.Error)
+		assert.Equal(t, 1, task.Attempts)
+		assert.Equal(t, 6, task.Progress.WordsProcessed)
+
+		var groups []string
+		for _, group := range task.Result {
+			sort.Strings(group)
+			groups = append(groups, strings.Join(group, ","))
+		}
+		sort.Strings(groups)
+		assert.Equal(t, []string{"дом,мод", "кот,окт,ток"}, groups)
+	}
+}
+
+func TestRemoteWorker_ExpiredLeaseIsRequeued(t *testing.T) {
+	env := newRemoteTestEnv(t, 100*time.Millisecond)
+	ctx := context.Background()
+
+	id, err := env.service.CreateTask(ctx, []string{"кот", "ток"}, domain.TaskOptions{})
+	require.NoError(t, err)
+
+	// воркер берет задачу и пропадает, не продлевая аренду
+	client := worker.NewLeaseClient(env.server.URL, remoteWorkerToken, env.server.Client())
+	lease, err := client.Acquire(ctx, "crashed", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, lease)
+	assert.Equal(t, id, lease.TaskID)
+
+	require.Eventually(t, func() bool { return env.queue.Contains(id) }, 2*time.Second, 10*time.Millisecond)
+
+	_, err = client.Heartbeat(ctx, lease.ID, 1, 1)
+	assert.ErrorIs(t, err, worker.ErrLeaseLost)
+
+	remote := worker.NewRemoteWorker(client, "healthy", 1, 10, 5*time.Second, logger.AppLogger)
+	remote.SetLeaseWait(100 * time.Millisecond)
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		remote.Run(runCtx)
+		close(stopped)
+	}()
+
+	env.waitFinished(t, []string{id})
+	cancel()
+	<-stopped
+
+	task, err := env.storage.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCompleted, task.Status)
+	assert.Equal(t, 2, task.Attempts)
+}
+
+func TestRemoteWorker_RejectsInvalidToken(t *testing.T) {
+	env := newRemoteTestEnv(t, time.Second)
+
+	_, err := env.service.CreateTask(context.Background(), []string{"кот", "ток"}, domain.TaskOptions{})
+	require.NoError(t, err)
+
+	client := worker.NewLeaseClient(env.server.URL, "wrong", env.server.Client())
+	lease, err := client.Acquire(context.Background(), "intruder", time.Second)
+	require.Error(t, err)
+	assert.Nil(t, lease)
+	assert.Contains(t, err.Error(), "WORKER_UNAUTHORIZED")
+	assert.Equal(t, 1, env.queue.Len())
+
+	resp, err := http.Post(env.server.URL+"/api/v1/internal/leases/", "application/json", strings.NewReader(`{"worker_id":"anonymous"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
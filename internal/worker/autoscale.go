@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AutoscalePolicy определяет, когда пул добавляет и убирает воркеров
+type AutoscalePolicy struct {
+	// Как часто пересчитывать размер пула
+	Interval time.Duration
+	// Допустимое количество задач в очереди на одного воркера
+	QueuePerWorker int
+	// Допустимое среднее время ожидания задачи в очереди
+	TargetLatency time.Duration
+}
+
+var DefaultAutoscalePolicy = AutoscalePolicy{
+	Interval:       5 * time.Second,
+	QueuePerWorker: 10,
+	TargetLatency:  2 * time.Second,
+}
+
+// queueWaitTracker накапливает время ожидания задач в очереди между
+// срабатываниями автомасштабирования
+type queueWaitTracker struct {
+	mu    sync.Mutex
+	total time.Duration
+	count int
+}
+
+func (t *queueWaitTracker) observe(wait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total += wait
+	t.count++
+}
+
+// reset возвращает среднее время ожидания с прошлого вызова
+func (t *queueWaitTracker) reset() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var avg time.Duration
+	if t.count > 0 {
+		avg = t.total / time.Duration(t.count)
+	}
+	t.total, t.count = 0, 0
+	return avg
+}
+
+// desiredWorkers вычисляет размер пула по глубине очереди и времени ожидания.
+// Пул растет сразу до нужного размера, а сокращается по одному воркеру,
+// чтобы не терять производительность при кратковременном затишье.
+func (p AutoscalePolicy) desiredWorkers(current, depth int, latency time.Duration, minWorkers, maxWorkers int) int {
+	perWorker := max(p.QueuePerWorker, 1)
+	needed := (depth + perWorker - 1) / perWorker
+
+	desired := current
+	switch {
+	case depth > current*perWorker || (p.TargetLatency > 0 && latency > p.TargetLatency):
+		desired = max(needed, current+1)
+	case depth == 0 && (p.TargetLatency <= 0 || latency <= p.TargetLatency/2):
+		desired = current - 1
+	}
+
+	if maxWorkers > 0 && desired > maxWorkers {
+		desired = maxWorkers
+	}
+	return max(desired, minWorkers, 1)
+}
+
+// EnableAutoscaling запускает фоновую подстройку количества воркеров
+// в границах SetWorkerLimits. Подстройка прекращается в Stop.
+func (pool *Pool) EnableAutoscaling(policy AutoscalePolicy) {
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultAutoscalePolicy.Interval
+	}
+
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-pool.done:
+				return
+			case <-ticker.C:
+				pool.autoscale(policy)
+			}
+		}
+	}()
+}
+
+func (pool *Pool) autoscale(policy AutoscalePolicy) {
+	latency := pool.queueWait.reset()
+	depth := pool.queues.Len()
+
+	pool.workersMu.Lock()
+	current, minWorkers, maxWorkers := len(pool.workers), pool.minWorkers, pool.maxWorkers
+	pool.workersMu.Unlock()
+
+	desired := policy.desiredWorkers(current, depth, latency, minWorkers, maxWorkers)
+	if desired == current {
+		return
+	}
+
+	pool.logger.Info("autoscaling worker pool",
+		zap.Int("queue_depth", depth),
+		zap.Duration("queue_latency", latency),
+		zap.Int("workers", current),
+		zap.Int("desired", desired),
+	)
+	if err := pool.Resize(desired); err != nil {
+		pool.logger.Warn("failed to autoscale worker pool", zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
+	"go.uber.org/zap"
+)
+
+func TestAutoscalePolicy_DesiredWorkers(t *testing.T) {
+	policy := AutoscalePolicy{QueuePerWorker: 10, TargetLatency: time.Second}
+
+	cases := []struct {
+		name    string
+		current int
+		depth   int
+		latency time.Duration
+		want    int
+	}{
+		{"deep queue grows to needed size", 2, 95, 0, 10},
+		{"high latency adds worker", 4, 5, 2 * time.Second, 5},
+		{"busy but within targets keeps size", 4, 20, 800 * time.Millisecond, 4},
+		{"idle shrinks by one", 4, 0, 0, 3},
+		{"capped by max", 2, 1000, 0, 16},
+		{"kept at min", 2, 0, 0, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.desiredWorkers(tc.current, tc.depth, tc.latency, 2, 16); got != tc.want {
+				t.Errorf("expected %d workers, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPool_Resize(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(10)
+	stats := service.NewTaskStats()
+
+	pool := NewPool(storage, taskQueue, zap.NewNop(), time.Second, stats, 10)
+	pool.SetWorkerLimits(1, 4)
+	pool.Run(2)
+
+	if err := pool.Resize(4); err != nil || pool.Size() != 4 {
+		t.Fatalf("expected 4 workers, got %d, %v", pool.Size(), err)
+	}
+	if err := pool.Resize(5); !errors.Is(err, ErrInvalidPoolSize) {
+		t.Errorf("expected ErrInvalidPoolSize, got %v", err)
+	}
+	if err := pool.Resize(1); err != nil || pool.Size() != 1 {
+		t.Fatalf("expected 1 worker, got %d, %v", pool.Size(), err)
+	}
+
+	taskQueue.Push(&domain.Task{ID: "t1", Words: []string{"кот", "ток"}})
+	time.Sleep(200 * time.Millisecond)
+
+	saved, _ := storage.GetByID(context.Background(), "t1")
+	if saved == nil || saved.Status != domain.StatusCompleted {
+		t.Errorf("expected remaining worker to process task, got %+v", saved)
+	}
+
+	pool.Stop()
+	if err := pool.Resize(2); !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("expected ErrPoolStopped, got %v", err)
+	}
+}
+
+func TestPool_Autoscale(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(100)
+	stats := service.NewTaskStats()
+
+	pool := NewPool(storage, taskQueue, zap.NewNop(), time.Second, stats, 10)
+	pool.SetWorkerLimits(1, 8)
+	defer pool.Stop()
+
+	// воркеры не запущены, поэтому задачи остаются в очереди
+	for i := 0; i < 30; i++ {
+		taskQueue.Push(&domain.Task{ID: fmt.Sprintf("t%d", i), Words: []string{"кот"}})
+	}
+	pool.autoscale(AutoscalePolicy{QueuePerWorker: 10})
+	if pool.Size() != 3 {
+		t.Errorf("expected pool to grow to 3 workers, got %d", pool.Size())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	pool.autoscale(AutoscalePolicy{QueuePerWorker: 10})
+	if pool.Size() != 2 {
+		t.Errorf("expected idle pool to shrink by one, got %d", pool.Size())
+	}
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// DefaultMinChunkSize - меньше этого размера часть файла не выделяется
+// в отдельный фрагмент: накладные расходы превысят выигрыш от параллельности
+const DefaultMinChunkSize = 1 << 20
+
+// chunk - диапазон байт файла [start, end), границы которого совпадают с границами слов
+type chunk struct {
+	start, end int64
+}
+
+// splitChunks делит файл размера size не более чем на parts фрагментов не меньше
+// minSize байт. Граница фрагмента сдвигается вперед до ближайшего ASCII-пробела:
+// такой байт не встречается внутри многобайтовых символов UTF-8, поэтому
+// слово никогда не разрезается.
+func splitChunks(r io.ReaderAt, size int64, parts int, minSize int64) ([]chunk, error) {
+	parts = int(min(int64(parts), size/max(minSize, 1)))
+	if parts <= 1 {
+		return []chunk{{start: 0, end: size}}, nil
+	}
+
+	chunks := make([]chunk, 0, parts)
+	var start int64
+	for i := 1; i < parts && start < size; i++ {
+		end, err := wordBoundary(r, max(size*int64(i)/int64(parts), start), size)
+		if err != nil {
+			return nil, err
+		}
+		if end >= size {
+			break
+		}
+		if end > start {
+			chunks = append(chunks, chunk{start: start, end: end})
+			start = end
+		}
+	}
+	return append(chunks, chunk{start: start, end: size}), nil
+}
+
+// wordBoundary возвращает позицию сразу после первого ASCII-пробела начиная с offset
+func wordBoundary(r io.ReaderAt, offset, size int64) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r, offset, size-offset))
+	pos := offset
+	for {
+		b, err := reader.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		pos++
+		switch b {
+		case ' ', '\t', '\n', '\v', '\f', '\r':
+			return pos, nil
+		}
+	}
+}
+
+// groupFile группирует слова файла, обрабатывая его фрагменты параллельно, не более
+// parallelism одновременно. Группы фрагментов объединяются в порядке фрагментов,
+// поэтому результат совпадает с последовательным чтением файла.
+func groupFile(ctx context.Context, file *os.File, caseSensitive bool, batchSize, parallelism int, minChunkSize int64, group groupFunc, progress func(words, batches int)) (map[string][]string, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := splitChunks(file, info.Size(), parallelism, minChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 1 {
+		return groupReader(ctx, file, caseSensitive, batchSize, group, progress)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// прогресс фрагментов суммируется: общий счетчик растет монотонно
+	var progressMu sync.Mutex
+	words := make([]int, len(chunks))
+	batches := make([]int, len(chunks))
+	chunkProgress := func(i int) func(w, b int) {
+		return func(w, b int) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+
+			words[i], batches[i] = w, b
+			var totalWords, totalBatches int
+			for j := range chunks {
+				totalWords += words[j]
+				totalBatches += batches[j]
+			}
+			progress(totalWords, totalBatches)
+		}
+	}
+
+	results := make([]map[string][]string, len(chunks))
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// паника в отдельной горутине не перехватывается вызывающим, поэтому
+			// она возвращается как ошибка и останавливает остальные фрагменты
+			defer func() {
+				if value := recover(); value != nil {
+					cancel(&panicError{value: value, stack: debug.Stack()})
+				}
+			}()
+
+			section := io.NewSectionReader(file, c.start, c.end-c.start)
+			part, err := groupReader(ctx, section, caseSensitive, batchSize, group, chunkProgress(i))
+			if err != nil {
+				cancel(err)
+				return
+			}
+			results[i] = part
+		}()
+	}
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	groups := results[0]
+	for _, part := range results[1:] {
+		merge(groups, part)
+	}
+	return groups, nil
+}
@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grcflEgor/go-anagram-api/pkg/anagram"
+)
+
+func writeWordsFile(t *testing.T, count int) *os.File {
+	t.Helper()
+
+	words := []string{"кот", "ток", "окт", "рост", "торс", "сорт", "listen", "silent", "enlist", "ёж"}
+	seps := []string{"\n", " ", "\t", "\r\n", "  "}
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&b, "%s%d%s", words[i%len(words)], i%7, seps[i%len(seps)])
+	}
+
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open test file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func scanWords(t *testing.T, r io.Reader) []string {
+	t.Helper()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	var words []string
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	return words
+}
+
+func TestSplitChunks_KeepsWordsWhole(t *testing.T) {
+	file := writeWordsFile(t, 5000)
+	info, _ := file.Stat()
+	want := scanWords(t, io.NewSectionReader(file, 0, info.Size()))
+
+	for _, parts := range []int{1, 2, 3, 7, 16} {
+		chunks, err := splitChunks(file, info.Size(), parts, 64)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(chunks) > parts {
+			t.Errorf("parts=%d: expected at most %d chunks, got %d", parts, parts, len(chunks))
+		}
+
+		var got []string
+		var next int64
+		for _, c := range chunks {
+			if c.start != next || c.end <= c.start {
+				t.Fatalf("parts=%d: chunks are not contiguous: %+v", parts, chunks)
+			}
+			next = c.end
+			got = append(got, scanWords(t, io.NewSectionReader(file, c.start, c.end-c.start))...)
+		}
+		if next != info.Size() {
+			t.Errorf("parts=%d: chunks end at %d, file size %d", parts, next, info.Size())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parts=%d: chunked words differ from sequential scan", parts)
+		}
+	}
+}
+
+func TestSplitChunks_SmallFileIsSingleChunk(t *testing.T) {
+	file := writeWordsFile(t, 10)
+	info, _ := file.Stat()
+
+	chunks, err := splitChunks(file, info.Size(), 8, DefaultMinChunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].start != 0 || chunks[0].end != info.Size() {
+		t.Errorf("expected a single chunk for small file, got %+v", chunks)
+	}
+}
+
+func TestGroupFile_MatchesSequentialGrouping(t *testing.T) {
+	file := writeWordsFile(t, 20000)
+	ctx := context.Background()
+
+	want, err := groupReader(ctx, io.NewSectionReader(file, 0, 1<<30), false, 100, anagram.Group, func(int, int) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var lastWords, calls int
+	got, err := groupFile(ctx, file, false, 100, 4, 1024, anagram.Group, func(words, batches int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if words < lastWords {
+			t.Errorf("progress went backwards: %d after %d", words, lastWords)
+		}
+		lastWords = words
+		calls++
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Error("parallel grouping differs from sequential grouping")
+	}
+	if lastWords != 20000 || calls < 200 {
+		t.Errorf("expected progress for all 20000 words in at least 200 batches, got %d words in %d calls", lastWords, calls)
+	}
+}
+
+func TestGroupFile_StopsOnChunkPanic(t *testing.T) {
+	file := writeWordsFile(t, 20000)
+
+	var calls sync.Map
+	group := func(ctx context.Context, words []string, caseSensitive bool) (map[string][]string, error) {
+		for _, word := range words {
+			if strings.HasPrefix(word, "ёж") {
+				if _, loaded := calls.LoadOrStore("panicked", true); !loaded {
+					panic("bad word")
+				}
+			}
+		}
+		return anagram.Group(ctx, words, caseSensitive)
+	}
+
+	_, err := groupFile(context.Background(), file, false, 100, 4, 1024, group, func(int, int) {})
+	var panicErr *panicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, ErrTaskPanicked) {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+}
+
+func TestGroupFile_HonorsContext(t *testing.T) {
+	file := writeWordsFile(t, 20000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := groupFile(ctx, file, false, 100, 4, 1024, anagram.Group, func(int, int) {}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
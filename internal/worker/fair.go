@@ -0,0 +1,315 @@
+package worker
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+)
+
+// DefaultFairBufferSize - сколько задач диспетчер заранее разбирает из очередей по клиентам
+const DefaultFairBufferSize = 1000
+
+// clientTasks - задачи одного клиента, разобранные из очередей, но еще не взятые
+// воркерами, по приоритетам
+type clientTasks struct {
+	tasks   map[domain.TaskPriority][]*domain.Task
+	queued  int
+	running int
+}
+
+// fairScheduler распределяет задачи между клиентами по кругу, чтобы клиент
+// с большим количеством задач не вытеснял остальных. Диспетчер переносит
+// задачи из очередей приоритетов в буферы клиентов, разделенные по приоритетам.
+// Воркер сначала выбирает приоритет по весам планировщика среди буферов,
+// где есть задачи клиентов, не превысивших лимит одновременных задач, а затем
+// берет задачу этого приоритета у следующего по кругу клиента.
+// Задача считается взятой из очереди (Claim) только когда ее берет воркер,
+// поэтому отмена, глубина очереди и ее размер учитывают и задачи в буферах.
+type fairScheduler struct {
+	source *scheduler
+
+	mu      sync.Mutex
+	clients map[string]*clientTasks
+	// rings - клиенты с задачами в буфере каждого приоритета, в порядке обхода
+	rings          map[domain.TaskPriority][]string
+	cursors        map[domain.TaskPriority]int
+	current        map[domain.TaskPriority]int
+	buffered       int
+	capacity       int
+	limit          int
+	closed         bool
+	changed        chan struct{}
+	dispatcherDone chan struct{}
+}
+
+func newFairScheduler(source *scheduler) *fairScheduler {
+	return &fairScheduler{
+		source:   source,
+		clients:  make(map[string]*clientTasks),
+		rings:    make(map[domain.TaskPriority][]string, len(domain.Priorities)),
+		cursors:  make(map[domain.TaskPriority]int, len(domain.Priorities)),
+		current:  make(map[domain.TaskPriority]int, len(domain.Priorities)),
+		capacity: DefaultFairBufferSize,
+		changed:  make(chan struct{}),
+	}
+}
+
+func (f *fairScheduler) setLimits(perClient, capacity int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.limit = perClient
+	if capacity > 0 {
+		f.capacity = capacity
+	}
+	f.broadcastLocked()
+}
+
+// broadcastLocked будит всех, кто ждет изменения буферов
+func (f *fairScheduler) broadcastLocked() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// start запускает диспетчер, если он еще не запущен. Диспетчер завершается,
+// когда очереди закрыты и пусты либо закрыт quit.
+func (f *fairScheduler) start(quit <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.dispatcherDone != nil {
+		return
+	}
+	f.dispatcherDone = make(chan struct{})
+	go f.dispatch(quit, f.dispatcherDone)
+}
+
+func (f *fairScheduler) dispatch(quit <-chan struct{}, done chan struct{}) {
+	defer close(done)
+	defer func() {
+		f.mu.Lock()
+		f.closed = true
+		f.broadcastLocked()
+		f.mu.Unlock()
+	}()
+
+	for {
+		if !f.waitCapacity(quit) {
+			return
+		}
+		task, ok := f.source.receive(quit)
+		if !ok {
+			return
+		}
+		f.push(task)
+	}
+}
+
+func (f *fairScheduler) waitCapacity(quit <-chan struct{}) bool {
+	for {
+		f.mu.Lock()
+		if f.buffered < f.capacity {
+			f.mu.Unlock()
+			return true
+		}
+		changed := f.changed
+		f.mu.Unlock()
+
+		select {
+		case <-quit:
+			return false
+		case <-changed:
+		}
+	}
+}
+
+func (f *fairScheduler) push(task *domain.Task) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[task.ClientID]
+	if !ok {
+		client = &clientTasks{tasks: make(map[domain.TaskPriority][]*domain.Task, len(domain.Priorities))}
+		f.clients[task.ClientID] = client
+	}
+	priority := bufferPriority(task.Priority)
+	if len(client.tasks[priority]) == 0 {
+		f.rings[priority] = append(f.rings[priority], task.ClientID)
+	}
+	client.tasks[priority] = append(client.tasks[priority], task)
+	client.queued++
+	f.buffered++
+	f.broadcastLocked()
+}
+
+// bufferPriority возвращает буфер задачи; как и в очередях, задача
+// без приоритета попадает в normal
+func bufferPriority(priority domain.TaskPriority) domain.TaskPriority {
+	if slices.Contains(domain.Priorities, priority) {
+		return priority
+	}
+	return domain.PriorityNormal
+}
+
+// next возвращает задачу следующего по кругу клиента или false, если диспетчер
+// остановлен и буферы пусты либо закрыт quit. Задачи, удаленные из очереди
+// после постановки, пропускаются.
+func (f *fairScheduler) next(quit <-chan struct{}) (*domain.Task, bool) {
+	for {
+		select {
+		case <-quit:
+			return nil, false
+		default:
+		}
+
+		f.mu.Lock()
+		task := f.pickLocked()
+		if task == nil && f.closed && f.buffered == 0 {
+			f.mu.Unlock()
+			return nil, false
+		}
+		changed := f.changed
+		f.mu.Unlock()
+
+		if task != nil {
+			if f.source.claim(task) {
+				return task, true
+			}
+			f.finish(task)
+			continue
+		}
+
+		select {
+		case <-quit:
+			return nil, false
+		case <-changed:
+		}
+	}
+}
+
+// pickLocked выбирает приоритет по весам среди буферов, из которых можно
+// взять задачу, и берет первую задачу этого приоритета у следующего по кругу
+// клиента, который не исчерпал лимит одновременных задач
+func (f *fairScheduler) pickLocked() *domain.Task {
+	ready := make(map[domain.TaskPriority]int, len(domain.Priorities))
+	total := 0
+	for _, priority := range domain.Priorities {
+		idx := f.readyClientLocked(priority)
+		if idx < 0 {
+			continue
+		}
+		ready[priority] = idx
+		total += f.source.weight(priority)
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	// smooth weighted round-robin, как в scheduler.pick, но только
+	// по приоритетам, задачу которых можно взять
+	var best domain.TaskPriority
+	for _, priority := range domain.Priorities {
+		if _, ok := ready[priority]; !ok {
+			continue
+		}
+		f.current[priority] += f.source.weight(priority)
+		if best == "" || f.current[priority] > f.current[best] {
+			best = priority
+		}
+	}
+	f.current[best] -= total
+
+	return f.takeLocked(best, ready[best])
+}
+
+// readyClientLocked возвращает индекс в круге приоритета следующего клиента,
+// который не исчерпал лимит, или -1
+func (f *fairScheduler) readyClientLocked(priority domain.TaskPriority) int {
+	ring := f.rings[priority]
+	for i := 0; i < len(ring); i++ {
+		idx := (f.cursors[priority] + i) % len(ring)
+		if f.limit > 0 && f.clients[ring[idx]].running >= f.limit {
+			continue
+		}
+		return idx
+	}
+	return -1
+}
+
+func (f *fairScheduler) takeLocked(priority domain.TaskPriority, idx int) *domain.Task {
+	ring := f.rings[priority]
+	client := f.clients[ring[idx]]
+
+	tasks := client.tasks[priority]
+	task := tasks[0]
+	tasks[0] = nil
+	client.tasks[priority] = tasks[1:]
+	client.queued--
+	client.running++
+	f.buffered--
+
+	cursor := idx + 1
+	if len(client.tasks[priority]) == 0 {
+		delete(client.tasks, priority)
+		ring = append(ring[:idx], ring[idx+1:]...)
+		f.rings[priority] = ring
+		cursor = idx
+	}
+	if len(ring) > 0 {
+		f.cursors[priority] = cursor % len(ring)
+	} else {
+		f.cursors[priority] = 0
+	}
+
+	f.broadcastLocked()
+	return task
+}
+
+// finish освобождает место клиента после обработки задачи
+func (f *fairScheduler) finish(task *domain.Task) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[task.ClientID]
+	if !ok {
+		return
+	}
+	client.running--
+	if client.running <= 0 && client.queued == 0 {
+		delete(f.clients, task.ClientID)
+	}
+	f.broadcastLocked()
+}
+
+// stop дожидается остановки диспетчера и возвращает задачи из буферов,
+// отмечая их взятыми из очереди. Удаленные из очереди задачи пропускаются.
+func (f *fairScheduler) stop() []*domain.Task {
+	f.mu.Lock()
+	done := f.dispatcherDone
+	f.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var tasks []*domain.Task
+	for _, priority := range domain.Priorities {
+		for _, id := range f.rings[priority] {
+			client := f.clients[id]
+			for _, task := range client.tasks[priority] {
+				if f.source.claim(task) {
+					tasks = append(tasks, task)
+				}
+			}
+			client.queued -= len(client.tasks[priority])
+			delete(client.tasks, priority)
+		}
+		delete(f.rings, priority)
+		delete(f.cursors, priority)
+	}
+	f.buffered = 0
+	return tasks
+}
@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+)
+
+func startFairScheduler(t *testing.T, q *queue.Queues, perClient, expected int) *fairScheduler {
+	t.Helper()
+
+	f := newFairScheduler(newScheduler(q, DefaultPriorityWeights))
+	f.setLimits(perClient, 0)
+
+	quit := make(chan struct{})
+	f.start(quit)
+	t.Cleanup(func() {
+		close(quit)
+		f.stop()
+	})
+
+	waitBuffered(t, f, expected)
+	return f
+}
+
+func waitBuffered(t *testing.T, f *fairScheduler, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		f.mu.Lock()
+		buffered := f.buffered
+		f.mu.Unlock()
+		if buffered == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d buffered tasks, got %d", expected, buffered)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFairScheduler_RoundRobinAcrossClients(t *testing.T) {
+	q := queue.NewQueues(100)
+	for i := 0; i < 6; i++ {
+		q.Push(&domain.Task{ID: fmt.Sprintf("a%d", i), ClientID: "a"})
+	}
+	for i := 0; i < 2; i++ {
+		q.Push(&domain.Task{ID: fmt.Sprintf("b%d", i), ClientID: "b"})
+	}
+	f := startFairScheduler(t, q, 0, 8)
+
+	var order []string
+	for i := 0; i < 8; i++ {
+		task, ok := f.next(nil)
+		if !ok {
+			t.Fatal("expected task")
+		}
+		order = append(order, task.ClientID)
+		f.finish(task)
+	}
+
+	if got := fmt.Sprint(order); got != "[a b a b a a a a]" {
+		t.Errorf("expected clients to alternate while both have tasks, got %s", got)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queues, got %d", q.Len())
+	}
+}
+
+func TestFairScheduler_PriorityWithinAndAcrossClients(t *testing.T) {
+	q := queue.NewQueues(100)
+	for i := 0; i < 20; i++ {
+		q.Push(&domain.Task{ID: fmt.Sprintf("a-low%d", i), ClientID: "a", Priority: domain.PriorityLow})
+	}
+	q.Push(&domain.Task{ID: "a-high", ClientID: "a", Priority: domain.PriorityHigh})
+	for i := 0; i < 2; i++ {
+		q.Push(&domain.Task{ID: fmt.Sprintf("b-low%d", i), ClientID: "b", Priority: domain.PriorityLow})
+	}
+	f := startFairScheduler(t, q, 0, 23)
+
+	var order []string
+	for i := 0; i < 6; i++ {
+		task, ok := f.next(nil)
+		if !ok {
+			t.Fatal("expected task")
+		}
+		order = append(order, task.ID)
+		f.finish(task)
+	}
+
+	if got := fmt.Sprint(order); got != "[a-high a-low0 b-low0 a-low1 b-low1 a-low2]" {
+		t.Errorf("expected high task first and clients to alternate within low priority, got %s", got)
+	}
+}
+
+func TestFairScheduler_ClientConcurrencyLimit(t *testing.T) {
+	q := queue.NewQueues(10)
+	q.Push(&domain.Task{ID: "a1", ClientID: "a"})
+	q.Push(&domain.Task{ID: "a2", ClientID: "a"})
+	f := startFairScheduler(t, q, 1, 2)
+
+	first, ok := f.next(nil)
+	if !ok || first.ID != "a1" {
+		t.Fatalf("expected first task of client, got %+v", first)
+	}
+
+	quit := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(quit) })
+	if task, ok := f.next(quit); ok {
+		t.Fatalf("expected client at its limit to wait, got %+v", task)
+	}
+
+	f.finish(first)
+	if second, ok := f.next(nil); !ok || second.ID != "a2" {
+		t.Fatalf("expected second task after first finished, got %+v", second)
+	}
+}
+
+func TestFairScheduler_SkipsRemovedTasks(t *testing.T) {
+	q := queue.NewQueues(10)
+	q.Push(&domain.Task{ID: "removed"})
+	q.Push(&domain.Task{ID: "kept"})
+	f := startFairScheduler(t, q, 0, 2)
+
+	if !q.Remove("removed") {
+		t.Fatal("expected queued task to be removed")
+	}
+	if q.Remove("removed") {
+		t.Error("expected second remove to report missing task")
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected 1 pending task, got %d", q.Len())
+	}
+
+	if task, ok := f.next(nil); !ok || task.ID != "kept" {
+		t.Fatalf("expected removed task to be skipped, got %+v", task)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queues, got %d", q.Len())
+	}
+}
+
+func TestFairScheduler_BufferedTasksKeepQueueFull(t *testing.T) {
+	q := queue.NewQueues(2)
+	q.Push(&domain.Task{ID: "a1", ClientID: "a", Priority: domain.PriorityHigh})
+	q.Push(&domain.Task{ID: "b1", ClientID: "b", Priority: domain.PriorityLow})
+	f := startFairScheduler(t, q, 0, 2)
+
+	ctx, cancel := queue.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "c1", ClientID: "c"}); !errors.Is(err, queue.ErrQueueFull) {
+		t.Fatalf("expected buffered tasks to keep the queue full, got %v", err)
+	}
+
+	task, ok := f.next(nil)
+	if !ok {
+		t.Fatal("expected task")
+	}
+	f.finish(task)
+
+	ctx, cancel = queue.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := q.Enqueue(ctx, &domain.Task{ID: "c1", ClientID: "c"}); err != nil {
+		t.Errorf("expected claimed task to free space, got %v", err)
+	}
+}
+
+func TestFairScheduler_StopReturnsBufferedTasks(t *testing.T) {
+	q := queue.NewQueues(10)
+	q.Push(&domain.Task{ID: "a1", ClientID: "a"})
+	q.Push(&domain.Task{ID: "b1", ClientID: "b"})
+
+	f := newFairScheduler(newScheduler(q, DefaultPriorityWeights))
+	quit := make(chan struct{})
+	f.start(quit)
+	waitBuffered(t, f, 2)
+
+	close(quit)
+	tasks := f.stop()
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 buffered tasks, got %d", len(tasks))
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected returned tasks to be claimed, got %d pending", q.Len())
+	}
+	if _, ok := f.next(nil); ok {
+		t.Error("expected no tasks after stop")
+	}
+}
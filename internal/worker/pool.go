@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/service"
 	"github.com/grcflEgor/go-anagram-api/internal/storage"
 	"github.com/grcflEgor/go-anagram-api/pkg/anagram"
@@ -19,90 +23,314 @@ import (
 	"go.uber.org/zap"
 )
 
+var _ service.TaskCanceller = (*Pool)(nil)
+var _ service.WorkerPoolScaler = (*Pool)(nil)
+var _ service.TaskSource = (*Pool)(nil)
+var _ service.TaskRetrier = (*Pool)(nil)
+
+// DefaultProgressInterval - интервал сохранения прогресса обработки файла
+const DefaultProgressInterval = time.Second
+
+// DefaultFileParallelism - сколько фрагментов одного файла обрабатывается параллельно
+const DefaultFileParallelism = 4
+
+var (
+	ErrPoolStopped     = errors.New("worker pool is stopped")
+	ErrInvalidPoolSize = errors.New("invalid worker pool size")
+)
+
+// ErrTaskCancelled - причина отмены контекста задачи по запросу клиента
+var ErrTaskCancelled = errors.New("task cancelled")
+
+// ErrShutdownInterrupted - причина отмены контекста задачи, которую не успели
+// обработать за время остановки пула; такая задача возвращается из Shutdown
+var ErrShutdownInterrupted = errors.New("task interrupted by shutdown")
+
+// groupFunc группирует слова по анаграммам; в тестах подменяется
+type groupFunc func(ctx context.Context, words []string, caseSensitive bool) (map[string][]string, error)
+
+// ErrTaskPanicked - ошибка, которую получает задача, если при ее обработке
+// произошла паника; подробности попадают только в лог и трейс
+var ErrTaskPanicked = errors.New("internal error while processing task")
+
+// panicError хранит значение паники и стек вызовов
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func (e *panicError) Unwrap() error {
+	return ErrTaskPanicked
+}
+
 type Pool struct {
 	storage           storage.TaskStorage
-	taskQueue         chan *domain.Task
+	queues            queue.TaskQueue
+	scheduler         *scheduler
+	fair              *fairScheduler
 	logger            *zap.Logger
 	wg                sync.WaitGroup
 	processingTimeout time.Duration
 	stats             *service.TaskStats
 	batchSize         int
+	retryPolicy       RetryPolicy
+	progressInterval  time.Duration
+	groupWords        groupFunc
+	fileParallelism   int
+	minChunkSize      int64
+
+	// mu защищает постановку отложенных повторов от закрытия очередей в Stop
+	mu      sync.RWMutex
+	stopped bool
+	done    chan struct{}
+	// dispatchQuit останавливает диспетчер, не дожидаясь разбора очередей
+	dispatchQuit chan struct{}
+
+	tasksMu     sync.Mutex
+	running     map[string]context.CancelCauseFunc
+	delayed     map[string]*delayedRetry
+	interrupted []*domain.Task
+
+	// workers хранит каналы остановки запущенных воркеров; воркер,
+	// чей канал закрыт, завершается после текущей задачи
+	workersMu    sync.Mutex
+	workers      []chan struct{}
+	nextWorkerID int
+	minWorkers   int
+	maxWorkers   int
+
+	queueWait queueWaitTracker
 }
 
-func NewPool(storage storage.TaskStorage, taskQueue chan *domain.Task, logger *zap.Logger, processingTimeout time.Duration, stats *service.TaskStats, batchSize int) *Pool {
+func NewPool(storage storage.TaskStorage, queues queue.TaskQueue, logger *zap.Logger, processingTimeout time.Duration, stats *service.TaskStats, batchSize int) *Pool {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
 
+	scheduler := newScheduler(queues, DefaultPriorityWeights)
 	return &Pool{
 		storage:           storage,
-		taskQueue:         taskQueue,
+		queues:            queues,
+		scheduler:         scheduler,
+		fair:              newFairScheduler(scheduler),
+		dispatchQuit:      make(chan struct{}),
+		retryPolicy:       DefaultRetryPolicy,
+		progressInterval:  DefaultProgressInterval,
+		done:              make(chan struct{}),
+		running:           make(map[string]context.CancelCauseFunc),
+		delayed:           make(map[string]*delayedRetry),
 		logger:            logger,
 		processingTimeout: processingTimeout,
 		stats:             stats,
 		batchSize:         batchSize,
+		groupWords:        anagram.Group,
+		fileParallelism:   DefaultFileParallelism,
+		minChunkSize:      DefaultMinChunkSize,
 	}
 }
 
+// SetFileParallelism задает, на сколько фрагментов делится файл задачи для
+// параллельной группировки. 1 - файл читается последовательно.
+func (pool *Pool) SetFileParallelism(parallelism int) {
+	if parallelism > 0 {
+		pool.fileParallelism = parallelism
+	}
+}
+
+// SetPriorityWeights задает доли, в которых воркеры берут задачи из очередей разных приоритетов
+func (pool *Pool) SetPriorityWeights(weights map[domain.TaskPriority]int) {
+	pool.scheduler.setWeights(weights)
+}
+
+// SetClientLimits задает, сколько задач одного клиента могут обрабатываться
+// одновременно (0 - без ограничения), и сколько задач диспетчер заранее
+// разбирает из очередей по клиентам
+func (pool *Pool) SetClientLimits(maxConcurrency, bufferSize int) {
+	pool.fair.setLimits(maxConcurrency, bufferSize)
+}
+
+func (pool *Pool) SetRetryPolicy(policy RetryPolicy) {
+	pool.retryPolicy = policy
+}
+
+// SetProgressInterval задает, как часто прогресс обработки файла сохраняется в хранилище
+func (pool *Pool) SetProgressInterval(interval time.Duration) {
+	pool.progressInterval = interval
+}
+
+// SetWorkerLimits задает границы, в которых Resize и автомасштабирование
+// могут менять количество воркеров. 0 снимает ограничение сверху.
+func (pool *Pool) SetWorkerLimits(minWorkers, maxWorkers int) {
+	pool.workersMu.Lock()
+	defer pool.workersMu.Unlock()
+
+	pool.minWorkers = minWorkers
+	pool.maxWorkers = maxWorkers
+}
+
+// Run запускает numWorkers воркеров, приводя их количество к границам SetWorkerLimits
 func (pool *Pool) Run(numWorkers int) {
-	for i := 0; i < numWorkers; i++ {
+	pool.workersMu.Lock()
+	if pool.maxWorkers > 0 {
+		numWorkers = min(numWorkers, pool.maxWorkers)
+	}
+	numWorkers = max(numWorkers, pool.minWorkers, 1)
+	pool.workersMu.Unlock()
+
+	if err := pool.Resize(numWorkers); err != nil {
+		pool.logger.Warn("failed to start workers", zap.Int("workers", numWorkers), zap.Error(err))
+	}
+}
+
+// Size возвращает текущее количество воркеров
+func (pool *Pool) Size() int {
+	pool.workersMu.Lock()
+	defer pool.workersMu.Unlock()
+	return len(pool.workers)
+}
+
+// Resize запускает недостающих воркеров или останавливает лишних.
+// Останавливаемые воркеры дорабатывают текущую задачу.
+func (pool *Pool) Resize(size int) error {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	if pool.stopped {
+		return ErrPoolStopped
+	}
+
+	pool.workersMu.Lock()
+	defer pool.workersMu.Unlock()
+
+	if size < 1 || size < pool.minWorkers || (pool.maxWorkers > 0 && size > pool.maxWorkers) {
+		return fmt.Errorf("%w: %d is outside [%d, %d]", ErrInvalidPoolSize, size, max(pool.minWorkers, 1), pool.maxWorkers)
+	}
+
+	pool.fair.start(pool.dispatchQuit)
+
+	previous := len(pool.workers)
+	for len(pool.workers) < size {
+		quit := make(chan struct{})
+		pool.workers = append(pool.workers, quit)
+		pool.nextWorkerID++
 		pool.wg.Add(1)
-		go pool.worker(i + 1)
+		go pool.worker(pool.nextWorkerID, quit)
 	}
+	for len(pool.workers) > size {
+		last := len(pool.workers) - 1
+		close(pool.workers[last])
+		pool.workers = pool.workers[:last]
+	}
+
+	if previous != size {
+		pool.logger.Info("worker pool resized", zap.Int("from", previous), zap.Int("to", size))
+	}
+	return nil
 }
 
-func (pool *Pool) worker(id int) {
+func (pool *Pool) worker(id int, quit <-chan struct{}) {
 	defer pool.wg.Done()
 
 	workerLog := pool.logger.With(zap.Int("worker_id", id))
 	workerLog.Info("worker started")
+	defer workerLog.Info("worker stopped")
 	tr := otel.Tracer("worker")
 
-	for task := range pool.taskQueue {
+	for {
+		task, ok := pool.fair.next(quit)
+		if !ok {
+			return
+		}
+		if !task.EnqueuedAt.IsZero() {
+			pool.queueWait.observe(time.Since(task.EnqueuedAt))
+		}
+
 		func(task *domain.Task) {
+			defer pool.fair.finish(task)
+
 			parentCtx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(task.TraceContext))
 
-			taskCtx, cancel := context.WithTimeout(parentCtx, pool.processingTimeout)
-			defer cancel()
+			timeoutCtx, cancelTimeout := context.WithTimeout(parentCtx, pool.processingTimeout)
+			defer cancelTimeout()
+			taskCtx, cancel := context.WithCancelCause(timeoutCtx)
+			defer cancel(nil)
+
+			pool.trackRunning(task.ID, cancel)
+			defer pool.untrackRunning(task.ID)
 
 			spanCtx, span := tr.Start(taskCtx, "process_task")
 			defer span.End()
 
-			span.SetAttributes(attribute.String("task_id", task.ID))
+			span.SetAttributes(attribute.String("task_id", task.ID), attribute.String("priority", string(task.Priority)))
 			taskLog := workerLog.With(zap.String("task_id", task.ID))
-			taskLog.Info("processing task")
+			task.Attempts++
+			span.SetAttributes(attribute.Int("attempt", task.Attempts))
+			taskLog.Info("processing task", zap.Int("attempt", task.Attempts))
 
 			start := time.Now()
+			pool.startProgress(task, start)
 
-			var grouped map[string][]string
-			var err error
-
-			if task.FilePath != "" {
-				grouped, err = pool.processFile(spanCtx, task.FilePath, task.CaseSensitive)
-				if removeErr := os.Remove(task.FilePath); removeErr != nil {
-					workerLog.Warn("failed to remove file", zap.Error(removeErr))
-				}
-			} else {
-				grouped, err = anagram.Group(spanCtx, task.Words, task.CaseSensitive)
-			}
+			grouped, err := pool.process(spanCtx, task, taskLog)
 
 			processingTime := time.Since(start).Milliseconds()
 			span.SetAttributes(attribute.Int64("processing_ms", processingTime))
 
+			if err != nil && errors.Is(context.Cause(taskCtx), ErrShutdownInterrupted) {
+				// попытка не засчитывается, задача вернется в очередь после перезапуска
+				taskLog.Info("task interrupted by shutdown")
+				task.Attempts--
+				task.Progress = nil
+				pool.tasksMu.Lock()
+				pool.interrupted = append(pool.interrupted, task)
+				pool.tasksMu.Unlock()
+				return
+			}
+
+			retry := false
 			if err != nil {
-				if errors.Is(err, context.DeadlineExceeded) {
+				message := err.Error()
+				cancelled := errors.Is(context.Cause(taskCtx), ErrTaskCancelled)
+				var panicErr *panicError
+				panicked := errors.As(err, &panicErr)
+				if panicked {
+					taskLog.Error("task processing panicked", zap.Any("panic", panicErr.value), zap.ByteString("stack", panicErr.stack))
+					span.SetAttributes(attribute.String("panic.stack", string(panicErr.stack)))
+					message = ErrTaskPanicked.Error()
+					pool.stats.IncrementPanickedTasks()
+				} else if cancelled {
+					taskLog.Info("task cancelled")
+					message = ErrTaskCancelled.Error()
+				} else if errors.Is(err, context.DeadlineExceeded) {
 					taskLog.Warn("task processing timeout")
-					task.Status = domain.StatusFailed
-					task.Error = "task processing timeout"
-					pool.stats.IncrementFailedTasks()
+					message = "task processing timeout"
 				} else {
 					taskLog.Error("task processing failed", zap.Error(err))
+				}
+				task.LastError = message
+
+				switch {
+				case cancelled:
+					task.Status = domain.StatusCancelled
+					task.Error = message
+					pool.stats.IncrementCancelledTasks()
+				case pool.retryPolicy.shouldRetry(err, task.Attempts):
+					retry = true
+					pool.stats.IncrementRetriedTasks()
+				case pool.retryPolicy.retryable(err):
+					taskLog.Warn("retry attempts exhausted, moving task to dead letter", zap.Int("attempts", task.Attempts))
+					task.Status = domain.StatusDeadLetter
+					task.Error = message
+					pool.stats.IncrementDeadLetterTasks()
+				default:
 					task.Status = domain.StatusFailed
-					task.Error = err.Error()
+					task.Error = message
 					pool.stats.IncrementFailedTasks()
 				}
 				span.RecordError(err)
-				span.SetAttributes(attribute.String("status", "failed"))
+				span.SetAttributes(attribute.String("status", string(task.Status)), attribute.Bool("retry", retry))
 			} else {
 				result := make([][]string, 0, len(grouped))
 				for _, group := range grouped {
@@ -121,20 +349,284 @@ func (pool *Pool) worker(id int) {
 			}
 
 			if err := pool.storage.Save(context.Background(), task); err != nil {
-				taskLog.Error("failed to save completed task", zap.String("status", string(task.Status)), zap.Error(err))
 				span.RecordError(err)
+				if !errors.Is(err, storage.ErrVersionConflict) {
+					// итоговый статус не сохранен, и задача в хранилище осталась
+					// в обработке, поэтому ее файл нужен для восстановления
+					taskLog.Error("failed to save completed task", zap.String("status", string(task.Status)), zap.Error(err))
+					return
+				}
+				taskLog.Warn("task was modified concurrently, result discarded", zap.Error(err))
+				retry = false
+			}
+
+			if retry {
+				backoff := pool.retryPolicy.backoff(task.Attempts)
+				taskLog.Info("task scheduled for retry", zap.Duration("backoff", backoff))
+				pool.scheduleRetry(task, backoff)
+				return
+			}
+
+			// файл удаляется только после сохранения итогового статуса,
+			// чтобы при падении между этими шагами задачу можно было восстановить.
+			// Входные данные задач в dead letter сохраняются для повторной постановки.
+			if task.FilePath != "" && task.Status != domain.StatusDeadLetter {
+				if removeErr := os.Remove(task.FilePath); removeErr != nil {
+					workerLog.Warn("failed to remove file", zap.Error(removeErr))
+				}
 			}
 			taskLog.Info("finished task")
 		}(task)
 	}
 }
 
+// Take выдает следующую задачу очереди для обработки вне пула, например
+// удаленным воркером, соблюдая приоритеты и лимиты клиентов. После обработки
+// задачу нужно вернуть через Release.
+func (pool *Pool) Take(ctx context.Context) (*domain.Task, error) {
+	quit := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() { close(quit) })
+	defer stop()
+
+	task, ok := pool.fair.next(quit)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrPoolStopped
+	}
+	if !task.EnqueuedAt.IsZero() {
+		pool.queueWait.observe(time.Since(task.EnqueuedAt))
+	}
+	return task, nil
+}
+
+// Release освобождает место клиента, занятое задачей из Take
+func (pool *Pool) Release(task *domain.Task) {
+	pool.fair.finish(task)
+}
+
+// ShouldRetry сообщает, нужно ли повторить задачу из Take после attempts попыток
+func (pool *Pool) ShouldRetry(err error, attempts int) bool {
+	return pool.retryPolicy.shouldRetry(err, attempts)
+}
+
+// Retryable сообщает, считает ли политика повторов ошибку временной
+func (pool *Pool) Retryable(err error) bool {
+	return pool.retryPolicy.retryable(err)
+}
+
+// Retry возвращает задачу из Take в очередь после задержки политики повторов
+func (pool *Pool) Retry(task *domain.Task) {
+	pool.scheduleRetry(task, pool.retryPolicy.backoff(task.Attempts))
+}
+
+// scheduleRetry возвращает задачу в очередь после задержки.
+// Если пул остановлен раньше, задача остается в статусе processing:
+// Shutdown вернет ее для сохранения, а без снимка ее восстановит журнал.
+func (pool *Pool) scheduleRetry(task *domain.Task, backoff time.Duration) {
+	timer := time.NewTimer(backoff)
+	cancelled := make(chan struct{})
+
+	pool.tasksMu.Lock()
+	pool.delayed[task.ID] = &delayedRetry{task: task, cancelled: cancelled}
+	pool.tasksMu.Unlock()
+
+	go func() {
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-pool.done:
+			return
+		case <-cancelled:
+			return
+		}
+
+		pool.mu.RLock()
+		defer pool.mu.RUnlock()
+		if pool.stopped {
+			return
+		}
+
+		pool.tasksMu.Lock()
+		delete(pool.delayed, task.ID)
+		pool.tasksMu.Unlock()
+
+		if err := pool.queues.Enqueue(context.Background(), task); err != nil {
+			pool.logger.Error("failed to requeue task for retry", zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}()
+}
+
+// delayedRetry - задача, ожидающая повторной постановки в очередь
+type delayedRetry struct {
+	task      *domain.Task
+	cancelled chan struct{}
+}
+
+func (pool *Pool) trackRunning(id string, cancel context.CancelCauseFunc) {
+	pool.tasksMu.Lock()
+	defer pool.tasksMu.Unlock()
+	pool.running[id] = cancel
+}
+
+func (pool *Pool) untrackRunning(id string) {
+	pool.tasksMu.Lock()
+	defer pool.tasksMu.Unlock()
+	delete(pool.running, id)
+}
+
+// CancelTask отменяет контекст выполняющейся задачи или отложенный повтор.
+// Возвращает true, если задача выполнялась: итоговый статус сохранит воркер.
+// Для отложенного повтора и неизвестной задачи статус сохраняет вызывающий.
+func (pool *Pool) CancelTask(id string) bool {
+	pool.tasksMu.Lock()
+	defer pool.tasksMu.Unlock()
+
+	if cancel, ok := pool.running[id]; ok {
+		cancel(ErrTaskCancelled)
+		return true
+	}
+	if retry, ok := pool.delayed[id]; ok {
+		close(retry.cancelled)
+		delete(pool.delayed, id)
+	}
+	return false
+}
+
+// Stop останавливает пул, дожидаясь обработки всех задач из очереди
 func (pool *Pool) Stop() {
-	close(pool.taskQueue)
+	if !pool.markStopped() {
+		return
+	}
+
+	pool.queues.Close()
 	pool.wg.Wait()
+
+	pool.workersMu.Lock()
+	pool.workers = nil
+	pool.workersMu.Unlock()
 }
 
-func (pool *Pool) processFile(ctx context.Context, filePath string, caseSensitive bool) (map[string][]string, error) {
+// Shutdown останавливает пул, не разбирая очередь: воркеры дорабатывают
+// текущие задачи, а задачи из очереди и ожидающие повтора возвращаются,
+// чтобы их можно было сохранить до следующего запуска. Если ctx истекает
+// раньше, выполняющиеся задачи прерываются и тоже возвращаются.
+func (pool *Pool) Shutdown(ctx context.Context) []*domain.Task {
+	if !pool.markStopped() {
+		return nil
+	}
+
+	pool.workersMu.Lock()
+	for _, quit := range pool.workers {
+		close(quit)
+	}
+	pool.workers = nil
+	pool.workersMu.Unlock()
+	close(pool.dispatchQuit)
+
+	finished := make(chan struct{})
+	go func() {
+		pool.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		pool.tasksMu.Lock()
+		for _, cancel := range pool.running {
+			cancel(ErrShutdownInterrupted)
+		}
+		pool.tasksMu.Unlock()
+		<-finished
+	}
+
+	pool.tasksMu.Lock()
+	pending := pool.interrupted
+	pool.interrupted = nil
+	for id, retry := range pool.delayed {
+		pending = append(pending, retry.task)
+		delete(pool.delayed, id)
+	}
+	pool.tasksMu.Unlock()
+
+	pending = append(pending, pool.fair.stop()...)
+	pending = append(pending, queue.Drain(pool.queues)...)
+	pool.queues.Close()
+	return pending
+}
+
+// markStopped запрещает запуск воркеров и постановку повторов.
+// Возвращает false, если пул уже остановлен.
+func (pool *Pool) markStopped() bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.stopped {
+		return false
+	}
+	pool.stopped = true
+	close(pool.done)
+	return true
+}
+
+func (pool *Pool) startProgress(task *domain.Task, start time.Time) {
+	total := 0
+	if task.Progress != nil {
+		total = task.Progress.TotalWords
+	}
+	task.Progress = &domain.TaskProgress{
+		TotalWords: total,
+		StartedAt:  start,
+		UpdatedAt:  start,
+	}
+}
+
+// progressReporter обновляет прогресс задачи после каждого батча и сохраняет его
+// не чаще progressInterval, чтобы не перегружать хранилище и журнал
+func (pool *Pool) progressReporter(task *domain.Task, taskLog *zap.Logger) func(words, batches int) {
+	var lastSaved time.Time
+	return func(words, batches int) {
+		now := time.Now()
+		task.Progress.WordsProcessed = words
+		task.Progress.BatchesMerged = batches
+		task.Progress.UpdatedAt = now
+
+		if pool.progressInterval <= 0 || now.Sub(lastSaved) < pool.progressInterval {
+			return
+		}
+		lastSaved = now
+		if err := pool.storage.Save(context.Background(), task); err != nil {
+			taskLog.Warn("failed to save task progress", zap.Error(err))
+		}
+	}
+}
+
+// process группирует слова задачи. Паника при обработке не завершает воркер:
+// она перехватывается и возвращается как ошибка, и задача переходит в failed.
+func (pool *Pool) process(ctx context.Context, task *domain.Task, taskLog *zap.Logger) (grouped map[string][]string, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			grouped, err = nil, &panicError{value: value, stack: debug.Stack()}
+		}
+	}()
+
+	if task.FilePath != "" {
+		return pool.processFile(ctx, task.FilePath, task.CaseSensitive, pool.progressReporter(task, taskLog))
+	}
+
+	grouped, err = pool.groupWords(ctx, task.Words, task.CaseSensitive)
+	if err == nil {
+		task.Progress.WordsProcessed = len(task.Words)
+		task.Progress.BatchesMerged = 1
+		task.Progress.UpdatedAt = time.Now()
+	}
+	return grouped, err
+}
+
+func (pool *Pool) processFile(ctx context.Context, filePath string, caseSensitive bool, progress func(words, batches int)) (map[string][]string, error) {
 	l := logger.FromContext(ctx)
 
 	tr := otel.Tracer("worker")
@@ -152,11 +644,16 @@ func (pool *Pool) processFile(ctx context.Context, filePath string, caseSensitiv
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return groupFile(ctx, file, caseSensitive, pool.batchSize, pool.fileParallelism, pool.minChunkSize, pool.groupWords, progress)
+}
+
+// groupReader группирует слова из r батчами по batchSize, сообщая прогресс после каждого батча
+func groupReader(ctx context.Context, r io.Reader, caseSensitive bool, batchSize int, group groupFunc, progress func(words, batches int)) (map[string][]string, error) {
+	scanner := bufio.NewScanner(r)
 	scanner.Split(bufio.ScanWords)
-	batchSize := pool.batchSize
 	groups := make(map[string][]string)
 	var batch []string
+	var processed, batches int
 
 	for scanner.Scan() {
 		select {
@@ -169,21 +666,27 @@ func (pool *Pool) processFile(ctx context.Context, filePath string, caseSensitiv
 		batch = append(batch, word)
 
 		if len(batch) >= batchSize {
-			part, err := anagram.Group(ctx, batch, caseSensitive)
+			part, err := group(ctx, batch, caseSensitive)
 			if err != nil {
 				return nil, err
 			}
 			merge(groups, part)
+			processed += len(batch)
+			batches++
+			progress(processed, batches)
 			batch = batch[:0]
 		}
 	}
 
 	if len(batch) > 0 {
-		part, err := anagram.Group(ctx, batch, caseSensitive)
+		part, err := group(ctx, batch, caseSensitive)
 		if err != nil {
 			return nil, err
 		}
 		merge(groups, part)
+		processed += len(batch)
+		batches++
+		progress(processed, batches)
 	}
 	return groups, scanner.Err()
 }
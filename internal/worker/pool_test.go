@@ -2,12 +2,16 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
 	"github.com/grcflEgor/go-anagram-api/internal/service"
 	"github.com/grcflEgor/go-anagram-api/internal/test/integration/mocks"
 	"go.uber.org/zap"
@@ -33,7 +37,7 @@ func TestMerge(t *testing.T) {
 
 func TestStop(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	logger := zap.NewNop()
 	stats := service.NewTaskStats()
 
@@ -45,7 +49,7 @@ func TestStop(t *testing.T) {
 
 func TestWorker_ProcessWordsTask_Success(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	logger := zap.NewNop()
 	stats := service.NewTaskStats()
 
@@ -59,7 +63,7 @@ func TestWorker_ProcessWordsTask_Success(t *testing.T) {
 		CaseSensitive: false,
 		TraceContext:  make(map[string]string),
 	}
-	taskQueue <- task
+	taskQueue.Push(task)
 
 	time.Sleep(200 * time.Millisecond)
 
@@ -77,7 +81,7 @@ func TestWorker_ProcessWordsTask_Success(t *testing.T) {
 
 func TestWorker_ProcessFileTask_Error(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	logger := zap.NewNop()
 	stats := service.NewTaskStats()
 
@@ -89,7 +93,7 @@ func TestWorker_ProcessFileTask_Error(t *testing.T) {
 		ID:       "t2",
 		FilePath: "not_exists.txt",
 	}
-	taskQueue <- task
+	taskQueue.Push(task)
 
 	time.Sleep(200 * time.Millisecond)
 
@@ -104,7 +108,7 @@ func TestWorker_ProcessFileTask_Error(t *testing.T) {
 
 func TestWorker_ProcessFileTask_Success(t *testing.T) {
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	logger := zap.NewNop()
 	stats := service.NewTaskStats()
 
@@ -123,7 +127,7 @@ func TestWorker_ProcessFileTask_Success(t *testing.T) {
 		ID:       "t3",
 		FilePath: filePath,
 	}
-	taskQueue <- task
+	taskQueue.Push(task)
 
 	time.Sleep(300 * time.Millisecond)
 
@@ -139,10 +143,40 @@ func TestWorker_ProcessFileTask_Success(t *testing.T) {
 	}
 }
 
+func TestWorker_ProcessFileTask_KeepsFileWhenSaveFails(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task), SaveErr: errors.New("storage unavailable")}
+	taskQueue := queue.NewQueues(1)
+	stats := service.NewTaskStats()
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte("кот\nток\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	pool := NewPool(storage, taskQueue, zap.NewNop(), time.Second, stats, 2)
+	go pool.Run(1)
+	defer pool.Stop()
+
+	taskQueue.Push(&domain.Task{ID: "unsaved", FilePath: filePath})
+
+	deadline := time.Now().Add(time.Second)
+	for stats.CompletedTasks.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("task was not processed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := os.Stat(filePath); err != nil {
+		t.Errorf("expected input file to be kept when the final status was not saved: %v", err)
+	}
+}
+
 func TestWorker_TaskTimeout(t *testing.T) {
 	t.Parallel()
 	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
-	taskQueue := make(chan *domain.Task, 1)
+	taskQueue := queue.NewQueues(1)
 	logger := zap.NewNop()
 	stats := service.NewTaskStats()
 
@@ -156,7 +190,7 @@ func TestWorker_TaskTimeout(t *testing.T) {
 		CaseSensitive: false,
 		TraceContext:  make(map[string]string),
 	}
-	taskQueue <- task
+	taskQueue.Push(task)
 
 	time.Sleep(200 * time.Millisecond)
 
@@ -168,3 +202,264 @@ func TestWorker_TaskTimeout(t *testing.T) {
 		t.Errorf("expected 1 failed, got %d", stats.FailedTasks.Load())
 	}
 }
+
+func TestWorker_TransientError_RetriesThenDeadLetter(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(1)
+	logger := zap.NewNop()
+	stats := service.NewTaskStats()
+
+	pool := NewPool(storage, taskQueue, logger, time.Second, stats, 10)
+	pool.SetRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Multiplier:     2,
+		Retryable:      IsTransientError,
+	})
+	go pool.Run(1)
+	defer pool.Stop()
+
+	// чтение каталога как файла возвращает *fs.PathError, который считается временной ошибкой
+	dir := t.TempDir()
+	task := &domain.Task{
+		ID:       "t5",
+		FilePath: dir,
+	}
+	taskQueue.Push(task)
+
+	time.Sleep(300 * time.Millisecond)
+
+	saved, _ := storage.GetByID(context.Background(), "t5")
+	if saved.Status != domain.StatusDeadLetter {
+		t.Fatalf("expected DeadLetter, got %v", saved.Status)
+	}
+	if saved.Attempts != 3 || saved.LastError == "" {
+		t.Errorf("expected 3 attempts with last error, got %d, %q", saved.Attempts, saved.LastError)
+	}
+	if stats.RetriedTasks.Load() != 2 || stats.DeadLetterTasks.Load() != 1 {
+		t.Errorf("expected 2 retries and 1 dead letter, got %d, %d", stats.RetriedTasks.Load(), stats.DeadLetterTasks.Load())
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected input to be kept for dead-letter task: %v", err)
+	}
+}
+
+func TestWorker_CancelRunningTask(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(1)
+	logger := zap.NewNop()
+	stats := service.NewTaskStats()
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte(strings.Repeat("кот ток рост торс ", 500000)), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	pool := NewPool(storage, taskQueue, logger, 10*time.Second, stats, 10)
+	go pool.Run(1)
+	defer pool.Stop()
+
+	taskQueue.Push(&domain.Task{ID: "t6", FilePath: filePath})
+
+	deadline := time.Now().Add(time.Second)
+	for !pool.CancelTask("t6") {
+		if time.Now().After(deadline) {
+			t.Fatal("task was not picked up by worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	saved, _ := storage.GetByID(context.Background(), "t6")
+	if saved.Status != domain.StatusCancelled {
+		t.Fatalf("expected Cancelled, got %v", saved.Status)
+	}
+	if stats.CancelledTasks.Load() != 1 || stats.FailedTasks.Load() != 0 {
+		t.Errorf("expected 1 cancelled and no failed tasks, got %d, %d", stats.CancelledTasks.Load(), stats.FailedTasks.Load())
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Error("expected file of cancelled task to be removed")
+	}
+}
+
+func TestWorker_ProcessFileTask_ReportsProgress(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(1)
+	logger := zap.NewNop()
+	stats := service.NewTaskStats()
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte("кот\nток\nрост\nторс\nсорт\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	pool := NewPool(storage, taskQueue, logger, time.Second, stats, 2)
+	go pool.Run(1)
+	defer pool.Stop()
+
+	taskQueue.Push(&domain.Task{ID: "t7", FilePath: filePath, Progress: &domain.TaskProgress{TotalWords: 5}})
+
+	time.Sleep(200 * time.Millisecond)
+
+	saved, _ := storage.GetByID(context.Background(), "t7")
+	if saved.Status != domain.StatusCompleted || saved.Progress == nil {
+		t.Fatalf("expected completed task with progress, got %+v", saved)
+	}
+	if saved.Progress.WordsProcessed != 5 || saved.Progress.TotalWords != 5 || saved.Progress.BatchesMerged != 3 {
+		t.Errorf("unexpected progress: %+v", saved.Progress)
+	}
+	if saved.Progress.StartedAt.IsZero() || saved.Progress.Percent() != 100 {
+		t.Errorf("expected finished progress, got %+v", saved.Progress)
+	}
+}
+
+func TestPool_Shutdown_ReturnsUnprocessedTasks(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(2)
+	logger := zap.NewNop()
+	stats := service.NewTaskStats()
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte(strings.Repeat("кот ток рост торс ", 500000)), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	pool := NewPool(storage, taskQueue, logger, 10*time.Second, stats, 10)
+	pool.Run(1)
+
+	taskQueue.Push(&domain.Task{ID: "running", FilePath: filePath})
+	deadline := time.Now().Add(time.Second)
+	for {
+		pool.tasksMu.Lock()
+		_, running := pool.running["running"]
+		pool.tasksMu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task was not picked up by worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	taskQueue.Push(&domain.Task{ID: "queued", Words: []string{"кот", "ток"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pending := pool.Shutdown(ctx)
+
+	ids := make(map[string]*domain.Task, len(pending))
+	for _, task := range pending {
+		ids[task.ID] = task
+	}
+	if len(pending) != 2 || ids["running"] == nil || ids["queued"] == nil {
+		t.Fatalf("expected interrupted and queued tasks, got %v", pending)
+	}
+	if ids["running"].Attempts != 0 {
+		t.Errorf("expected interrupted attempt not to count, got %d", ids["running"].Attempts)
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		t.Errorf("expected input file of interrupted task to be kept: %v", err)
+	}
+	if stats.CompletedTasks.Load() != 0 || stats.FailedTasks.Load() != 0 {
+		t.Errorf("expected no finished tasks, got %d completed, %d failed", stats.CompletedTasks.Load(), stats.FailedTasks.Load())
+	}
+	if err := pool.Resize(1); !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("expected ErrPoolStopped after shutdown, got %v", err)
+	}
+}
+
+func TestPool_QueueSizeLimitsPendingTasks(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(3)
+	stats := service.NewTaskStats()
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte(strings.Repeat("кот ток рост торс ", 500000)), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	pool := NewPool(storage, taskQueue, zap.NewNop(), 10*time.Second, stats, 10)
+	pool.Run(1)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		pool.Shutdown(ctx)
+	}()
+
+	taskQueue.Push(&domain.Task{ID: "running", FilePath: filePath})
+	deadline := time.Now().Add(time.Second)
+	for {
+		pool.tasksMu.Lock()
+		_, running := pool.running["running"]
+		pool.tasksMu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task was not picked up by worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	enqueue := func(id string, priority domain.TaskPriority) error {
+		ctx, cancel := queue.WithTimeout(context.Background(), 0)
+		defer cancel()
+		return taskQueue.Enqueue(ctx, &domain.Task{ID: id, Priority: priority, Words: []string{"кот"}})
+	}
+	for i, priority := range []domain.TaskPriority{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow} {
+		if err := enqueue(fmt.Sprintf("queued%d", i), priority); err != nil {
+			t.Fatalf("expected task %d to fit into the queue, got %v", i, err)
+		}
+	}
+
+	// диспетчер успевает разобрать задачи по клиентам, но место в очереди они не освобождают
+	time.Sleep(50 * time.Millisecond)
+	for _, priority := range domain.Priorities {
+		if err := enqueue("overflow-"+string(priority), priority); !errors.Is(err, queue.ErrQueueFull) {
+			t.Errorf("expected ErrQueueFull for %s task, got %v", priority, err)
+		}
+	}
+	if taskQueue.Len() != 3 {
+		t.Errorf("expected 3 pending tasks, got %d", taskQueue.Len())
+	}
+}
+
+func TestWorker_PanicMarksTaskFailedAndKeepsWorker(t *testing.T) {
+	storage := &mocks.MockTaskStorage{Tasks: make(map[string]*domain.Task)}
+	taskQueue := queue.NewQueues(2)
+	stats := service.NewTaskStats()
+
+	pool := NewPool(storage, taskQueue, zap.NewNop(), time.Second, stats, 10)
+	pool.groupWords = func(ctx context.Context, words []string, caseSensitive bool) (map[string][]string, error) {
+		if words[0] == "boom" {
+			var groups map[string][]string
+			groups["boom"] = words
+		}
+		return map[string][]string{"кот": words}, nil
+	}
+	go pool.Run(1)
+	defer pool.Stop()
+
+	taskQueue.Push(&domain.Task{ID: "panic", Words: []string{"boom"}, TraceContext: make(map[string]string)})
+	taskQueue.Push(&domain.Task{ID: "ok", Words: []string{"кот", "ток"}, TraceContext: make(map[string]string)})
+
+	time.Sleep(300 * time.Millisecond)
+
+	failed, _ := storage.GetByID(context.Background(), "panic")
+	if failed.Status != domain.StatusFailed {
+		t.Fatalf("expected Failed, got %v", failed.Status)
+	}
+	if failed.Error != ErrTaskPanicked.Error() {
+		t.Errorf("expected sanitized error, got %q", failed.Error)
+	}
+	if stats.PanickedTasks.Load() != 1 || stats.FailedTasks.Load() != 1 {
+		t.Errorf("expected 1 panicked and 1 failed, got %d and %d", stats.PanickedTasks.Load(), stats.FailedTasks.Load())
+	}
+
+	completed, _ := storage.GetByID(context.Background(), "ok")
+	if completed.Status != domain.StatusCompleted {
+		t.Errorf("expected worker to survive and complete next task, got %v", completed.Status)
+	}
+}
@@ -0,0 +1,416 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/pkg/anagram"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ErrLeaseLost - аренда истекла или задачу отменили; результат больше не нужен
+var ErrLeaseLost = errors.New("task lease lost")
+
+// DefaultLeaseWait - сколько удаленный воркер ждет задачу в одном запросе
+const DefaultLeaseWait = 10 * time.Second
+
+const (
+	leasesPath        = "/api/v1/internal/leases"
+	workerTokenHeader = "X-Worker-Token"
+	// completeTimeout ограничивает отправку результата, которая идет уже после отмены Run
+	completeTimeout = 30 * time.Second
+)
+
+// RemoteLease - задача, арендованная удаленным воркером
+type RemoteLease struct {
+	ID            string    `json:"lease_id"`
+	TaskID        string    `json:"task_id"`
+	CaseSensitive bool      `json:"case_sensitive"`
+	Attempt       int       `json:"attempt"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type acquireLeaseRequest struct {
+	WorkerID string `json:"worker_id"`
+	Wait     string `json:"wait,omitempty"`
+}
+
+type leaseHeartbeatRequest struct {
+	WordsProcessed int `json:"words_processed"`
+	BatchesMerged  int `json:"batches_merged"`
+}
+
+type leaseHeartbeatResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type completeLeaseRequest struct {
+	Result           [][]string `json:"result"`
+	ProcessingTimeMS int64      `json:"processing_time_ms"`
+	WordsProcessed   int        `json:"words_processed"`
+	BatchesMerged    int        `json:"batches_merged"`
+	Error            string     `json:"error,omitempty"`
+	Panicked         bool       `json:"panicked,omitempty"`
+	Timeout          bool       `json:"timeout,omitempty"`
+	Transient        bool       `json:"transient,omitempty"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// LeaseClient обращается к внутренним эндпоинтам аренды задач API
+type LeaseClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewLeaseClient(baseURL, token string, client *http.Client) *LeaseClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &LeaseClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// Acquire арендует задачу, ожидая ее на стороне API не дольше wait.
+// Если задач нет, возвращает nil без ошибки.
+func (c *LeaseClient) Acquire(ctx context.Context, workerID string, wait time.Duration) (*RemoteLease, error) {
+	request := acquireLeaseRequest{WorkerID: workerID}
+	if wait > 0 {
+		request.Wait = wait.String()
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, leasesPath, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	var lease RemoteLease
+	if err := json.NewDecoder(resp.Body).Decode(&lease); err != nil {
+		return nil, fmt.Errorf("decode lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// Heartbeat продлевает аренду и возвращает новое время ее окончания
+func (c *LeaseClient) Heartbeat(ctx context.Context, leaseID string, wordsProcessed, batchesMerged int) (time.Time, error) {
+	resp, err := c.do(ctx, http.MethodPost, leasePath(leaseID, "heartbeat"), leaseHeartbeatRequest{
+		WordsProcessed: wordsProcessed,
+		BatchesMerged:  batchesMerged,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	var renewed leaseHeartbeatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&renewed); err != nil {
+		return time.Time{}, fmt.Errorf("decode heartbeat: %w", err)
+	}
+	return renewed.ExpiresAt, nil
+}
+
+// Input открывает поток слов арендованной задачи
+func (c *LeaseClient) Input(ctx context.Context, leaseID string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, leasePath(leaseID, "input"), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Complete сдает результат обработки и завершает аренду
+func (c *LeaseClient) Complete(ctx context.Context, leaseID string, result domain.LeaseResult) error {
+	resp, err := c.do(ctx, http.MethodPost, leasePath(leaseID, "complete"), completeLeaseRequest{
+		Result:           result.Groups,
+		ProcessingTimeMS: result.ProcessingTimeMS,
+		WordsProcessed:   result.WordsProcessed,
+		BatchesMerged:    result.BatchesMerged,
+		Error:            result.Error,
+		Panicked:         result.Panicked,
+		Timeout:          result.Timeout,
+		Transient:        result.Transient,
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *LeaseClient) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(workerTokenHeader, c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return nil, ErrLeaseLost
+	}
+	var apiErr apiError
+	_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+	return nil, fmt.Errorf("%s %s: status %d %s: %s", method, path, resp.StatusCode, apiErr.Code, apiErr.Message)
+}
+
+func leasePath(leaseID, action string) string {
+	return leasesPath + "/" + url.PathEscape(leaseID) + "/" + action
+}
+
+// RemoteWorker арендует задачи у API и обрабатывает их вне процесса API.
+// Пока задача обрабатывается, аренда продлевается; если ее продлить
+// не удалось, обработка прерывается, а API вернет задачу в очередь.
+type RemoteWorker struct {
+	client            *LeaseClient
+	id                string
+	concurrency       int
+	batchSize         int
+	processingTimeout time.Duration
+	leaseWait         time.Duration
+	retryDelay        time.Duration
+	groupWords        groupFunc
+	logger            *zap.Logger
+}
+
+func NewRemoteWorker(client *LeaseClient, id string, concurrency, batchSize int, processingTimeout time.Duration, logger *zap.Logger) *RemoteWorker {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &RemoteWorker{
+		client:            client,
+		id:                id,
+		concurrency:       max(concurrency, 1),
+		batchSize:         max(batchSize, 1),
+		processingTimeout: processingTimeout,
+		leaseWait:         DefaultLeaseWait,
+		retryDelay:        time.Second,
+		groupWords:        anagram.Group,
+		logger:            logger.With(zap.String("worker_id", id)),
+	}
+}
+
+// SetLeaseWait задает, сколько ждать задачу в одном запросе аренды
+func (w *RemoteWorker) SetLeaseWait(wait time.Duration) {
+	if wait > 0 {
+		w.leaseWait = wait
+	}
+}
+
+// Run обрабатывает задачи, пока не отменен ctx. После отмены новые задачи
+// не арендуются, а уже взятые дорабатываются и сдаются.
+func (w *RemoteWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *RemoteWorker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		lease, err := w.client.Acquire(ctx, w.id, w.leaseWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			w.logger.Warn("failed to lease task", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.retryDelay):
+			}
+			continue
+		}
+		if lease == nil {
+			continue
+		}
+		w.handle(lease)
+	}
+}
+
+func (w *RemoteWorker) handle(lease *RemoteLease) {
+	taskLog := w.logger.With(zap.String("task_id", lease.TaskID), zap.String("lease_id", lease.ID))
+	taskLog.Info("processing leased task", zap.Int("attempt", lease.Attempt))
+
+	tr := otel.Tracer("worker")
+	ctx, span := tr.Start(context.Background(), "process_remote_task")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("task_id", lease.TaskID),
+		attribute.String("lease_id", lease.ID),
+		attribute.Int("attempt", lease.Attempt),
+	)
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, w.processingTimeout)
+	defer cancelTimeout()
+	taskCtx, cancel := context.WithCancelCause(timeoutCtx)
+	defer cancel(nil)
+
+	// если слова не удалось получить, задача не сдается: API вернет ее
+	// в очередь, когда истечет аренда
+	input, err := w.client.Input(taskCtx, lease.ID)
+	if err != nil {
+		taskLog.Warn("failed to fetch task input", zap.Error(err))
+		span.RecordError(err)
+		return
+	}
+	defer input.Close()
+
+	var words, batches atomic.Int64
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.heartbeat(taskCtx, lease, &words, &batches, cancel, taskLog)
+	}()
+
+	start := time.Now()
+	grouped, err := w.process(taskCtx, input, lease.CaseSensitive, func(w, b int) {
+		words.Store(int64(w))
+		batches.Store(int64(b))
+	})
+	cancel(nil)
+	<-heartbeatDone
+
+	if errors.Is(context.Cause(taskCtx), ErrLeaseLost) {
+		taskLog.Warn("task lease lost, result discarded")
+		span.SetAttributes(attribute.String("status", "lease_lost"))
+		return
+	}
+
+	result := domain.LeaseResult{
+		ProcessingTimeMS: time.Since(start).Milliseconds(),
+		WordsProcessed:   int(words.Load()),
+		BatchesMerged:    int(batches.Load()),
+	}
+	if err != nil {
+		var panicErr *panicError
+		switch {
+		case errors.As(err, &panicErr):
+			taskLog.Error("task processing panicked", zap.Any("panic", panicErr.value), zap.ByteString("stack", panicErr.stack))
+			span.SetAttributes(attribute.String("panic.stack", string(panicErr.stack)))
+			result.Error = ErrTaskPanicked.Error()
+			result.Panicked = true
+		case errors.Is(err, context.DeadlineExceeded):
+			taskLog.Warn("task processing timeout")
+			result.Error = "task processing timeout"
+			result.Timeout = true
+		default:
+			taskLog.Error("task processing failed", zap.Error(err))
+			result.Error = err.Error()
+			result.Transient = IsTransientError(err)
+		}
+		span.RecordError(err)
+	} else {
+		result.Groups = make([][]string, 0, len(grouped))
+		for _, group := range grouped {
+			if len(group) > 1 {
+				result.Groups = append(result.Groups, group)
+			}
+		}
+		span.SetAttributes(attribute.Int("groups_count", len(result.Groups)))
+	}
+
+	completeCtx, cancelComplete := context.WithTimeout(ctx, completeTimeout)
+	defer cancelComplete()
+	if err := w.client.Complete(completeCtx, lease.ID, result); err != nil {
+		taskLog.Error("failed to complete leased task", zap.Error(err))
+		span.RecordError(err)
+		return
+	}
+	taskLog.Info("finished leased task")
+}
+
+// process группирует слова задачи, перехватывая панику так же, как пул воркеров
+func (w *RemoteWorker) process(ctx context.Context, input io.Reader, caseSensitive bool, progress func(words, batches int)) (grouped map[string][]string, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			grouped, err = nil, &panicError{value: value, stack: debug.Stack()}
+		}
+	}()
+
+	return groupReader(ctx, input, caseSensitive, w.batchSize, w.groupWords, progress)
+}
+
+// heartbeat продлевает аренду, пока не отменен ctx. Если аренда потеряна,
+// обработка задачи отменяется с причиной ErrLeaseLost.
+func (w *RemoteWorker) heartbeat(ctx context.Context, lease *RemoteLease, words, batches *atomic.Int64, cancel context.CancelCauseFunc, taskLog *zap.Logger) {
+	expiresAt := lease.ExpiresAt
+	for {
+		interval := max(time.Until(expiresAt)/3, 10*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		renewed, err := w.client.Heartbeat(ctx, lease.ID, int(words.Load()), int(batches.Load()))
+		if err != nil {
+			if errors.Is(err, ErrLeaseLost) {
+				cancel(ErrLeaseLost)
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			taskLog.Warn("failed to renew task lease", zap.Error(err))
+			if time.Now().After(expiresAt) {
+				cancel(ErrLeaseLost)
+				return
+			}
+			continue
+		}
+		expiresAt = renewed
+	}
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/service"
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+// RetryPolicy определяет, сколько раз и с какой задержкой повторять обработку задачи
+type RetryPolicy struct {
+	// Максимальное количество попыток, включая первую. 1 отключает повторы
+	MaxAttempts int
+	// Задержка перед второй попыткой
+	InitialBackoff time.Duration
+	// Верхняя граница задержки
+	MaxBackoff time.Duration
+	// Множитель задержки для каждой следующей попытки
+	Multiplier float64
+	// Retryable решает, является ли ошибка временной
+	Retryable func(err error) bool
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Retryable:      IsTransientError,
+}
+
+// IsTransientError считает временными ошибки ввода-вывода и недоступность хранилища,
+// а для удаленного воркера - ошибки, которые он сам счел временными.
+// Отсутствующий файл и таймаут обработки повтором не исправить.
+func IsTransientError(err error) bool {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pathErr *fs.PathError
+	var remoteErr *service.RemoteTaskError
+	if errors.As(err, &remoteErr) {
+		return remoteErr.Transient
+	}
+	return errors.As(err, &pathErr) || errors.Is(err, storage.ErrUnavailable)
+}
+
+// RetryTimeouts дополнительно считает временным таймаут обработки
+func RetryTimeouts(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || IsTransientError(err)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	return p.Retryable != nil && p.Retryable(err)
+}
+
+// shouldRetry сообщает, нужно ли повторить задачу после attempts неудачных попыток
+func (p RetryPolicy) shouldRetry(err error, attempts int) bool {
+	return p.retryable(err) && attempts < p.MaxAttempts
+}
+
+// backoff возвращает задержку перед попыткой номер attempts+1
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempts; i++ {
+		delay *= p.Multiplier
+		if p.MaxBackoff > 0 && delay >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(delay)
+}
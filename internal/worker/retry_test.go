@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grcflEgor/go-anagram-api/internal/storage"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Retryable: IsTransientError}
+	transient := &fs.PathError{Op: "read", Path: "words.txt", Err: fmt.Errorf("input/output error")}
+
+	if !policy.shouldRetry(transient, 2) {
+		t.Error("expected retry before attempts are exhausted")
+	}
+	if policy.shouldRetry(transient, 3) {
+		t.Error("expected no retry after attempts are exhausted")
+	}
+	if policy.shouldRetry(fmt.Errorf("invalid input"), 1) {
+		t.Error("expected no retry for permanent error")
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	_, notExist := os.Open("not_exists.txt")
+
+	cases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"path error", &fs.PathError{Op: "read", Path: "words.txt", Err: fmt.Errorf("input/output error")}, true},
+		{"storage unavailable", fmt.Errorf("%w: journal closed", storage.ErrUnavailable), true},
+		{"missing file", notExist, false},
+		{"timeout", context.DeadlineExceeded, false},
+		{"plain error", fmt.Errorf("boom"), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTransientError(tc.err); got != tc.transient {
+				t.Errorf("expected %v, got %v", tc.transient, got)
+			}
+		})
+	}
+
+	if !RetryTimeouts(context.DeadlineExceeded) {
+		t.Error("expected RetryTimeouts to retry timeouts")
+	}
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+)
+
+// DefaultPriorityWeights - доли задач каждого приоритета, которые берут воркеры,
+// когда заполнены все очереди
+var DefaultPriorityWeights = map[domain.TaskPriority]int{
+	domain.PriorityHigh:   6,
+	domain.PriorityNormal: 3,
+	domain.PriorityLow:    1,
+}
+
+// scheduler выбирает очередь для следующей задачи алгоритмом smooth weighted
+// round-robin: при заполненных очередях задачи берутся пропорционально весам,
+// а пустая очередь уступает свою долю остальным. Очередь без каналов
+// по приоритетам читается через Dequeue, и веса не применяются.
+type scheduler struct {
+	queue    queue.TaskQueue
+	channels queue.PriorityQueue
+
+	mu      sync.Mutex
+	weights map[domain.TaskPriority]int
+	current map[domain.TaskPriority]int
+}
+
+func newScheduler(taskQueue queue.TaskQueue, weights map[domain.TaskPriority]int) *scheduler {
+	s := &scheduler{queue: taskQueue}
+	if channels, ok := taskQueue.(queue.PriorityQueue); ok {
+		s.channels = channels
+	}
+	s.setWeights(weights)
+	return s
+}
+
+func (s *scheduler) setWeights(weights map[domain.TaskPriority]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.weights = make(map[domain.TaskPriority]int, len(domain.Priorities))
+	s.current = make(map[domain.TaskPriority]int, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		weight := weights[priority]
+		if weight < 1 {
+			weight = 1
+		}
+		s.weights[priority] = weight
+	}
+}
+
+// weight возвращает вес приоритета
+func (s *scheduler) weight(priority domain.TaskPriority) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.weights[priority]
+}
+
+func (s *scheduler) pick() domain.TaskPriority {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	best := domain.Priorities[0]
+	for _, priority := range domain.Priorities {
+		s.current[priority] += s.weights[priority]
+		total += s.weights[priority]
+		if s.current[priority] > s.current[best] {
+			best = priority
+		}
+	}
+	s.current[best] -= total
+	return best
+}
+
+// receive возвращает задачу из очереди, выбранной по весам, или false,
+// если все очереди закрыты и пусты либо закрыт quit
+func (s *scheduler) receive(quit <-chan struct{}) (*domain.Task, bool) {
+	select {
+	case <-quit:
+		return nil, false
+	default:
+	}
+
+	if s.channels == nil {
+		return s.dequeue(quit)
+	}
+
+	preferred := s.pick()
+
+	for _, priority := range append([]domain.TaskPriority{preferred}, domain.Priorities...) {
+		select {
+		case task, ok := <-s.channels.Channel(priority):
+			if ok {
+				return task, true
+			}
+		default:
+		}
+	}
+
+	high := s.channels.Channel(domain.PriorityHigh)
+	normal := s.channels.Channel(domain.PriorityNormal)
+	low := s.channels.Channel(domain.PriorityLow)
+	for high != nil || normal != nil || low != nil {
+		select {
+		case <-quit:
+			return nil, false
+		case task, ok := <-high:
+			if !ok {
+				high = nil
+				continue
+			}
+			return task, true
+		case task, ok := <-normal:
+			if !ok {
+				normal = nil
+				continue
+			}
+			return task, true
+		case task, ok := <-low:
+			if !ok {
+				low = nil
+				continue
+			}
+			return task, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scheduler) dequeue(quit <-chan struct{}) (*domain.Task, bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	task, err := s.queue.Dequeue(ctx)
+	return task, err == nil
+}
+
+// claim отмечает полученную задачу взятой в обработку. Задачи, полученные
+// через Dequeue, уже отмечены.
+func (s *scheduler) claim(task *domain.Task) bool {
+	if s.channels == nil {
+		return true
+	}
+	return s.channels.Claim(task)
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/grcflEgor/go-anagram-api/internal/domain"
+	"github.com/grcflEgor/go-anagram-api/internal/queue"
+)
+
+func fillQueues(q *queue.Queues, perPriority int) {
+	for _, priority := range domain.Priorities {
+		for i := 0; i < perPriority; i++ {
+			q.Push(&domain.Task{ID: string(priority), Priority: priority})
+		}
+	}
+}
+
+func TestScheduler_WeightedFairness(t *testing.T) {
+	q := queue.NewQueues(300)
+	fillQueues(q, 100)
+	s := newScheduler(q, DefaultPriorityWeights)
+
+	counts := make(map[domain.TaskPriority]int)
+	for i := 0; i < 100; i++ {
+		task, ok := s.receive(nil)
+		if !ok {
+			t.Fatal("expected task")
+		}
+		counts[task.Priority]++
+	}
+
+	if counts[domain.PriorityHigh] != 60 || counts[domain.PriorityNormal] != 30 || counts[domain.PriorityLow] != 10 {
+		t.Errorf("expected 60/30/10 split, got %v", counts)
+	}
+}
+
+func TestScheduler_EmptyQueueYieldsShare(t *testing.T) {
+	q := queue.NewQueues(10)
+	for i := 0; i < 5; i++ {
+		q.Push(&domain.Task{Priority: domain.PriorityLow})
+	}
+	s := newScheduler(q, DefaultPriorityWeights)
+
+	for i := 0; i < 5; i++ {
+		task, ok := s.receive(nil)
+		if !ok || task.Priority != domain.PriorityLow {
+			t.Fatalf("expected low priority task, got %+v", task)
+		}
+	}
+}
+
+func TestScheduler_ClosedQueues(t *testing.T) {
+	q := queue.NewQueues(10)
+	q.Push(&domain.Task{ID: "last"})
+	q.Close()
+	s := newScheduler(q, DefaultPriorityWeights)
+
+	if task, ok := s.receive(nil); !ok || task.ID != "last" {
+		t.Fatalf("expected queued task to be drained, got %+v", task)
+	}
+	if _, ok := s.receive(nil); ok {
+		t.Error("expected no tasks after queues are closed and drained")
+	}
+}
+
+// plainQueue скрывает каналы приоритетов, оставляя только методы TaskQueue
+type plainQueue struct {
+	queue.TaskQueue
+}
+
+func TestScheduler_PlainTaskQueueUsesDequeue(t *testing.T) {
+	q := queue.NewQueues(10)
+	q.Push(&domain.Task{ID: "low", Priority: domain.PriorityLow})
+	q.Push(&domain.Task{ID: "high", Priority: domain.PriorityHigh})
+	s := newScheduler(plainQueue{q}, DefaultPriorityWeights)
+
+	task, ok := s.receive(nil)
+	if !ok || task.ID != "high" {
+		t.Fatalf("expected high priority task, got %+v", task)
+	}
+	if !s.claim(task) || q.Len() != 1 {
+		t.Errorf("expected dequeued task to be claimed, queue len %d", q.Len())
+	}
+
+	quit := make(chan struct{})
+	close(quit)
+	if _, ok := s.receive(quit); ok {
+		t.Error("expected receive to stop on quit")
+	}
+}